@@ -75,5 +75,16 @@ in the corresponding Agentx PDU type as specified by RFC 2741.
 
 The PayloadSize field is set in process of encoding pdu.
 
+The encoded pdu can be displayed as a hex dump where each field is
+labeled with its name and decoded value:
+
+	fmt.Println(agentx.DumpPduAnnotated(data))
+
+results with
+
+	00000000  01 05 10 00  Version: 1, Tag: Get, Flags: NetworkByteOrder, reserved
+	00000004  00 00 00 07  SessionId: 7
+	...
+
 See also agentx/demo/demo_test.go.
 */
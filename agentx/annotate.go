@@ -0,0 +1,265 @@
+package agentx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/hex"
+	"github.com/alexispb/mygosnmp/ipa"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+// annotator decodes pdu data and labels the decoded fields
+// with spans. It uses decoder for parsing, so it fails (panics)
+// on the same errors as DecodePduHeader and DecodePduPayload.
+type annotator struct {
+	decoder
+	data  []byte
+	pos   int
+	spans []hex.Span
+}
+
+// tail returns the data which are not decoded yet.
+func (a *annotator) tail() []byte {
+	return a.data[a.pos:]
+}
+
+// add labels the data decoded up to the next (i.e. the rest of
+// data returned by a parse function).
+func (a *annotator) add(next []byte, label string) {
+	a.addSize(len(a.data)-a.pos-len(next), label)
+}
+
+// addSize labels the next size bytes.
+func (a *annotator) addSize(size int, label string) {
+	if size <= 0 {
+		return
+	}
+	if a.pos+size > len(a.data) {
+		panic(ErrEncoding)
+	}
+	a.spans = append(a.spans, hex.Span{Start: a.pos, End: a.pos + size, Label: label})
+	a.pos += size
+}
+
+func (a *annotator) int16(label string) int16 {
+	val, next := a.parseInt16(a.tail())
+	a.add(next, label+": "+strconv.FormatInt(int64(val), 10))
+	return val
+}
+
+func (a *annotator) uint32(label string) uint32 {
+	val, next := a.parseUint32(a.tail())
+	a.add(next, label+": "+strconv.FormatUint(uint64(val), 10))
+	return val
+}
+
+func (a *annotator) octetString(label string) {
+	val, next := a.parseOctetString(a.tail())
+	a.addSize(4, label+".len: "+strconv.Itoa(len(val)))
+	a.addSize(len(val), label+".data")
+	a.add(next, label+".pad")
+}
+
+func (a *annotator) objectId(label string) (include byte) {
+	val, include, next := a.parseObjectId(a.tail())
+	if len(a.data)-a.pos-len(next) == 4 {
+		// null oid or oid which consists of prefix only
+		str := oid.String(val)
+		if len(str) == 0 {
+			str = "<null>"
+		}
+		a.addSize(4, fmt.Sprintf("%s: %s, include: %d", label, str, include))
+		return
+	}
+	a.addSize(4, fmt.Sprintf("%s.header: include: %d", label, include))
+	a.add(next, label+": "+oid.String(val))
+	return
+}
+
+func (a *annotator) reserved(size int) {
+	for _, b := range a.tail()[:size] {
+		if b != 0 {
+			panic(ErrEncoding)
+		}
+	}
+	a.addSize(size, "reserved")
+}
+
+// AnnotatePdu decodes the encoded pdu data (header and payload) and
+// returns spans which label the decoded fields. Labels include field
+// names and decoded values. If data can not be decoded, AnnotatePdu
+// returns ok = false and the spans label the successfully decoded
+// part of data followed by the "!!! undecoded" span.
+// Use hex.DumpAnnotated to display the spans.
+func AnnotatePdu(data []byte) (spans []hex.Span, ok bool) {
+	a := annotator{data: data}
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+		if !ok && a.pos < len(data) {
+			a.spans = append(a.spans, hex.Span{Start: a.pos, End: len(data), Label: "!!! undecoded"})
+		}
+		spans = a.spans
+	}()
+
+	if ok = len(data) >= PduHeaderSize; !ok {
+		return
+	}
+	pdu, ok := DecodePduHeader(data[:PduHeaderSize])
+	if !ok {
+		return
+	}
+	a.byteOrder = pdu.Flags.byteOrder()
+	a.addSize(4, fmt.Sprintf("Version: %d, Tag: %s, Flags: %s, reserved",
+		data[0], pdu.Tag.String(), pdu.Flags.String()))
+	a.uint32("SessionId")
+	a.uint32("TransactionId")
+	a.uint32("PacketId")
+	a.uint32("PayloadSize")
+
+	if ok = len(data) == PduHeaderSize+int(pdu.PayloadSize); !ok {
+		return
+	}
+
+	if pdu.Flags&FlagNonDefaultContext != 0 {
+		a.octetString("Context")
+	}
+	pduTable[pdu.Tag].annotateParams(&a)
+	if pduTable[pdu.Tag].includesRanges {
+		for i := 0; a.pos < len(a.data); i++ {
+			prefix := "Ranges[" + strconv.Itoa(i) + "]."
+			a.objectId(prefix + "StartOid")
+			a.objectId(prefix + "EndOid")
+		}
+	}
+	if pduTable[pdu.Tag].includesVarbinds {
+		for i := 0; a.pos < len(a.data); i++ {
+			a.varbind("Varbinds[" + strconv.Itoa(i) + "].")
+		}
+	}
+
+	ok = a.pos == len(data)
+	return
+}
+
+// DumpPduAnnotated returns hex dump of the encoded pdu data
+// with labels of the decoded fields (see AnnotatePdu).
+func DumpPduAnnotated(data []byte) string {
+	spans, _ := AnnotatePdu(data)
+	return hex.DumpAnnotated(data, spans)
+}
+
+func (a *annotator) varbind(prefix string) {
+	tag, next := a.parseInt16(a.tail())
+	vbtag := asn.Tag(tag)
+	if !vbtag.IsValueTag() {
+		panic(ErrEncoding)
+	}
+	a.add(next, prefix+"Tag: "+vbtag.String())
+	a.reserved(2)
+	a.objectId(prefix + "Oid")
+	vbtable[vbtag].annotateValue(a, prefix+"Value")
+}
+
+func annotateNoParams(a *annotator) {}
+
+func annotateOpenParams(a *annotator) {
+	a.addSize(1, "Params.Timeout: "+strconv.Itoa(int(a.tail()[0])))
+	a.reserved(3)
+	a.objectId("Params.Oid")
+	a.octetString("Params.Description")
+}
+
+func annotateCloseParams(a *annotator) {
+	a.addSize(1, "Params.Reason: "+CloseReason(a.tail()[0]).String())
+	a.reserved(3)
+}
+
+func annotateRegisterParams(a *annotator) {
+	data := a.tail()[:4]
+	a.addSize(3, fmt.Sprintf("Params.Timeout: %d, Priority: %d, RangeSubid: %d",
+		data[0], data[1], data[2]))
+	a.reserved(1)
+	a.objectId("Params.Subtree")
+	if data[2] != 0 {
+		a.uint32("Params.UpperBound")
+	}
+}
+
+func annotateUnregisterParams(a *annotator) {
+	a.reserved(1)
+	data := a.tail()[:3]
+	a.addSize(2, fmt.Sprintf("Params.Priority: %d, RangeSubid: %d",
+		data[0], data[1]))
+	a.reserved(1)
+	a.objectId("Params.Subtree")
+	if data[1] != 0 {
+		a.uint32("Params.UpperBound")
+	}
+}
+
+func annotateGetBulkParams(a *annotator) {
+	if a.int16("Params.NonRepeaters") < 0 {
+		panic(ErrEncoding)
+	}
+	if a.int16("Params.MaxRepeatitions") < 0 {
+		panic(ErrEncoding)
+	}
+}
+
+func annotateAddAgentCapsParams(a *annotator) {
+	a.objectId("Params.Oid")
+	a.octetString("Params.Description")
+}
+
+func annotateRemoveAgentCapsParams(a *annotator) {
+	a.objectId("Params.Oid")
+}
+
+func annotateResponseParams(a *annotator) {
+	a.uint32("Params.SysUpTime")
+	err, next := a.parseInt16(a.tail())
+	a.add(next, "Params.Error: "+pduerror.Error(err).String())
+	a.int16("Params.Index")
+}
+
+func annotateInt32Value(a *annotator, label string) {
+	val, next := a.parseInt32(a.tail())
+	a.add(next, label+": "+strconv.FormatInt(int64(val), 10))
+}
+
+func annotateUint32Value(a *annotator, label string) {
+	a.uint32(label)
+}
+
+func annotateUint64Value(a *annotator, label string) {
+	val, next := a.parseUint64(a.tail())
+	a.add(next, label+": "+strconv.FormatUint(val, 10))
+}
+
+func annotateOctetStringValue(a *annotator, label string) {
+	a.octetString(label)
+}
+
+func annotateObjectIdValue(a *annotator, label string) {
+	a.objectId(label)
+}
+
+func annotateIpAddressValue(a *annotator, label string) {
+	val, next := a.parseIpAddress(a.tail())
+	a.addSize(4, label+".len: 4")
+	a.add(next, label+": "+ipa.String(val))
+}
+
+func annotateOpaqueValue(a *annotator, label string) {
+	val, next := a.parseOpaque(a.tail())
+	a.addSize(4, label+".len: "+strconv.Itoa(len(val)))
+	a.addSize(len(val), label+".data")
+	a.add(next, label+".pad")
+}
+
+func annotateNullValue(a *annotator, label string) {}
@@ -0,0 +1,90 @@
+package agentx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/hex"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+var annotateTestPdu = Pdu{
+	Tag:           TagResponse,
+	Flags:         FlagNetworkByteOrder | FlagNonDefaultContext,
+	SessionId:     7,
+	TransactionId: 8,
+	PacketId:      9,
+	Context:       "ctx",
+	Params:        ResponseParams{SysUpTime: 100, Error: pduerror.NoError},
+	Varbinds: []asn.Varbind{
+		{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 1, 0}, Tag: asn.TagInteger32, Value: int32(5)},
+		{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 2, 0}, Tag: asn.TagIpAddress, Value: [4]byte{10, 0, 0, 1}},
+		{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 3, 0}, Tag: asn.TagNoSuchObject},
+	},
+}
+
+func TestAnnotatePdu(t *testing.T) {
+	for _, orderFlag := range pduEncodingTestData.orderFlag {
+		pdu := annotateTestPdu
+		pdu.Flags = pdu.Flags&^FlagNetworkByteOrder | orderFlag
+		data, _ := EncodePdu(pdu)
+
+		spans, ok := AnnotatePdu(data)
+		if !ok {
+			t.Fatalf("failed to annotate pdu:\n%s", hex.DumpAnnotated(data, spans))
+		}
+		pos := 0
+		for _, s := range spans {
+			if s.Start != pos || s.End <= s.Start {
+				t.Errorf("invalid span %v at position %d", s, pos)
+			}
+			pos = s.End
+		}
+		if pos != len(data) {
+			t.Errorf("spans do not cover data: %d != %d", pos, len(data))
+		}
+
+		dump := DumpPduAnnotated(data)
+		for _, label := range []string{
+			"Version: 1, Tag: Response, Flags: NonDefaultContext",
+			"PayloadSize: ",
+			"Context.len: 3",
+			"Params.SysUpTime: 100",
+			"Params.Error: NoError",
+			"Varbinds[0].Tag: Integer32",
+			"Varbinds[0].Oid: 1.3.6.1.4.1.999.1.0",
+			"Varbinds[0].Value: 5",
+			"Varbinds[1].Value: 10.0.0.1",
+			"Varbinds[2].Tag: NoSuchObject",
+		} {
+			if !strings.Contains(dump, label) {
+				t.Errorf("label %q is not found in\n%s", label, dump)
+			}
+		}
+
+		if parsed, err := hex.Parse(dump); err != nil || !bytes.Equal(parsed, data) {
+			t.Errorf("failed to parse annotated dump: %v\n%s", err, dump)
+		}
+	}
+}
+
+func TestAnnotatePduError(t *testing.T) {
+	data, _ := EncodePdu(annotateTestPdu)
+	// damage the reserved bytes of the first varbind
+	data[0x26] = 1
+
+	spans, ok := AnnotatePdu(data)
+	if ok {
+		t.Fatalf("damaged pdu is annotated:\n%s", hex.DumpAnnotated(data, spans))
+	}
+	last := spans[len(spans)-1]
+	if last.Label != "!!! undecoded" || last.End != len(data) {
+		t.Errorf("invalid last span: %v", last)
+	}
+
+	if spans, ok = AnnotatePdu(data[:10]); ok || len(spans) != 1 {
+		t.Errorf("truncated pdu is annotated")
+	}
+}
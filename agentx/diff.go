@@ -0,0 +1,180 @@
+package agentx
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// DiffFlags define pdu fields which are ignored by Diff.
+type DiffFlags byte
+
+const (
+	DiffIgnoreSessionId DiffFlags = 1 << iota
+	DiffIgnoreTransactionId
+	DiffIgnorePacketId
+	// DiffIgnoreSysUpTime ignores ResponseParams.SysUpTime.
+	DiffIgnoreSysUpTime
+)
+
+// Diff compares pdu a and b field by field and returns the list
+// of differences. Each difference is described by the field path
+// followed by the values, e.g.
+//
+//	Varbinds[3].Value: 5 != 6
+//	Ranges[0].EndOid missing
+//
+// where "missing" means that the field (or slice element) is set
+// in a and is not set in b, and "unexpected" means the opposite.
+// The fields which are not included in the pdu type (e.g. Ranges
+// of Response-pdu, or Context if FlagNonDefaultContext is not set)
+// and the PayloadSize field are ignored. The flags define other
+// fields to be ignored. If pdu are equal, Diff returns nil.
+func Diff(a, b Pdu, flags ...DiffFlags) (diff []string) {
+	var ignore DiffFlags
+	for _, f := range flags {
+		ignore |= f
+	}
+
+	add := func(path string, va, vb string) {
+		if va != vb {
+			diff = append(diff, path+": "+va+" != "+vb)
+		}
+	}
+	addUint := func(path string, va, vb uint32) {
+		add(path, strconv.FormatUint(uint64(va), 10), strconv.FormatUint(uint64(vb), 10))
+	}
+
+	add("Tag", a.Tag.String(), b.Tag.String())
+	add("Flags", diffFlagsString(a.Flags), diffFlagsString(b.Flags))
+	if ignore&DiffIgnoreSessionId == 0 {
+		addUint("SessionId", a.SessionId, b.SessionId)
+	}
+	if ignore&DiffIgnoreTransactionId == 0 {
+		addUint("TransactionId", a.TransactionId, b.TransactionId)
+	}
+	if ignore&DiffIgnorePacketId == 0 {
+		addUint("PacketId", a.PacketId, b.PacketId)
+	}
+	if (a.Flags|b.Flags)&FlagNonDefaultContext != 0 {
+		add("Context", strconv.Quote(a.Context), strconv.Quote(b.Context))
+	}
+
+	diff = append(diff, diffParams(a.Params, b.Params, ignore)...)
+
+	if pduIncludes(a.Tag, b.Tag, func(e pduEntry) bool { return e.includesRanges }) {
+		diffSlices(&diff, "Ranges", len(a.Ranges), len(b.Ranges), func(path string, i int) {
+			ra, rb := a.Ranges[i], b.Ranges[i]
+			diffOids(&diff, path+".StartOid", ra.StartOid, rb.StartOid)
+			diffOids(&diff, path+".EndOid", ra.EndOid, rb.EndOid)
+			addUint(path+".StartIncluded", uint32(ra.StartIncluded), uint32(rb.StartIncluded))
+		})
+	}
+
+	if pduIncludes(a.Tag, b.Tag, func(e pduEntry) bool { return e.includesVarbinds }) {
+		diffSlices(&diff, "Varbinds", len(a.Varbinds), len(b.Varbinds), func(path string, i int) {
+			va, vb := a.Varbinds[i], b.Varbinds[i]
+			diffOids(&diff, path+".Oid", va.Oid, vb.Oid)
+			add(path+".Tag", va.Tag.String(), vb.Tag.String())
+			add(path+".Value", valueString(va), valueString(vb))
+		})
+	}
+
+	return
+}
+
+// pduIncludes tests whether the pdu entry of either tag
+// satisfies the condition.
+func pduIncludes(tag1, tag2 PduTag, cond func(pduEntry) bool) bool {
+	return tag1.IsKnown() && cond(pduTable[tag1]) ||
+		tag2.IsKnown() && cond(pduTable[tag2])
+}
+
+func diffFlagsString(f Flags) string {
+	if f == FlagsNone {
+		return "None"
+	}
+	return f.String()
+}
+
+func valueString(vb asn.Varbind) string {
+	var sb strings.Builder
+	vb.Tag.Fprint(&sb, vb.Value)
+	return sb.String()
+}
+
+// diffSlices reports missing and unexpected elements and calls
+// diffElem for elements which exist in both slices.
+func diffSlices(diff *[]string, path string, lena, lenb int, diffElem func(path string, i int)) {
+	for i := 0; i < lena || i < lenb; i++ {
+		elemPath := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= lenb:
+			*diff = append(*diff, elemPath+" missing")
+		case i >= lena:
+			*diff = append(*diff, elemPath+" unexpected")
+		default:
+			diffElem(elemPath, i)
+		}
+	}
+}
+
+func diffOids(diff *[]string, path string, a, b []uint32) {
+	switch {
+	case oid.Eq(a, b):
+	case len(b) == 0:
+		*diff = append(*diff, path+" missing")
+	case len(a) == 0:
+		*diff = append(*diff, path+" unexpected")
+	default:
+		*diff = append(*diff, path+": "+oid.String(a)+" != "+oid.String(b))
+	}
+}
+
+// diffParams compares payload params of the same type field by
+// field. Params of different types are reported as a whole.
+func diffParams(a, b PayloadParams, ignore DiffFlags) (diff []string) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case !va.IsValid() && !vb.IsValid():
+		return
+	case !vb.IsValid():
+		return []string{"Params missing"}
+	case !va.IsValid():
+		return []string{"Params unexpected"}
+	case va.Type() != vb.Type():
+		return []string{"Params: " + va.Type().Name() + " != " + vb.Type().Name()}
+	}
+
+	typ := va.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if name == "SysUpTime" && ignore&DiffIgnoreSysUpTime != 0 {
+			continue
+		}
+		path := "Params." + name
+		fa, fb := va.Field(i), vb.Field(i)
+		if ida, ok := fa.Interface().([]uint32); ok {
+			diffOids(&diff, path, ida, fb.Interface().([]uint32))
+			continue
+		}
+		if sa, sb := fieldString(fa), fieldString(fb); sa != sb {
+			diff = append(diff, path+": "+sa+" != "+sb)
+		}
+	}
+	return
+}
+
+func fieldString(v reflect.Value) string {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	if v.Kind() == reflect.String {
+		return strconv.Quote(v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
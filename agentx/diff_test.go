@@ -0,0 +1,97 @@
+package agentx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+var diffTestGetNext = Pdu{
+	Tag:           TagGetNext,
+	SessionId:     1,
+	TransactionId: 2,
+	PacketId:      3,
+	Ranges: []SearchRange{
+		{StartOid: []uint32{1, 3, 6, 1, 2}, EndOid: []uint32{1, 3, 6, 1, 3}},
+	},
+}
+
+var testDataDiff = []struct {
+	a, b  Pdu
+	flags []DiffFlags
+	diff  []string
+}{
+	{a: annotateTestPdu, b: annotateTestPdu},
+	{
+		a: annotateTestPdu,
+		b: func() Pdu {
+			pdu := annotateTestPdu
+			pdu.PacketId++
+			pdu.Context = "other"
+			pdu.Params = ResponseParams{SysUpTime: 200, Error: pduerror.GenError, Index: 1}
+			pdu.Varbinds = []asn.Varbind{
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 1, 0}, Tag: asn.TagInteger32, Value: int32(6)},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 2}, Tag: asn.TagIpAddress, Value: [4]byte{10, 0, 0, 1}},
+			}
+			return pdu
+		}(),
+		diff: []string{
+			"PacketId: 9 != 10",
+			`Context: "ctx" != "other"`,
+			"Params.SysUpTime: 100 != 200",
+			"Params.Error: NoError != GenError",
+			"Params.Index: 0 != 1",
+			"Varbinds[0].Value: 5 != 6",
+			"Varbinds[1].Oid: 1.3.6.1.4.1.999.2.0 != 1.3.6.1.4.1.999.2",
+			"Varbinds[2] missing",
+		},
+	},
+	{
+		a: annotateTestPdu,
+		b: func() Pdu {
+			pdu := annotateTestPdu
+			pdu.TransactionId++
+			pdu.PacketId++
+			pdu.Params = ResponseParams{SysUpTime: 200}
+			return pdu
+		}(),
+		flags: []DiffFlags{DiffIgnoreTransactionId | DiffIgnorePacketId, DiffIgnoreSysUpTime},
+	},
+	{
+		a: diffTestGetNext,
+		b: func() Pdu {
+			pdu := diffTestGetNext
+			pdu.Ranges = []SearchRange{
+				{StartOid: []uint32{1, 3, 6, 1, 2}},
+				{StartOid: []uint32{1, 3, 6, 1, 4}},
+			}
+			return pdu
+		}(),
+		diff: []string{
+			"Ranges[0].EndOid missing",
+			"Ranges[1] unexpected",
+		},
+	},
+	{
+		a: diffTestGetNext,
+		b: Pdu{Tag: TagClose, SessionId: 1, TransactionId: 2, PacketId: 3,
+			Params: CloseParams{Reason: CloseReasonShutdown}},
+		diff: []string{
+			"Tag: GetNext != Close",
+			"Params unexpected",
+			"Ranges[0] missing",
+		},
+	},
+}
+
+func TestDiff(t *testing.T) {
+	for i, test := range testDataDiff {
+		diff := Diff(test.a, test.b, test.flags...)
+		if strings.Join(diff, "\n") != strings.Join(test.diff, "\n") {
+			t.Errorf("TestDiff[%d]:\nexpected:\n%s\nreceived:\n%s", i,
+				strings.Join(test.diff, "\n"), strings.Join(diff, "\n"))
+		}
+	}
+}
@@ -25,6 +25,16 @@ type decoderDbg struct {
 	log  logger.Log
 }
 
+// describe returns the dotted oid followed by its symbolic name
+// (see oid.Describe) if the name is known.
+func describe(id []uint32) string {
+	s := oid.String(id)
+	if name := oid.Describe(id); name != s {
+		s += " (" + name + ")"
+	}
+	return s
+}
+
 func (d decoderDbg) nextChunk(startpos, size int) (chunk []byte, nextpos int) {
 	n := generics.Min(len(d.data)-startpos, size)
 	d.log.Write(hex.DumpSub("    ", d.data, startpos, startpos+n))
@@ -158,7 +168,7 @@ func (d decoderDbg) parseOctetString(startpos int) (val string, nextpos int) {
 }
 
 func (e encoderDbg) appendObjectId(data []byte, val []uint32, include byte) []byte {
-	e.log.Writef("appending ObjectId: %s, include: %d", oid.String(val), include)
+	e.log.Writef("appending ObjectId: %s, include: %d", describe(val), include)
 	if len(val) == 0 {
 		data = append(data, 0, 0, 0, 0)
 		e.log.Write("appended null ObjectId")
@@ -210,7 +220,7 @@ func (d *decoderDbg) parseObjectId(startpos int) (val []uint32, include byte, ne
 	} else {
 		val = make([]uint32, 0, nsubids+5)
 		val = append(val, 1, 3, 6, 1, prefix)
-		d.log.Writef("ObjectId starts with %s", oid.String(val))
+		d.log.Writef("ObjectId starts with %s", describe(val))
 	}
 	for i := 0; i < nsubids; i++ {
 		d.log.Write("parsing subid")
@@ -218,7 +228,7 @@ func (d *decoderDbg) parseObjectId(startpos int) (val []uint32, include byte, ne
 		subid, nextpos = d.parseUint32(nextpos)
 		val = append(val, subid)
 	}
-	d.log.Writef("parsed ObjectId: %s", oid.String(val))
+	d.log.Writef("parsed ObjectId: %s", describe(val))
 	return
 }
 
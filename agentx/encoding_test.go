@@ -378,3 +378,12 @@ func TestEncoding(t *testing.T) {
 	}
 
 }
+
+func TestDescribe(t *testing.T) {
+	if res := describe([]uint32{1, 3, 6, 1, 2, 1, 1, 3, 0}); res != "1.3.6.1.2.1.1.3.0 (sysUpTime.0)" {
+		t.Errorf("TestDescribe: %s", res)
+	}
+	if res := describe([]uint32{2, 1}); res != "2.1" {
+		t.Errorf("TestDescribe: %s", res)
+	}
+}
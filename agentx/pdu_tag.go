@@ -61,6 +61,8 @@ type pduEntry struct {
 	parseParams funcParseParams
 	// parseParamsDbg is the parseDbg-function applicable for the pdu type
 	parseParamsDbg funcParseParamsDbg
+	// annotateParams is the annotate-function applicable for the pdu type
+	annotateParams func(a *annotator)
 }
 
 var pduTable = [pduTagMax + 1]pduEntry{
@@ -73,6 +75,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(OpenParams); return ok },
 		parseParams:        parseOpenParams,
 		parseParamsDbg:     parseOpenParamsDbg,
+		annotateParams:     annotateOpenParams,
 	},
 	{
 		tagString:          "Close",
@@ -82,6 +85,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(CloseParams); return ok },
 		parseParams:        parseCloseParams,
 		parseParamsDbg:     parseCloseParamsDbg,
+		annotateParams:     annotateCloseParams,
 	},
 	{
 		tagString:          "Register",
@@ -91,6 +95,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(RegisterParams); return ok },
 		parseParams:        parseRegisterParams,
 		parseParamsDbg:     parseRegisterParamsDbg,
+		annotateParams:     annotateRegisterParams,
 	},
 	{
 		tagString:          "Unregister",
@@ -100,6 +105,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(UnregisterParams); return ok },
 		parseParams:        parseUnregisterParams,
 		parseParamsDbg:     parseUnregisterParamsDbg,
+		annotateParams:     annotateUnregisterParams,
 	},
 	{
 		tagString:          "Get",
@@ -109,6 +115,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "GetNext",
@@ -118,6 +125,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "GetBulk",
@@ -127,6 +135,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(GetBulkParams); return ok },
 		parseParams:        parseGetBulkParams,
 		parseParamsDbg:     parseGetBulkParamsDbg,
+		annotateParams:     annotateGetBulkParams,
 	},
 	{
 		tagString:          "TestSet",
@@ -136,6 +145,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "CommitSet",
@@ -145,6 +155,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "UndoSet",
@@ -154,6 +165,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "CleanupSet",
@@ -163,6 +175,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "Notify",
@@ -172,6 +185,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "Ping",
@@ -181,6 +195,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "IndexAllocate",
@@ -190,6 +205,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "IndexDeallocate",
@@ -199,6 +215,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(NoParams); return ok },
 		parseParams:        parseNoParams,
 		parseParamsDbg:     parseNoParamsDbg,
+		annotateParams:     annotateNoParams,
 	},
 	{
 		tagString:          "AddAgentCaps",
@@ -208,6 +225,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(AddAgentCapsParams); return ok },
 		parseParams:        parseAddAgentCapsParams,
 		parseParamsDbg:     parseAddAgentCapsParamsDbg,
+		annotateParams:     annotateAddAgentCapsParams,
 	},
 	{
 		tagString:          "RemoveAgentCaps",
@@ -217,6 +235,7 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(RemoveAgentCapsParams); return ok },
 		parseParams:        parseRemoveAgentCapsParams,
 		parseParamsDbg:     parseRemoveAgentCapsParamsDbg,
+		annotateParams:     annotateRemoveAgentCapsParams,
 	},
 	{
 		tagString:          "Response",
@@ -226,5 +245,6 @@ var pduTable = [pduTagMax + 1]pduEntry{
 		isApplicableParams: func(p PayloadParams) bool { _, ok := p.(ResponseParams); return ok },
 		parseParams:        parseResponseParams,
 		parseParamsDbg:     parseResponseParamsDbg,
+		annotateParams:     annotateResponseParams,
 	},
 }
@@ -32,7 +32,7 @@ func (d decoder) parseVarbind(data []byte) (vb asn.Varbind, next []byte) {
 }
 
 func (e encoderDbg) appendVarbind(data []byte, vb asn.Varbind) []byte {
-	e.log.Writef("appending Varbind: %s", vb.String())
+	e.log.Writef("appending Varbind: %s", asn.Describe(vb))
 	startindex := len(data)
 
 	e.log.Write("appending Varbind Tag")
@@ -72,7 +72,7 @@ func (d decoderDbg) parseVarbind(startpos int) (vb asn.Varbind, nextpos int) {
 	vb.Oid, _, nextpos = d.parseObjectId(nextpos)
 	d.log.Write("parsing Varbind.Value")
 	vb.Value, nextpos = vbtable[vb.Tag].parseValueDbg(d, nextpos)
-	d.log.Writef("parsed Varbind: %s", vb.String())
+	d.log.Writef("parsed Varbind: %s", asn.Describe(vb))
 	return
 }
 
@@ -82,6 +82,7 @@ type vbentry struct {
 	parseValue     func(d decoder, data []byte) (interface{}, []byte)
 	appendValueDbg func(e encoderDbg, data []byte, v interface{}) []byte
 	parseValueDbg  func(d decoderDbg, startpos int) (v interface{}, nextpos int)
+	annotateValue  func(a *annotator, label string)
 }
 
 var vbtable = map[asn.Tag]vbentry{
@@ -101,6 +102,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseInt32(startpos)
 		},
+		annotateValue: annotateInt32Value,
 	},
 	asn.TagOctetString: {
 		encodingSize: func(v interface{}) int {
@@ -118,25 +120,30 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseOctetString(startpos)
 		},
+		annotateValue: annotateOctetStringValue,
 	},
 	asn.TagObjectId: {
 		encodingSize: func(v interface{}) int {
-			return objectIdEncodingSize(v.([]uint32))
+			id, _ := asn.ObjectIdValue(v)
+			return objectIdEncodingSize(id)
 		},
 		appendValue: func(e encoder, data []byte, v interface{}) []byte {
-			return e.appendObjectId(data, v.([]uint32), 0)
+			id, _ := asn.ObjectIdValue(v)
+			return e.appendObjectId(data, id, 0)
 		},
 		parseValue: func(d decoder, data []byte) (v interface{}, next []byte) {
 			v, _, next = d.parseObjectId(data)
 			return
 		},
 		appendValueDbg: func(e encoderDbg, data []byte, v interface{}) []byte {
-			return e.appendObjectId(data, v.([]uint32), 0)
+			id, _ := asn.ObjectIdValue(v)
+			return e.appendObjectId(data, id, 0)
 		},
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			v, _, nextpos = d.parseObjectId(startpos)
 			return
 		},
+		annotateValue: annotateObjectIdValue,
 	},
 	asn.TagIpAddress: {
 		encodingSize: func(v interface{}) int {
@@ -154,6 +161,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseIpAddress(startpos)
 		},
+		annotateValue: annotateIpAddressValue,
 	},
 	asn.TagCounter32: {
 		encodingSize: func(v interface{}) int {
@@ -171,6 +179,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseUint32(startpos)
 		},
+		annotateValue: annotateUint32Value,
 	},
 	asn.TagGauge32: {
 		encodingSize: func(v interface{}) int {
@@ -188,6 +197,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseUint32(startpos)
 		},
+		annotateValue: annotateUint32Value,
 	},
 	asn.TagTimeTicks: {
 		encodingSize: func(v interface{}) int {
@@ -205,6 +215,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseUint32(startpos)
 		},
+		annotateValue: annotateUint32Value,
 	},
 	asn.TagOpaque: {
 		encodingSize: func(v interface{}) int {
@@ -222,6 +233,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseOpaque(startpos)
 		},
+		annotateValue: annotateOpaqueValue,
 	},
 	asn.TagCounter64: {
 		encodingSize: func(v interface{}) int {
@@ -239,6 +251,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValueDbg: func(d decoderDbg, startpos int) (v interface{}, nextpos int) {
 			return d.parseUint64(startpos)
 		},
+		annotateValue: annotateUint64Value,
 	},
 	asn.TagNull: {
 		encodingSize:   func(v interface{}) int { return 0 },
@@ -246,6 +259,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValue:     func(d decoder, data []byte) (interface{}, []byte) { return nil, data },
 		appendValueDbg: func(e encoderDbg, data []byte, v interface{}) []byte { return data },
 		parseValueDbg:  func(d decoderDbg, startpos int) (v interface{}, nextpos int) { return nil, startpos },
+		annotateValue:  annotateNullValue,
 	},
 	asn.TagNoSuchObject: {
 		encodingSize:   func(v interface{}) int { return 0 },
@@ -253,6 +267,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValue:     func(d decoder, data []byte) (interface{}, []byte) { return nil, data },
 		appendValueDbg: func(e encoderDbg, data []byte, v interface{}) []byte { return data },
 		parseValueDbg:  func(d decoderDbg, startpos int) (v interface{}, nextpos int) { return nil, startpos },
+		annotateValue:  annotateNullValue,
 	},
 	asn.TagNoSuchInstance: {
 		encodingSize:   func(v interface{}) int { return 0 },
@@ -260,6 +275,7 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValue:     func(d decoder, data []byte) (interface{}, []byte) { return nil, data },
 		appendValueDbg: func(e encoderDbg, data []byte, v interface{}) []byte { return data },
 		parseValueDbg:  func(d decoderDbg, startpos int) (v interface{}, nextpos int) { return nil, startpos },
+		annotateValue:  annotateNullValue,
 	},
 	asn.TagEndOfMibView: {
 		encodingSize:   func(v interface{}) int { return 0 },
@@ -267,5 +283,6 @@ var vbtable = map[asn.Tag]vbentry{
 		parseValue:     func(d decoder, data []byte) (interface{}, []byte) { return nil, data },
 		appendValueDbg: func(e encoderDbg, data []byte, v interface{}) []byte { return data },
 		parseValueDbg:  func(d decoderDbg, startpos int) (v interface{}, nextpos int) { return nil, startpos },
+		annotateValue:  annotateNullValue,
 	},
 }
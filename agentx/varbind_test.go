@@ -6,6 +6,7 @@ import (
 
 	"github.com/alexispb/mygosnmp/asn"
 	"github.com/alexispb/mygosnmp/hex"
+	"github.com/alexispb/mygosnmp/oid"
 )
 
 var varbindTestData = struct {
@@ -277,3 +278,23 @@ func TestVarbindEncoding(t *testing.T) {
 		}
 	}
 }
+
+func TestVarbindEncodingOID(t *testing.T) {
+	value := asn.Varbind{Oid: []uint32{1, 3, 6, 1, 2, 1, 1, 2, 0}, Tag: asn.TagObjectId}
+	for _, order := range varbindTestData.order {
+		value.Value = []uint32{1, 3, 6, 1, 4, 1, 999}
+		expected := encoder{byteOrder: order}.appendVarbind(nil, value)
+		value.Value = oid.OID{1, 3, 6, 1, 4, 1, 999}
+		if size := varbindEncodingSize(value); size != len(expected) {
+			t.Errorf("TestVarbindEncodingOID: %s: encoding size %d != %d", order.String(), size, len(expected))
+		}
+		data := encoder{byteOrder: order}.appendVarbind(nil, value)
+		if diff := hex.DumpDiff(expected, data); len(diff) != 0 {
+			t.Errorf("TestVarbindEncodingOID: %s:\n%s", order.String(), diff)
+		}
+		data = encoderDbg{byteOrder: order, log: lognone}.appendVarbind(nil, value)
+		if diff := hex.DumpDiff(expected, data); len(diff) != 0 {
+			t.Errorf("TestVarbindEncodingOID: %s: debug:\n%s", order.String(), diff)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Package ber implements BER encoding and decoding of SNMP messages
+(RFC 1157, RFC 3416, RFC 3412). SNMPv1 and SNMPv2c message is
+
+	Message ::= SEQUENCE {
+		version   INTEGER,
+		community OCTET STRING,
+		data      PDU
+	}
+
+The EncodeMessage function returns data ready for sending across
+the wire, and DecodeMessage decodes received data:
+
+	data, err := ber.EncodeMessage(ber.Message{
+		Version:   ber.Version2c,
+		Community: "public",
+		Pdu: ber.Pdu{
+			Type:      ber.TypeGetRequest,
+			RequestId: 1,
+			Varbinds:  []asn.Varbind{{Oid: sysDescr, Tag: asn.TagNull}},
+		},
+	})
+
+	msg, err := ber.DecodeMessage(data)
+	var serr *ber.SyntaxError
+	if errors.As(err, &serr) {
+		// serr.Offset and serr.Field locate the malformed value
+	}
+
+SNMPv3 messages are encoded and decoded with EncodeMessageV3 and
+DecodeMessageV3 (the DecodeVersion function returns the version
+of received message). The security parameters and encryption
+are handled by the security model (see the snmp/usm package).
+
+Only the definite length form is accepted. The varbind values
+are represented by the Go types described in the asn package.
+
+Also, the package exports primitives (AppendXxx functions and
+Reader) which can be used to encode and decode other BER
+structures (e.g. SNMPv3 message header).
+*/
+package ber
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+var (
+	ErrTruncated = errors.New("truncated data")
+	ErrLength    = errors.New("unsupported length form")
+	ErrTag       = errors.New("unexpected tag")
+	ErrValue     = errors.New("invalid value")
+	ErrTrailing  = errors.New("trailing data")
+)
+
+// SyntaxError describes malformed input. Err is one of the
+// ErrXxx errors.
+type SyntaxError struct {
+	// Offset is the offset of the malformed value in the data.
+	Offset int
+	// Field is the path of the malformed value in the message,
+	// e.g. "Pdu.Varbinds[2].Value".
+	Field string
+	Err   error
+}
+
+func (e *SyntaxError) Error() string {
+	return "ber: " + e.Field + " at offset " + strconv.Itoa(e.Offset) + ": " + e.Err.Error()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Version is the SNMP message version.
+type Version int32
+
+const (
+	Version1  Version = 0
+	Version2c Version = 1
+	Version3  Version = 3
+)
+
+func (v Version) String() string {
+	switch v {
+	case Version1:
+		return "v1"
+	case Version2c:
+		return "v2c"
+	case Version3:
+		return "v3"
+	}
+	return "?" + strconv.FormatInt(int64(v), 10)
+}
+
+// PduType is the identifier octet of the pdu.
+type PduType byte
+
+const (
+	TypeGetRequest     PduType = 0xA0
+	TypeGetNextRequest PduType = 0xA1
+	TypeResponse       PduType = 0xA2
+	TypeSetRequest     PduType = 0xA3
+	TypeTrapV1         PduType = 0xA4
+	TypeGetBulkRequest PduType = 0xA5
+	TypeInformRequest  PduType = 0xA6
+	TypeTrapV2         PduType = 0xA7
+	TypeReport         PduType = 0xA8
+)
+
+var pduTypeString = [...]string{
+	"GetRequest",
+	"GetNextRequest",
+	"Response",
+	"SetRequest",
+	"TrapV1",
+	"GetBulkRequest",
+	"InformRequest",
+	"TrapV2",
+	"Report",
+}
+
+func (t PduType) IsKnown() bool {
+	return TypeGetRequest <= t && t <= TypeReport
+}
+
+func (t PduType) String() string {
+	if !t.IsKnown() {
+		return "?" + strconv.FormatInt(int64(t), 10)
+	}
+	return pduTypeString[t-TypeGetRequest]
+}
+
+// Pdu is the unified representation of SNMP PDU types. The fields
+// encoded for each type are
+//   - TypeGetBulkRequest: RequestId, NonRepeaters, MaxRepetitions,
+//     and Varbinds;
+//   - TypeTrapV1: Enterprise, AgentAddr, GenericTrap, SpecificTrap,
+//     Timestamp, and Varbinds;
+//   - other types: RequestId, ErrorStatus, ErrorIndex, and Varbinds.
+type Pdu struct {
+	Type        PduType
+	RequestId   int32
+	ErrorStatus pduerror.Error
+	ErrorIndex  int32
+
+	NonRepeaters   int32
+	MaxRepetitions int32
+
+	Enterprise   []uint32
+	AgentAddr    [4]byte
+	GenericTrap  int32
+	SpecificTrap int32
+	Timestamp    uint32
+
+	Varbinds []asn.Varbind
+}
+
+// Message is SNMPv1 or SNMPv2c message.
+type Message struct {
+	Version   Version
+	Community string
+	Pdu       Pdu
+}
+
+// EncodeMessage returns the encoded message.
+func EncodeMessage(m Message) ([]byte, error) {
+	content := AppendInteger(nil, byte(asn.TagInteger32), int64(m.Version))
+	content = AppendOctetString(content, byte(asn.TagOctetString), []byte(m.Community))
+	content, err := AppendPdu(content, m.Pdu)
+	if err != nil {
+		return nil, err
+	}
+	return AppendTLV(nil, byte(asn.TagSequence), content), nil
+}
+
+// DecodeMessage decodes SNMPv1 or SNMPv2c message. It returns
+// *SyntaxError if data are malformed. Other versions are
+// reported as ErrValue of the Version field (see DecodeMessageV3).
+func DecodeMessage(data []byte) (m Message, err error) {
+	r := NewReader(data)
+	s, err := r.ReadSequence(byte(asn.TagSequence), "Message")
+	if err != nil {
+		return
+	}
+	if err = r.End("Message"); err != nil {
+		return
+	}
+
+	pos := s.Offset()
+	version, err := s.ReadInteger(byte(asn.TagInteger32), "Version")
+	if err != nil {
+		return
+	}
+	m.Version = Version(version)
+	if m.Version != Version1 && m.Version != Version2c {
+		return m, &SyntaxError{Offset: pos, Field: "Version", Err: ErrValue}
+	}
+
+	community, err := s.ReadOctetString(byte(asn.TagOctetString), "Community")
+	if err != nil {
+		return
+	}
+	m.Community = string(community)
+
+	if m.Pdu, err = s.ReadPdu("Pdu"); err != nil {
+		return
+	}
+	err = s.End("Message")
+	return
+}
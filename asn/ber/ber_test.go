@@ -0,0 +1,232 @@
+package ber
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/hex"
+	"github.com/alexispb/mygosnmp/internal"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+var testGetRequest = Message{
+	Version:   Version2c,
+	Community: "public",
+	Pdu: Pdu{
+		Type:      TypeGetRequest,
+		RequestId: 1,
+		Varbinds: []asn.Varbind{
+			{Oid: []uint32{1, 3, 6, 1, 2, 1, 1, 1, 0}, Tag: asn.TagNull},
+		},
+	},
+}
+
+var testGetRequestData = hex.MustParse(`
+	30 26 02 01 01 04 06 70 75 62 6C 69 63 A0 19 02
+	01 01 02 01 00 02 01 00 30 0E 30 0C 06 08 2B 06
+	01 02 01 01 01 00 05 00`)
+
+func TestEncodeMessage(t *testing.T) {
+	data, err := EncodeMessage(testGetRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, testGetRequestData) {
+		t.Errorf("TestEncodeMessage:\n%s", hex.DumpDiff(testGetRequestData, data))
+	}
+}
+
+var testDataMessage = []Message{
+	testGetRequest,
+	{
+		Version:   Version2c,
+		Community: "private",
+		Pdu: Pdu{
+			Type:        TypeResponse,
+			RequestId:   -2000000000,
+			ErrorStatus: pduerror.NotWritable,
+			ErrorIndex:  2,
+			Varbinds: []asn.Varbind{
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 1}, Tag: asn.TagInteger32, Value: int32(-129)},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 2}, Tag: asn.TagOctetString, Value: "octet string"},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 3}, Tag: asn.TagObjectId, Value: []uint32{2, 999, 4294967295}},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 4}, Tag: asn.TagIpAddress, Value: [4]byte{192, 168, 0, 1}},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 5}, Tag: asn.TagCounter32, Value: uint32(4294967295)},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 6}, Tag: asn.TagGauge32, Value: uint32(128)},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 7}, Tag: asn.TagTimeTicks, Value: uint32(0)},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 8}, Tag: asn.TagOpaque, Value: make([]byte, 300)},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 9}, Tag: asn.TagCounter64, Value: uint64(18446744073709551615)},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 10}, Tag: asn.TagNoSuchObject},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 11}, Tag: asn.TagNoSuchInstance},
+				{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 12}, Tag: asn.TagEndOfMibView},
+			},
+		},
+	},
+	{
+		Version:   Version2c,
+		Community: "public",
+		Pdu: Pdu{
+			Type:           TypeGetBulkRequest,
+			RequestId:      3,
+			NonRepeaters:   1,
+			MaxRepetitions: 10,
+			Varbinds: []asn.Varbind{
+				{Oid: []uint32{1, 3, 6, 1, 2, 1, 1, 3}, Tag: asn.TagNull},
+				{Oid: []uint32{1, 3, 6, 1, 2, 1, 2, 2, 1, 2}, Tag: asn.TagNull},
+			},
+		},
+	},
+	{
+		Version:   Version1,
+		Community: "public",
+		Pdu: Pdu{
+			Type:         TypeTrapV1,
+			Enterprise:   []uint32{1, 3, 6, 1, 4, 1, 999},
+			AgentAddr:    [4]byte{10, 0, 0, 1},
+			GenericTrap:  6,
+			SpecificTrap: 17,
+			Timestamp:    123456,
+		},
+	},
+}
+
+func TestMessage(t *testing.T) {
+	for i, msg := range testDataMessage {
+		data, err := EncodeMessage(msg)
+		if err != nil {
+			t.Errorf("TestMessage[%d]: %v", i, err)
+			continue
+		}
+		res, err := DecodeMessage(data)
+		if err != nil {
+			t.Errorf("TestMessage[%d]: %v\n%s", i, err, hex.Dump("", data))
+			continue
+		}
+		if diff := internal.StructsDiff(msg, res); len(diff) > 0 {
+			t.Errorf("TestMessage[%d]:\n%s", i, diff)
+		}
+	}
+}
+
+var testDataDecodeError = []struct {
+	data   string
+	err    error
+	field  string
+	offset int
+}{
+	// truncated message
+	{data: "30 26 02 01 01", err: ErrTruncated, field: "Message", offset: 0},
+	// indefinite length
+	{data: "30 80 02 01 01 00 00", err: ErrLength, field: "Message", offset: 1},
+	// trailing data after message
+	{data: "30 03 02 01 01 00", err: ErrTrailing, field: "Message", offset: 5},
+	// unsupported version
+	{data: "30 03 02 01 03", err: ErrValue, field: "Version", offset: 2},
+	// community is not an octet string
+	{data: "30 06 02 01 01 02 01 00", err: ErrTag, field: "Community", offset: 5},
+	// unknown pdu type
+	{data: "30 07 02 01 01 04 00 AF 00", err: ErrTag, field: "Pdu", offset: 7},
+	// request-id does not fit in 32 bits
+	{data: "30 14 02 01 01 04 00 A0 0D 02 05 01 00 00 00 00 02 01 00 02 01 00",
+		err: ErrValue, field: "Pdu.RequestId", offset: 9},
+	// missing varbind value
+	{data: "30 17 02 01 01 04 00 A2 10 02 01 01 02 01 00 02 01 00 30 05 30 03 06 01 2B",
+		err: ErrTruncated, field: "Pdu.Varbinds[0].Value", offset: 25},
+	// varbind value of unknown type
+	{data: "30 19 02 01 01 04 00 A2 12 02 01 01 02 01 00 02 01 00 30 07 30 05 06 01 2B 09 00",
+		err: ErrTag, field: "Pdu.Varbinds[0].Value", offset: 25},
+	// IpAddress of wrong length
+	{data: "30 1B 02 01 01 04 00 A2 14 02 01 01 02 01 00 02 01 00 30 09 30 07 06 01 2B 40 02 0A 00",
+		err: ErrValue, field: "Pdu.Varbinds[0].Value", offset: 25},
+	// unterminated subidentifier
+	{data: "30 19 02 01 01 04 00 A2 12 02 01 01 02 01 00 02 01 00 30 07 30 05 06 01 AB 05 00",
+		err: ErrValue, field: "Pdu.Varbinds[0].Oid", offset: 22},
+}
+
+func TestDecodeError(t *testing.T) {
+	for i, test := range testDataDecodeError {
+		_, err := DecodeMessage(hex.MustParse(test.data))
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("TestDecodeError[%d]: expected *SyntaxError, received %v", i, err)
+			continue
+		}
+		if !errors.Is(err, test.err) || serr.Field != test.field || serr.Offset != test.offset {
+			t.Errorf("TestDecodeError[%d]: %v", i, err)
+		}
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	for i, msg := range []Message{
+		{Pdu: Pdu{Type: 0x30}},
+		{Pdu: Pdu{Type: TypeTrapV1, Enterprise: []uint32{1}}},
+		{Pdu: Pdu{Type: TypeGetRequest, Varbinds: []asn.Varbind{
+			{Oid: []uint32{1, 3}, Tag: asn.TagInteger32, Value: "1"}}}},
+		{Pdu: Pdu{Type: TypeGetRequest, Varbinds: []asn.Varbind{
+			{Oid: []uint32{1, 40}, Tag: asn.TagNull}}}},
+	} {
+		if _, err := EncodeMessage(msg); !errors.Is(err, ErrValue) {
+			t.Errorf("TestEncodeError[%d]: %v", i, err)
+		}
+	}
+}
+
+func TestMessageV3(t *testing.T) {
+	msg := MessageV3{
+		MsgId:              100,
+		MaxSize:            65507,
+		Flags:              FlagAuth | FlagReportable,
+		SecurityModel:      SecurityModelUSM,
+		SecurityParameters: []byte{0x30, 0x00},
+		ScopedPdu: ScopedPdu{
+			ContextEngineId: []byte("engine"),
+			ContextName:     "context",
+			Pdu: Pdu{
+				Type:      TypeGetRequest,
+				RequestId: 100,
+				Varbinds:  []asn.Varbind{{Oid: []uint32{1, 3, 6, 1}, Tag: asn.TagNull}},
+			},
+		},
+	}
+	data, err := EncodeMessageV3(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := DecodeVersion(data); err != nil || version != Version3 {
+		t.Errorf("TestMessageV3: version %v, %v", version, err)
+	}
+	offset, err := SecurityParametersOffset(data)
+	if err != nil || !bytes.Equal(data[offset:offset+2], msg.SecurityParameters) {
+		t.Errorf("TestMessageV3: security parameters offset %d, %v", offset, err)
+	}
+	res, err := DecodeMessageV3(data)
+	if err != nil {
+		t.Fatalf("TestMessageV3: %v\n%s", err, hex.Dump("", data))
+	}
+	if diff := internal.StructsDiff(msg, res); len(diff) > 0 {
+		t.Errorf("TestMessageV3:\n%s", diff)
+	}
+	if _, err := DecodeMessage(data); !errors.Is(err, ErrValue) {
+		t.Errorf("TestMessageV3: DecodeMessage error %v", err)
+	}
+}
+
+func TestEncodeOID(t *testing.T) {
+	vb := asn.Varbind{Oid: []uint32{1, 3, 6, 1, 2, 1, 1, 2, 0}, Tag: asn.TagObjectId, Value: []uint32{1, 3, 6, 1, 4, 1, 999}}
+	expected, err := AppendVarbind(nil, vb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vb.Value = oid.OID{1, 3, 6, 1, 4, 1, 999}
+	data, err := AppendVarbind(nil, vb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("TestEncodeOID:\n%s", hex.DumpDiff(expected, data))
+	}
+}
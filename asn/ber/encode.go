@@ -0,0 +1,184 @@
+package ber
+
+import (
+	"fmt"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// AppendHeader appends the identifier octet and the definite
+// length octets (in the shortest form) to dst.
+func AppendHeader(dst []byte, tag byte, length int) []byte {
+	dst = append(dst, tag)
+	if length < 0x80 {
+		return append(dst, byte(length))
+	}
+	n := 0
+	for l := length; l > 0; l >>= 8 {
+		n++
+	}
+	dst = append(dst, 0x80|byte(n))
+	for i := n - 1; i >= 0; i-- {
+		dst = append(dst, byte(length>>(8*i)))
+	}
+	return dst
+}
+
+// AppendTLV appends the encoding of tag, length and content to dst.
+func AppendTLV(dst []byte, tag byte, content []byte) []byte {
+	return append(AppendHeader(dst, tag, len(content)), content...)
+}
+
+// AppendInteger appends the two's complement encoding of v
+// in the minimal number of octets.
+func AppendInteger(dst []byte, tag byte, v int64) []byte {
+	n := 1
+	for u := v; u > 0x7F || u < -0x80; u >>= 8 {
+		n++
+	}
+	dst = append(dst, tag, byte(n))
+	for i := n - 1; i >= 0; i-- {
+		dst = append(dst, byte(v>>(8*i)))
+	}
+	return dst
+}
+
+// AppendUnsigned appends the encoding of the unsigned value v
+// (e.g. Counter32 or Counter64) in the minimal number of octets.
+// A leading zero octet is added if the high bit of v is set.
+func AppendUnsigned(dst []byte, tag byte, v uint64) []byte {
+	n := 1
+	for u := v; u > 0x7F; u >>= 8 {
+		n++
+	}
+	dst = append(dst, tag, byte(n))
+	for i := n - 1; i >= 0; i-- {
+		// v>>64 == 0 for the leading zero octet
+		dst = append(dst, byte(v>>(8*i)))
+	}
+	return dst
+}
+
+// AppendOctetString appends the encoding of the octet string s.
+func AppendOctetString(dst []byte, tag byte, s []byte) []byte {
+	return AppendTLV(dst, tag, s)
+}
+
+// AppendNull appends the encoding of a value with empty content
+// (Null and the exception values like NoSuchObject).
+func AppendNull(dst []byte, tag byte) []byte {
+	return append(dst, tag, 0)
+}
+
+// AppendObjectId appends the encoding of the object identifier id.
+// The id must include at least two subidentifiers, the first one
+// must not exceed 2, and the second one must be less than 40 if
+// the first one is 0 or 1.
+func AppendObjectId(dst []byte, tag byte, id []uint32) ([]byte, error) {
+	if len(id) < 2 || id[0] > 2 || id[0] < 2 && id[1] >= 40 || id[0] == 2 && id[1] > 0xFFFFFFFF-80 {
+		return dst, fmt.Errorf("ber: invalid object identifier %v: %w", id, ErrValue)
+	}
+	content := appendSubid(make([]byte, 0, len(id)+4), id[0]*40+id[1])
+	for _, subid := range id[2:] {
+		content = appendSubid(content, subid)
+	}
+	return AppendTLV(dst, tag, content), nil
+}
+
+// appendSubid appends the base 128 encoding of the subidentifier.
+func appendSubid(dst []byte, subid uint32) []byte {
+	n := 1
+	for s := subid; s > 0x7F; s >>= 7 {
+		n++
+	}
+	for i := n - 1; i > 0; i-- {
+		dst = append(dst, 0x80|byte(subid>>(7*i)))
+	}
+	return append(dst, byte(subid)&0x7F)
+}
+
+// AppendVarbind appends the encoding of the varbind, i.e. the
+// sequence of the object identifier and the value. The type of
+// vb.Value must conform to vb.Tag (see asn.Tag.IsValidValue).
+func AppendVarbind(dst []byte, vb asn.Varbind) ([]byte, error) {
+	if !vb.Tag.IsValueTag() || vb.Tag == asn.TagSequence || !vb.Tag.IsValidValue(vb.Value) {
+		return dst, fmt.Errorf("ber: invalid varbind %s: %w", vb.String(), ErrValue)
+	}
+	content, err := AppendObjectId(nil, byte(asn.TagObjectId), vb.Oid)
+	if err != nil {
+		return dst, err
+	}
+
+	tag := byte(vb.Tag)
+	switch v := vb.Value.(type) {
+	case int32:
+		content = AppendInteger(content, tag, int64(v))
+	case string:
+		content = AppendOctetString(content, tag, []byte(v))
+	case []uint32, oid.OID:
+		id, _ := asn.ObjectIdValue(v)
+		if content, err = AppendObjectId(content, tag, id); err != nil {
+			return dst, err
+		}
+	case [4]byte:
+		content = AppendOctetString(content, tag, v[:])
+	case uint32:
+		content = AppendUnsigned(content, tag, uint64(v))
+	case []byte:
+		content = AppendOctetString(content, tag, v)
+	case uint64:
+		content = AppendUnsigned(content, tag, v)
+	default:
+		content = AppendNull(content, tag)
+	}
+	return AppendTLV(dst, byte(asn.TagSequence), content), nil
+}
+
+// AppendVarbindList appends the encoding of the sequence of varbinds.
+func AppendVarbindList(dst []byte, vbs []asn.Varbind) ([]byte, error) {
+	var content []byte
+	for _, vb := range vbs {
+		var err error
+		if content, err = AppendVarbind(content, vb); err != nil {
+			return dst, err
+		}
+	}
+	return AppendTLV(dst, byte(asn.TagSequence), content), nil
+}
+
+// AppendPdu appends the encoding of the pdu. The encoded fields
+// depend on the pdu type (see Pdu).
+func AppendPdu(dst []byte, pdu Pdu) ([]byte, error) {
+	if !pdu.Type.IsKnown() {
+		return dst, fmt.Errorf("ber: invalid pdu type %s: %w", pdu.Type.String(), ErrValue)
+	}
+
+	var content []byte
+	tagInteger := byte(asn.TagInteger32)
+	switch pdu.Type {
+	case TypeTrapV1:
+		var err error
+		if content, err = AppendObjectId(content, byte(asn.TagObjectId), pdu.Enterprise); err != nil {
+			return dst, err
+		}
+		content = AppendOctetString(content, byte(asn.TagIpAddress), pdu.AgentAddr[:])
+		content = AppendInteger(content, tagInteger, int64(pdu.GenericTrap))
+		content = AppendInteger(content, tagInteger, int64(pdu.SpecificTrap))
+		content = AppendUnsigned(content, byte(asn.TagTimeTicks), uint64(pdu.Timestamp))
+	case TypeGetBulkRequest:
+		content = AppendInteger(content, tagInteger, int64(pdu.RequestId))
+		content = AppendInteger(content, tagInteger, int64(pdu.NonRepeaters))
+		content = AppendInteger(content, tagInteger, int64(pdu.MaxRepetitions))
+	default:
+		content = AppendInteger(content, tagInteger, int64(pdu.RequestId))
+		content = AppendInteger(content, tagInteger, int64(pdu.ErrorStatus))
+		content = AppendInteger(content, tagInteger, int64(pdu.ErrorIndex))
+	}
+
+	content, err := AppendVarbindList(content, pdu.Varbinds)
+	if err != nil {
+		return dst, err
+	}
+	return AppendTLV(dst, byte(pdu.Type), content), nil
+}
@@ -0,0 +1,371 @@
+package ber
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+// Reader decodes BER encoded values one by one. All methods
+// return *SyntaxError if data can not be decoded. The field
+// argument names the decoded value in the error.
+type Reader struct {
+	data []byte
+	pos  int
+	// base is the offset of data in the decoded message.
+	base int
+}
+
+// NewReader returns a reader of data.
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Offset returns the offset of the next value in the decoded
+// message.
+func (r *Reader) Offset() int {
+	return r.base + r.pos
+}
+
+// Len returns the number of bytes which are not read yet.
+func (r *Reader) Len() int {
+	return len(r.data) - r.pos
+}
+
+func (r *Reader) errorAt(pos int, field string, err error) error {
+	return &SyntaxError{Offset: r.base + pos, Field: field, Err: err}
+}
+
+// PeekTag returns the identifier octet of the next value.
+func (r *Reader) PeekTag(field string) (byte, error) {
+	if r.Len() == 0 {
+		return 0, r.errorAt(r.pos, field, ErrTruncated)
+	}
+	return r.data[r.pos], nil
+}
+
+// ReadTLV reads the next value and returns its identifier octet
+// and content. Only single octet identifiers and definite lengths
+// are accepted.
+func (r *Reader) ReadTLV(field string) (tag byte, content []byte, err error) {
+	pos := r.pos
+	if len(r.data)-pos < 2 {
+		return 0, nil, r.errorAt(pos, field, ErrTruncated)
+	}
+	tag = r.data[pos]
+	if tag&0x1F == 0x1F {
+		return 0, nil, r.errorAt(pos, field, ErrTag)
+	}
+	pos++
+
+	length := int(r.data[pos])
+	pos++
+	if length >= 0x80 {
+		n := length & 0x7F
+		// n == 0 is the indefinite form, and 127 is reserved
+		if n == 0 || n > 4 {
+			return 0, nil, r.errorAt(pos-1, field, ErrLength)
+		}
+		if len(r.data)-pos < n {
+			return 0, nil, r.errorAt(pos-1, field, ErrTruncated)
+		}
+		length = 0
+		for _, b := range r.data[pos : pos+n] {
+			length = length<<8 | int(b)
+		}
+		pos += n
+	}
+	if length < 0 || length > len(r.data)-pos {
+		return 0, nil, r.errorAt(r.pos, field, ErrTruncated)
+	}
+
+	r.pos = pos + length
+	return tag, r.data[pos:r.pos], nil
+}
+
+// read reads the next value and checks its identifier octet.
+// It returns the content and the position of the value.
+func (r *Reader) read(tag byte, field string) (content []byte, pos int, err error) {
+	pos = r.pos
+	t, content, err := r.ReadTLV(field)
+	if err != nil {
+		return nil, pos, err
+	}
+	if t != tag {
+		return nil, pos, r.errorAt(pos, field, ErrTag)
+	}
+	return content, pos, nil
+}
+
+// ReadSequence reads a constructed value (e.g. sequence or pdu)
+// and returns a reader of its content.
+func (r *Reader) ReadSequence(tag byte, field string) (*Reader, error) {
+	content, _, err := r.read(tag, field)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{data: content, base: r.base + r.pos - len(content)}, nil
+}
+
+// ReadInteger reads a signed integer which fits in 64 bits.
+func (r *Reader) ReadInteger(tag byte, field string) (int64, error) {
+	content, pos, err := r.read(tag, field)
+	if err != nil {
+		return 0, err
+	}
+	if len(content) == 0 || len(content) > 8 {
+		return 0, r.errorAt(pos, field, ErrValue)
+	}
+	v := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// ReadUnsigned reads an unsigned integer which fits in 64 bits.
+// The sign bit is ignored, so the values encoded by the broken
+// implementations without the leading zero octet are accepted.
+func (r *Reader) ReadUnsigned(tag byte, field string) (uint64, error) {
+	content, pos, err := r.read(tag, field)
+	if err != nil {
+		return 0, err
+	}
+	if len(content) == 9 && content[0] == 0 {
+		content = content[1:]
+	}
+	if len(content) == 0 || len(content) > 8 {
+		return 0, r.errorAt(pos, field, ErrValue)
+	}
+	var v uint64
+	for _, b := range content {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+func (r *Reader) readInt32(tag byte, field string) (int32, error) {
+	pos := r.pos
+	v, err := r.ReadInteger(tag, field)
+	if err == nil && (v < math.MinInt32 || v > math.MaxInt32) {
+		err = r.errorAt(pos, field, ErrValue)
+	}
+	return int32(v), err
+}
+
+func (r *Reader) readUint32(tag byte, field string) (uint32, error) {
+	pos := r.pos
+	v, err := r.ReadUnsigned(tag, field)
+	if err == nil && v > math.MaxUint32 {
+		err = r.errorAt(pos, field, ErrValue)
+	}
+	return uint32(v), err
+}
+
+// ReadOctetString reads an octet string. The returned slice
+// refers to the decoded data.
+func (r *Reader) ReadOctetString(tag byte, field string) ([]byte, error) {
+	content, _, err := r.read(tag, field)
+	return content, err
+}
+
+// ReadNull reads a value with empty content.
+func (r *Reader) ReadNull(tag byte, field string) error {
+	content, pos, err := r.read(tag, field)
+	if err == nil && len(content) != 0 {
+		err = r.errorAt(pos, field, ErrValue)
+	}
+	return err
+}
+
+// ReadObjectId reads an object identifier.
+func (r *Reader) ReadObjectId(tag byte, field string) ([]uint32, error) {
+	content, pos, err := r.read(tag, field)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == 0 || content[len(content)-1]&0x80 != 0 {
+		return nil, r.errorAt(pos, field, ErrValue)
+	}
+
+	id := make([]uint32, 1, len(content)+1)
+	var subid uint32
+	for _, b := range content {
+		if subid > math.MaxUint32>>7 {
+			return nil, r.errorAt(pos, field, ErrValue)
+		}
+		subid = subid<<7 | uint32(b&0x7F)
+		if b&0x80 != 0 {
+			continue
+		}
+		if len(id) == 1 {
+			// the first subidentifier encodes the first two arcs
+			switch {
+			case subid < 40:
+				id[0] = 0
+			case subid < 80:
+				id[0], subid = 1, subid-40
+			default:
+				id[0], subid = 2, subid-80
+			}
+		}
+		id = append(id, subid)
+		subid = 0
+	}
+	return id, nil
+}
+
+// End returns an error if the reader has unread data.
+func (r *Reader) End(field string) error {
+	if r.Len() != 0 {
+		return r.errorAt(r.pos, field, ErrTrailing)
+	}
+	return nil
+}
+
+// ReadVarbind reads a varbind. The value type depends on the
+// value tag as described in the asn package.
+func (r *Reader) ReadVarbind(field string) (vb asn.Varbind, err error) {
+	s, err := r.ReadSequence(byte(asn.TagSequence), field)
+	if err != nil {
+		return
+	}
+	if vb.Oid, err = s.ReadObjectId(byte(asn.TagObjectId), field+".Oid"); err != nil {
+		return
+	}
+
+	field += ".Value"
+	tag, err := s.PeekTag(field)
+	if err != nil {
+		return
+	}
+	vb.Tag = asn.Tag(tag)
+	switch vb.Tag {
+	case asn.TagInteger32:
+		vb.Value, err = s.readInt32(tag, field)
+	case asn.TagOctetString:
+		var v []byte
+		v, err = s.ReadOctetString(tag, field)
+		vb.Value = string(v)
+	case asn.TagNull, asn.TagNoSuchObject, asn.TagNoSuchInstance, asn.TagEndOfMibView:
+		err = s.ReadNull(tag, field)
+	case asn.TagObjectId:
+		vb.Value, err = s.ReadObjectId(tag, field)
+	case asn.TagIpAddress:
+		pos := s.pos
+		var v []byte
+		if v, err = s.ReadOctetString(tag, field); err == nil && len(v) != 4 {
+			err = s.errorAt(pos, field, ErrValue)
+		}
+		var ip [4]byte
+		copy(ip[:], v)
+		vb.Value = ip
+	case asn.TagCounter32, asn.TagGauge32, asn.TagTimeTicks:
+		vb.Value, err = s.readUint32(tag, field)
+	case asn.TagOpaque:
+		var v []byte
+		v, err = s.ReadOctetString(tag, field)
+		vb.Value = append([]byte(nil), v...)
+	case asn.TagCounter64:
+		vb.Value, err = s.ReadUnsigned(tag, field)
+	default:
+		err = s.errorAt(s.pos, field, ErrTag)
+	}
+	if err != nil {
+		return
+	}
+	err = s.End(field)
+	return
+}
+
+// ReadVarbindList reads a sequence of varbinds.
+func (r *Reader) ReadVarbindList(field string) (vbs []asn.Varbind, err error) {
+	s, err := r.ReadSequence(byte(asn.TagSequence), field)
+	if err != nil {
+		return
+	}
+	for i := 0; s.Len() > 0; i++ {
+		vb, err := s.ReadVarbind(field + "[" + strconv.Itoa(i) + "]")
+		if err != nil {
+			return nil, err
+		}
+		vbs = append(vbs, vb)
+	}
+	return
+}
+
+// ReadPdu reads a pdu of any known type.
+func (r *Reader) ReadPdu(field string) (pdu Pdu, err error) {
+	tag, err := r.PeekTag(field)
+	if err != nil {
+		return
+	}
+	pdu.Type = PduType(tag)
+	if !pdu.Type.IsKnown() {
+		return pdu, r.errorAt(r.pos, field, ErrTag)
+	}
+	s, err := r.ReadSequence(tag, field)
+	if err != nil {
+		return
+	}
+
+	tagInteger := byte(asn.TagInteger32)
+	switch pdu.Type {
+	case TypeTrapV1:
+		if pdu.Enterprise, err = s.ReadObjectId(byte(asn.TagObjectId), field+".Enterprise"); err != nil {
+			return
+		}
+		pos := s.pos
+		var addr []byte
+		if addr, err = s.ReadOctetString(byte(asn.TagIpAddress), field+".AgentAddr"); err != nil {
+			return
+		}
+		if len(addr) != 4 {
+			return pdu, s.errorAt(pos, field+".AgentAddr", ErrValue)
+		}
+		copy(pdu.AgentAddr[:], addr)
+		if pdu.GenericTrap, err = s.readInt32(tagInteger, field+".GenericTrap"); err != nil {
+			return
+		}
+		if pdu.SpecificTrap, err = s.readInt32(tagInteger, field+".SpecificTrap"); err != nil {
+			return
+		}
+		if pdu.Timestamp, err = s.readUint32(byte(asn.TagTimeTicks), field+".Timestamp"); err != nil {
+			return
+		}
+	case TypeGetBulkRequest:
+		if pdu.RequestId, err = s.readInt32(tagInteger, field+".RequestId"); err != nil {
+			return
+		}
+		if pdu.NonRepeaters, err = s.readInt32(tagInteger, field+".NonRepeaters"); err != nil {
+			return
+		}
+		if pdu.MaxRepetitions, err = s.readInt32(tagInteger, field+".MaxRepetitions"); err != nil {
+			return
+		}
+	default:
+		if pdu.RequestId, err = s.readInt32(tagInteger, field+".RequestId"); err != nil {
+			return
+		}
+		pos := s.pos
+		var status int32
+		if status, err = s.readInt32(tagInteger, field+".ErrorStatus"); err != nil {
+			return
+		}
+		if status < 0 || status > int32(pduerror.InconsistentName) {
+			return pdu, s.errorAt(pos, field+".ErrorStatus", ErrValue)
+		}
+		pdu.ErrorStatus = pduerror.Error(status)
+		if pdu.ErrorIndex, err = s.readInt32(tagInteger, field+".ErrorIndex"); err != nil {
+			return
+		}
+	}
+
+	if pdu.Varbinds, err = s.ReadVarbindList(field + ".Varbinds"); err != nil {
+		return
+	}
+	err = s.End(field)
+	return
+}
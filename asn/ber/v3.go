@@ -0,0 +1,202 @@
+package ber
+
+import (
+	"github.com/alexispb/mygosnmp/asn"
+)
+
+// MsgFlags is the msgFlags field of SNMPv3 message (RFC 3412).
+type MsgFlags byte
+
+const (
+	FlagAuth       MsgFlags = 0x01
+	FlagPriv       MsgFlags = 0x02
+	FlagReportable MsgFlags = 0x04
+)
+
+// SecurityModelUSM is the msgSecurityModel of User-based
+// Security Model (RFC 3414).
+const SecurityModelUSM = 3
+
+// ScopedPdu is the pdu with its context (RFC 3412).
+type ScopedPdu struct {
+	ContextEngineId []byte
+	ContextName     string
+	Pdu             Pdu
+}
+
+// MessageV3 is SNMPv3 message (RFC 3412):
+//
+//	SNMPv3Message ::= SEQUENCE {
+//		msgVersion            INTEGER,
+//		msgGlobalData         HeaderData,
+//		msgSecurityParameters OCTET STRING,
+//		msgData               ScopedPduData
+//	}
+//
+// The msgData is the plaintext ScopedPdu if FlagPriv is not set,
+// and EncryptedPdu otherwise. The SecurityParameters are encoded
+// by the security model (see the snmp/usm package).
+type MessageV3 struct {
+	MsgId              int32
+	MaxSize            int32
+	Flags              MsgFlags
+	SecurityModel      int32
+	SecurityParameters []byte
+	ScopedPdu          ScopedPdu
+	EncryptedPdu       []byte
+}
+
+// AppendScopedPdu appends the encoding of the scoped pdu.
+func AppendScopedPdu(dst []byte, sp ScopedPdu) ([]byte, error) {
+	content := AppendOctetString(nil, byte(asn.TagOctetString), sp.ContextEngineId)
+	content = AppendOctetString(content, byte(asn.TagOctetString), []byte(sp.ContextName))
+	content, err := AppendPdu(content, sp.Pdu)
+	if err != nil {
+		return dst, err
+	}
+	return AppendTLV(dst, byte(asn.TagSequence), content), nil
+}
+
+// ReadScopedPdu reads a scoped pdu.
+func (r *Reader) ReadScopedPdu(field string) (sp ScopedPdu, err error) {
+	s, err := r.ReadSequence(byte(asn.TagSequence), field)
+	if err != nil {
+		return
+	}
+	id, err := s.ReadOctetString(byte(asn.TagOctetString), field+".ContextEngineId")
+	if err != nil {
+		return
+	}
+	sp.ContextEngineId = append([]byte(nil), id...)
+	name, err := s.ReadOctetString(byte(asn.TagOctetString), field+".ContextName")
+	if err != nil {
+		return
+	}
+	sp.ContextName = string(name)
+	if sp.Pdu, err = s.ReadPdu(field + ".Pdu"); err != nil {
+		return
+	}
+	err = s.End(field)
+	return
+}
+
+// DecodeScopedPdu decodes the decrypted scoped pdu. The data
+// which follow the scoped pdu (e.g. padding) are ignored.
+func DecodeScopedPdu(data []byte) (ScopedPdu, error) {
+	return NewReader(data).ReadScopedPdu("ScopedPdu")
+}
+
+func appendHeaderData(dst []byte, m MessageV3) []byte {
+	tagInteger := byte(asn.TagInteger32)
+	content := AppendInteger(nil, tagInteger, int64(m.MsgId))
+	content = AppendInteger(content, tagInteger, int64(m.MaxSize))
+	content = AppendOctetString(content, byte(asn.TagOctetString), []byte{byte(m.Flags)})
+	content = AppendInteger(content, tagInteger, int64(m.SecurityModel))
+	return AppendTLV(dst, byte(asn.TagSequence), content)
+}
+
+// EncodeMessageV3 returns the encoded SNMPv3 message.
+func EncodeMessageV3(m MessageV3) ([]byte, error) {
+	content := AppendInteger(nil, byte(asn.TagInteger32), int64(Version3))
+	content = appendHeaderData(content, m)
+	content = AppendOctetString(content, byte(asn.TagOctetString), m.SecurityParameters)
+	if m.Flags&FlagPriv != 0 {
+		content = AppendOctetString(content, byte(asn.TagOctetString), m.EncryptedPdu)
+	} else {
+		var err error
+		if content, err = AppendScopedPdu(content, m.ScopedPdu); err != nil {
+			return nil, err
+		}
+	}
+	return AppendTLV(nil, byte(asn.TagSequence), content), nil
+}
+
+// readHeader reads the message fields preceding msgData.
+func readHeader(data []byte) (s *Reader, m MessageV3, err error) {
+	r := NewReader(data)
+	if s, err = r.ReadSequence(byte(asn.TagSequence), "Message"); err != nil {
+		return
+	}
+	if err = r.End("Message"); err != nil {
+		return
+	}
+
+	pos := s.Offset()
+	version, err := s.ReadInteger(byte(asn.TagInteger32), "Version")
+	if err != nil {
+		return
+	}
+	if Version(version) != Version3 {
+		return s, m, &SyntaxError{Offset: pos, Field: "Version", Err: ErrValue}
+	}
+
+	h, err := s.ReadSequence(byte(asn.TagSequence), "HeaderData")
+	if err != nil {
+		return
+	}
+	tagInteger := byte(asn.TagInteger32)
+	if m.MsgId, err = h.readInt32(tagInteger, "HeaderData.MsgId"); err != nil {
+		return
+	}
+	if m.MaxSize, err = h.readInt32(tagInteger, "HeaderData.MaxSize"); err != nil {
+		return
+	}
+	pos = h.Offset()
+	flags, err := h.ReadOctetString(byte(asn.TagOctetString), "HeaderData.Flags")
+	if err != nil {
+		return
+	}
+	if len(flags) != 1 {
+		return s, m, &SyntaxError{Offset: pos, Field: "HeaderData.Flags", Err: ErrValue}
+	}
+	m.Flags = MsgFlags(flags[0])
+	if m.SecurityModel, err = h.readInt32(tagInteger, "HeaderData.SecurityModel"); err != nil {
+		return
+	}
+	if err = h.End("HeaderData"); err != nil {
+		return
+	}
+
+	m.SecurityParameters, err = s.ReadOctetString(byte(asn.TagOctetString), "SecurityParameters")
+	return
+}
+
+// DecodeMessageV3 decodes SNMPv3 message. The SecurityParameters
+// and EncryptedPdu fields refer to data.
+func DecodeMessageV3(data []byte) (m MessageV3, err error) {
+	s, m, err := readHeader(data)
+	if err != nil {
+		return
+	}
+	if m.Flags&FlagPriv != 0 {
+		m.EncryptedPdu, err = s.ReadOctetString(byte(asn.TagOctetString), "EncryptedPdu")
+	} else {
+		m.ScopedPdu, err = s.ReadScopedPdu("ScopedPdu")
+	}
+	if err != nil {
+		return
+	}
+	err = s.End("Message")
+	return
+}
+
+// SecurityParametersOffset returns the offset of the content of
+// msgSecurityParameters in the encoded SNMPv3 message. It is used
+// by security models to authenticate the message.
+func SecurityParametersOffset(data []byte) (int, error) {
+	s, m, err := readHeader(data)
+	if err != nil {
+		return 0, err
+	}
+	return s.Offset() - len(m.SecurityParameters), nil
+}
+
+// DecodeVersion returns the version of the encoded message.
+func DecodeVersion(data []byte) (Version, error) {
+	s, err := NewReader(data).ReadSequence(byte(asn.TagSequence), "Message")
+	if err != nil {
+		return 0, err
+	}
+	version, err := s.ReadInteger(byte(asn.TagInteger32), "Version")
+	return Version(version), err
+}
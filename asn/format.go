@@ -0,0 +1,72 @@
+package asn
+
+import (
+	"strings"
+
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// Format formats the values of the object, e.g. with the
+// DISPLAY-HINT or the enumeration of the object syntax (see
+// smi.MIB.RegisterFormats). FprintValue returns false if the value
+// is not formatted, then it is printed as is (see Tag.Fprint).
+type Format interface {
+	FprintValue(sb *strings.Builder, tag Tag, v interface{}) bool
+}
+
+// Formats maps the objects to the formats of their values. The
+// format of the object applies to all its instances. Formats can
+// be used concurrently (the tree locks itself).
+type Formats struct {
+	formats *oid.Tree[Format]
+}
+
+func NewFormats() *Formats {
+	return &Formats{formats: oid.NewTree[Format]()}
+}
+
+// DefaultFormats is the formats used by FormatValue and Describe.
+var DefaultFormats = NewFormats()
+
+// Register sets the format of the object values.
+func (f *Formats) Register(id []uint32, format Format) {
+	f.formats.Insert(id, format)
+}
+
+// FprintValue appends the varbind value formatted with the format
+// of the longest registered prefix of the varbind oid.
+func (f *Formats) FprintValue(sb *strings.Builder, vb Varbind) {
+	_, format, ok := f.formats.LongestPrefix(vb.Oid)
+	if ok && vb.Tag.IsValidValue(vb.Value) && format.FprintValue(sb, vb.Tag, vb.Value) {
+		return
+	}
+	vb.Tag.Fprint(sb, vb.Value)
+}
+
+// Fprint appends the varbind string representation with the
+// formatted value (see Varbind.Fprint).
+func (f *Formats) Fprint(sb *strings.Builder, vb Varbind) {
+	sb.WriteByte('{')
+	oid.Fprint(sb, vb.Oid)
+	sb.WriteByte(' ')
+	sb.WriteString(vb.Tag.String())
+	sb.WriteString(": ")
+	f.FprintValue(sb, vb)
+	sb.WriteByte('}')
+}
+
+// FormatValue returns the varbind value formatted with
+// DefaultFormats (e.g. up(1) for ifAdminStatus).
+func FormatValue(vb Varbind) string {
+	var sb strings.Builder
+	DefaultFormats.FprintValue(&sb, vb)
+	return sb.String()
+}
+
+// Describe returns the varbind string representation with the
+// value formatted with DefaultFormats.
+func Describe(vb Varbind) string {
+	var sb strings.Builder
+	DefaultFormats.Fprint(&sb, vb)
+	return sb.String()
+}
@@ -25,6 +25,18 @@ func (tag Tag) String() string {
 	return table[tag].tagstr
 }
 
+// ObjectIdValue returns the value of ObjectId varbind, which is
+// either []uint32 or oid.OID, as []uint32.
+func ObjectIdValue(v interface{}) (id []uint32, ok bool) {
+	switch v := v.(type) {
+	case []uint32:
+		return v, true
+	case oid.OID:
+		return v, true
+	}
+	return nil, false
+}
+
 func (tag Tag) IsValidValue(v interface{}) bool {
 	return tag.IsKnown() && table[tag].isvalid(v)
 }
@@ -75,11 +87,12 @@ var table = map[Tag]entry{
 	TagObjectId: {
 		tagstr: "ObjectId",
 		isvalid: func(v interface{}) bool {
-			_, ok := v.([]uint32)
+			_, ok := ObjectIdValue(v)
 			return ok
 		},
 		fprint: func(sb *strings.Builder, v interface{}) {
-			oid.Fprint(sb, v.([]uint32))
+			id, _ := ObjectIdValue(v)
+			oid.Fprint(sb, id)
 		},
 	},
 	TagSequence: {
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/smi"
+)
+
+// reserved are the names of the declarations which are generated
+// for every module.
+var reserved = map[string]bool{
+	"Module":               true,
+	"RegisterNames":        true,
+	"Handler":              true,
+	"UnimplementedHandler": true,
+	"Register":             true,
+}
+
+// goType is the Go type of the object values.
+type goType struct {
+	Name string
+	// Tag is the asn.Tag constant, and Option is the option of
+	// mib.Binding struct tag.
+	Tag    string
+	Option string
+	// Zero is the value returned by UnimplementedHandler.
+	Zero string
+	// Get converts the handler value to the varbind value, and
+	// Set converts the varbind value v to the handler value.
+	Get string
+	Set string
+}
+
+// goTypes maps the asn types to Go types. The OctetString values
+// are strings if they are displayed as text (see goTypeOf).
+var goTypes = map[asn.Tag]goType{
+	asn.TagInteger32:   {Name: "int32", Tag: "asn.TagInteger32", Option: "integer32", Zero: "0", Get: "%s", Set: "v.(int32)"},
+	asn.TagOctetString: {Name: "[]byte", Tag: "asn.TagOctetString", Option: "octetstring", Zero: "nil", Get: "string(%s)", Set: "[]byte(v.(string))"},
+	asn.TagObjectId:    {Name: "oid.OID", Tag: "asn.TagObjectId", Option: "objectid", Zero: "oid.OID{0, 0}", Get: "%s", Set: "oid.OID(v.([]uint32))"},
+	asn.TagIpAddress:   {Name: "net.IP", Tag: "asn.TagIpAddress", Option: "ipaddress", Zero: "nil", Get: "ipAddressValue(%s)", Set: "ipAddress(v.([4]byte))"},
+	asn.TagCounter32:   {Name: "uint32", Tag: "asn.TagCounter32", Option: "counter32", Zero: "0", Get: "%s", Set: "v.(uint32)"},
+	asn.TagGauge32:     {Name: "uint32", Tag: "asn.TagGauge32", Option: "gauge32", Zero: "0", Get: "%s", Set: "v.(uint32)"},
+	asn.TagTimeTicks:   {Name: "uint32", Tag: "asn.TagTimeTicks", Option: "timeticks", Zero: "0", Get: "%s", Set: "v.(uint32)"},
+	asn.TagOpaque:      {Name: "[]byte", Tag: "asn.TagOpaque", Option: "opaque", Zero: "nil", Get: "%s", Set: "v.([]byte)"},
+	asn.TagCounter64:   {Name: "uint64", Tag: "asn.TagCounter64", Option: "counter64", Zero: "0", Get: "%s", Set: "v.(uint64)"},
+}
+
+// goTypeOf returns the Go type of the syntax. The OctetString
+// values displayed as text (e.g. DisplayString) are strings.
+func goTypeOf(s *smi.Syntax) (goType, bool) {
+	t, ok := goTypes[s.Tag()]
+	if ok && t.Name == "[]byte" && s.Base != "BITS" && isTextHint(s.DisplayHint) {
+		t.Name, t.Zero, t.Get, t.Set = "string", `""`, "%s", "v.(string)"
+	}
+	return t, ok
+}
+
+// isTextHint tests whether the octet DISPLAY-HINT displays the
+// octets as ASCII (a) or UTF-8 (t) text.
+func isTextHint(hint string) bool {
+	return strings.HasSuffix(hint, "a") || strings.HasSuffix(hint, "t")
+}
+
+// goName converts the MIB name to the exported Go name, e.g.
+// mib-2 to Mib2.
+func goName(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "-") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(part[1:])
+	}
+	return sb.String()
+}
+
+type genOid struct {
+	Name    string
+	MibName string
+	Oid     string
+}
+
+type genConst struct {
+	Name  string
+	Value int64
+	// Bit is set for the bit numbers of BITS.
+	Bit bool
+}
+
+type genScalar struct {
+	Name     string
+	MibName  string
+	Type     goType
+	Writable bool
+}
+
+type genColumn struct {
+	Field string
+	Type  string
+	Tag   string
+}
+
+type genTable struct {
+	Name    string
+	MibName string
+	Row     string
+	Columns []genColumn
+	// Unsupported is the reason why the table is not served by
+	// Register.
+	Unsupported string
+}
+
+type genData struct {
+	Module  string
+	Package string
+	Oids    []genOid
+	Consts  []genConst
+	Scalars []genScalar
+	Tables  []genTable
+	// Imports are the standard and the module packages.
+	Imports [2][]string
+	// NeedIP tells that the conversions of IpAddress values are
+	// used.
+	NeedIP bool
+}
+
+// Served returns the tables served by Register.
+func (d *genData) Served() (tables []genTable) {
+	for _, t := range d.Tables {
+		if t.Unsupported == "" {
+			tables = append(tables, t)
+		}
+	}
+	return
+}
+
+func oidLiteral(id []uint32) string {
+	s := make([]string, len(id))
+	for i, subid := range id {
+		s[i] = fmt.Sprint(subid)
+	}
+	return strings.Join(s, ", ")
+}
+
+// isAccessible tests whether the object instances can be read.
+func isAccessible(obj *smi.Object) bool {
+	return obj.Access != "not-accessible" && obj.Access != "accessible-for-notify"
+}
+
+func isWritable(obj *smi.Object) bool {
+	return obj.Access == "read-write" || obj.Access == "read-create" || obj.Access == "write-only"
+}
+
+// newGenData collects the declarations of the module of the
+// resolved MIB.
+func newGenData(m *smi.MIB, module, pkg string) (*genData, error) {
+	mod := m.Module(module)
+	if mod == nil {
+		return nil, fmt.Errorf("module %s is not loaded", module)
+	}
+	d := &genData{Module: module, Package: pkg}
+	names := make(map[string]string)
+	declare := func(name, mibName string) error {
+		if reserved[name] {
+			return fmt.Errorf("%s: name %s is reserved", mibName, name)
+		}
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%s: name %s is used by %s", mibName, name, other)
+		}
+		names[name] = mibName
+		return nil
+	}
+
+	for _, obj := range mod.Objects {
+		name := goName(obj.Name)
+		if err := declare(name, obj.Name); err != nil {
+			return nil, err
+		}
+		d.Oids = append(d.Oids, genOid{Name: name, MibName: obj.Name, Oid: oidLiteral(obj.Oid)})
+		if obj.Kind != smi.KindObjectType || obj.Syntax == nil || !isAccessible(obj) {
+			continue
+		}
+		for _, e := range obj.Syntax.Enums {
+			c := genConst{Name: name + goName(e.Name), Value: e.Value, Bit: obj.Syntax.Base == "BITS"}
+			if err := declare(c.Name, obj.Name+"."+e.Name); err != nil {
+				return nil, err
+			}
+			d.Consts = append(d.Consts, c)
+		}
+	}
+
+	for _, obj := range mod.Objects {
+		switch {
+		case obj.Kind != smi.KindObjectType:
+		case obj.IsScalar() && isAccessible(obj):
+			typ, ok := goTypeOf(obj.Syntax)
+			if !ok {
+				return nil, fmt.Errorf("%s: unsupported syntax %s", obj.Name, obj.Syntax.Type)
+			}
+			s := genScalar{Name: goName(obj.Name), MibName: obj.Name, Type: typ, Writable: isWritable(obj)}
+			if s.Writable {
+				if err := declare("Set"+s.Name, obj.Name); err != nil {
+					return nil, err
+				}
+			}
+			d.Scalars = append(d.Scalars, s)
+			d.NeedIP = d.NeedIP || typ.Name == "net.IP"
+		case obj.IsRow():
+			t, err := newGenTable(m, obj)
+			if err != nil {
+				return nil, err
+			}
+			if err := declare(t.Row, obj.Name); err != nil {
+				return nil, err
+			}
+			d.Tables = append(d.Tables, t)
+		}
+	}
+
+	imports := map[string]bool{
+		"github.com/alexispb/mygosnmp/mib": true,
+		"github.com/alexispb/mygosnmp/oid": true,
+	}
+	for _, s := range d.Scalars {
+		imports["github.com/alexispb/mygosnmp/asn"] = true
+		if s.Writable {
+			imports["github.com/alexispb/mygosnmp/pduerror"] = true
+		}
+	}
+	for _, t := range d.Tables {
+		for _, col := range t.Columns {
+			if col.Type == "net.IP" {
+				imports["net"] = true
+			}
+		}
+	}
+	if d.NeedIP {
+		imports["net"] = true
+	}
+	for path := range imports {
+		if strings.Contains(path, ".") {
+			d.Imports[1] = append(d.Imports[1], path)
+		} else {
+			d.Imports[0] = append(d.Imports[0], path)
+		}
+	}
+	sort.Strings(d.Imports[0])
+	sort.Strings(d.Imports[1])
+	return d, nil
+}
+
+// newGenTable returns the table of the row. The index columns are
+// the first fields of the row struct in the order of INDEX clause.
+// The table is served if the index objects are the columns of the
+// row, i.e. AUGMENTS and the index objects of other tables are not
+// supported.
+func newGenTable(m *smi.MIB, row *smi.Object) (genTable, error) {
+	table := row.Parent
+	t := genTable{
+		Name:    goName(table.Name),
+		MibName: table.Name,
+		Row:     goName(row.Name) + "Row",
+	}
+	objs, err := m.IndexObjects(row)
+	if err != nil {
+		return t, err
+	}
+	schema, err := m.IndexSchema(row)
+	if err != nil {
+		return t, err
+	}
+	columns := make([]*smi.Object, 0, len(row.Children))
+	options := make(map[*smi.Object]string)
+	for i, obj := range objs {
+		switch {
+		case row.Augments != "":
+			t.Unsupported = "AUGMENTS " + row.Augments
+		case obj.Parent != row:
+			t.Unsupported = "index object " + obj.Name + " is not column"
+		}
+		if t.Unsupported != "" {
+			columns = columns[:0]
+			break
+		}
+		columns = append(columns, obj)
+		options[obj] = ",index"
+		if schema[i].Implied {
+			options[obj] += ",implied"
+		}
+		if schema[i].Size > 0 {
+			options[obj] += fmt.Sprintf(",size=%d", schema[i].Size)
+		}
+	}
+	for _, col := range row.Children {
+		if _, ok := options[col]; !ok {
+			columns = append(columns, col)
+		}
+	}
+	for _, col := range columns {
+		typ, ok := goTypeOf(col.Syntax)
+		if !ok {
+			return t, fmt.Errorf("%s: unsupported syntax %s", col.Name, col.Syntax.Type)
+		}
+		c := genColumn{Field: goName(col.Name), Type: typ.Name}
+		c.Tag = col.Oid.String() + "," + typ.Option + options[col]
+		if !isAccessible(col) {
+			c.Tag += ",noaccess"
+		}
+		t.Columns = append(t.Columns, c)
+	}
+	return t, nil
+}
+
+var genTemplate = template.Must(template.New("gen").Parse(`// Code generated by mibgen from {{.Module}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range index .Imports 0}}
+	"{{.}}"
+{{- end}}
+{{range index .Imports 1}}
+	"{{.}}"
+{{- end}}
+)
+
+// Module is the name of the MIB module.
+const Module = "{{.Module}}"
+
+// The oids of the objects defined by {{.Module}}.
+var (
+{{- range .Oids}}
+	{{.Name}} = oid.OID{ {{- .Oid -}} }
+{{- end}}
+)
+{{- if .Consts}}
+
+// The enumerations of INTEGER objects and the bit numbers of BITS
+// objects.
+const (
+{{- range .Consts}}
+	{{.Name}} {{if not .Bit}}int32 {{end}}= {{.Value}}
+{{- end}}
+)
+{{- end}}
+
+// RegisterNames adds the names of the objects to the registry.
+func RegisterNames(r *oid.Registry) error {
+	names := []struct {
+		name string
+		id   oid.OID
+	}{
+{{- range .Oids}}
+		{"{{.MibName}}", {{.Name}}},
+{{- end}}
+	}
+	for _, n := range names {
+		if err := r.Register(Module, n.name, n.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{- range .Tables}}
+
+// {{.Row}} is the row of {{.MibName}}.
+{{- if .Unsupported}}
+// The table is not served by Register ({{.Unsupported}}).
+{{- end}}
+type {{.Row}} struct {
+{{- range .Columns}}
+	{{.Field}} {{.Type}} ` + "`" + `snmp:"{{.Tag}}"` + "`" + `
+{{- end}}
+}
+{{- end}}
+
+// Handler provides the values of the scalars and the rows of the
+// tables of {{.Module}}. The setters are defined for the writable
+// scalars, the values passed to setters are not checked against
+// the ranges of the MIB.
+type Handler interface {
+{{- range .Scalars}}
+	// {{.Name}} returns the value of {{.MibName}}.0.
+	{{.Name}}() {{.Type.Name}}
+{{- if .Writable}}
+	// Set{{.Name}} assigns the value of {{.MibName}}.0.
+	Set{{.Name}}(v {{.Type.Name}}) pduerror.Error
+{{- end}}
+{{- end}}
+{{- range .Served}}
+	// {{.Name}} returns the rows of {{.MibName}}.
+	{{.Name}}() []{{.Row}}
+{{- end}}
+}
+
+// UnimplementedHandler is to be embedded into Handler
+// implementations. Its getters return zero values and its setters
+// return pduerror.NotWritable.
+type UnimplementedHandler struct{}
+{{range .Scalars}}
+func (UnimplementedHandler) {{.Name}}() {{.Type.Name}} { return {{.Type.Zero}} }
+{{- if .Writable}}
+func (UnimplementedHandler) Set{{.Name}}(v {{.Type.Name}}) pduerror.Error { return pduerror.NotWritable }
+{{- end}}
+{{- end}}
+{{- range .Served}}
+func (UnimplementedHandler) {{.Name}}() []{{.Row}} { return nil }
+{{- end}}
+
+// Register registers the handlers of the scalars and the tables
+// with the mux.
+func Register(mux *mib.Mux, h Handler) error {
+{{- if .Scalars}}
+	scalars := []*mib.Scalar{
+{{- range .Scalars}}
+		{
+			Oid:      {{.Name}},
+			Tag:      {{.Type.Tag}},
+			GetValue: func() interface{} { return {{printf .Type.Get (printf "h.%s()" .Name)}} },
+{{- if .Writable}}
+			SetValue: func(v interface{}) pduerror.Error { return h.Set{{.Name}}({{.Type.Set}}) },
+{{- end}}
+		},
+{{- end}}
+	}
+	for _, s := range scalars {
+		if err := mux.Register(s.Oid, s); err != nil {
+			return err
+		}
+	}
+{{- end}}
+{{- range .Served}}
+	if table, err := mib.NewStructTable(h.{{.Name}}); err != nil {
+		return err
+	} else if err = mux.Register(table.Entry(), table); err != nil {
+		return err
+	}
+{{- end}}
+	return nil
+}
+{{- if .NeedIP}}
+
+func ipAddressValue(ip net.IP) (v [4]byte) {
+	copy(v[:], ip.To4())
+	return
+}
+
+func ipAddress(v [4]byte) net.IP {
+	return net.IPv4(v[0], v[1], v[2], v[3]).To4()
+}
+{{- end}}
+`))
+
+// generate writes the Go source of the module of the resolved MIB.
+func generate(w io.Writer, m *smi.MIB, module, pkg string) error {
+	d, err := newGenData(m, module, pkg)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := genTemplate.Execute(&buf, d); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated source: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/internal"
+	"github.com/alexispb/mygosnmp/smi"
+)
+
+func loadTestMIB(t *testing.T, srcs ...string) *smi.MIB {
+	m := smi.New()
+	if err := m.LoadDir("../../smi/testdata"); err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range srcs {
+		if err := m.Parse("test", strings.NewReader(src)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// TestGenerate checks that the generated code of demo/acmemib is
+// up to date.
+func TestGenerate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := generate(&buf, loadTestMIB(t), "ACME-TEST-MIB", "acmemib"); err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile("../../demo/acmemib/acme_mib.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := internal.StringsLinesDiff(buf.String(), string(golden)); diff != "" {
+		t.Errorf("TestGenerate: run go generate in demo/acmemib:\n%s", diff)
+	}
+}
+
+var testDataGoName = []struct {
+	name   string
+	goName string
+}{
+	{name: "ifIndex", goName: "IfIndex"},
+	{name: "mib-2", goName: "Mib2"},
+	{name: "SNMPv2-MIB", goName: "SNMPv2MIB"},
+	{name: "snmp-usm-mib", goName: "SnmpUsmMib"},
+}
+
+func TestGoName(t *testing.T) {
+	for i, test := range testDataGoName {
+		if s := goName(test.name); s != test.goName {
+			t.Errorf("TestGoName[%d]: %s", i, s)
+		}
+	}
+}
+
+var testDataGenerateError = []struct {
+	src string
+	err string
+}{
+	{
+		src: "M DEFINITIONS ::= BEGIN\nIMPORTS enterprises FROM SNMPv2-SMI;\n" +
+			"register OBJECT IDENTIFIER ::= { enterprises 1 }\nEND",
+		err: "name Register is reserved",
+	},
+	{
+		src: "M DEFINITIONS ::= BEGIN\nIMPORTS enterprises FROM SNMPv2-SMI;\n" +
+			"x-y OBJECT IDENTIFIER ::= { enterprises 1 }\nxY OBJECT IDENTIFIER ::= { enterprises 2 }\nEND",
+		err: "name XY is used by x-y",
+	},
+	{
+		src: "M DEFINITIONS ::= BEGIN\nIMPORTS OBJECT-TYPE, Integer32, enterprises FROM SNMPv2-SMI;\n" +
+			"x OBJECT-TYPE\n SYNTAX INTEGER { on(1) }\n MAX-ACCESS read-only\n STATUS current\n ::= { enterprises 1 }\n" +
+			"xOn OBJECT IDENTIFIER ::= { enterprises 2 }\nEND",
+		err: "name XOn is used by",
+	},
+}
+
+func TestGenerateError(t *testing.T) {
+	for i, test := range testDataGenerateError {
+		err := generate(&bytes.Buffer{}, loadTestMIB(t, test.src), "M", "m")
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("TestGenerateError[%d]: error %v", i, err)
+		}
+	}
+	if err := generate(&bytes.Buffer{}, loadTestMIB(t), "NO-MIB", "m"); err == nil {
+		t.Errorf("TestGenerateError: no error for unknown module")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Mibgen generates the Go code of the MIB module for the agents and
+subagents which implement it:
+
+	mibgen [-I dir]... [-p package] [-o file] MODULE
+
+The modules are loaded from the -I directories (see smi.MIB.LoadDir)
+and resolved. The generated code declares:
+
+  - the oid of every object of the module (e.g. IfDescr for ifDescr),
+    and RegisterNames which adds the names to oid.Registry;
+  - the constants of the enumerations and the BITS bit numbers;
+  - the row struct of every table (e.g. IfEntryRow) with the
+    mib.Binding struct tags;
+  - the Handler interface with the getters and setters of scalars and
+    the row getters of tables, UnimplementedHandler with the default
+    methods, and Register which registers the Handler with mib.Mux.
+
+The generator is to be run with go:generate, e.g.
+
+	//go:generate go run github.com/alexispb/mygosnmp/cmd/mibgen -I mibs -p ifmib -o if_mib.go IF-MIB
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alexispb/mygosnmp/smi"
+)
+
+// dirList is the value of the repeated -I flag.
+type dirList []string
+
+func (d *dirList) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *dirList) Set(dir string) error {
+	*d = append(*d, dir)
+	return nil
+}
+
+func main() {
+	var dirs dirList
+	flag.Var(&dirs, "I", "load the MIB modules from `dir` (repeated)")
+	pkg := flag.String("p", "", "the `package` name (default is the lowercased module name)")
+	out := flag.String("o", "", "write the code to `file` (default is stdout)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: mibgen [-I dir]... [-p package] [-o file] MODULE\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(dirs, flag.Arg(0), *pkg, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "mibgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dirs []string, module, pkg, out string) error {
+	m := smi.New()
+	for _, dir := range dirs {
+		if err := m.LoadDir(dir); err != nil {
+			return err
+		}
+	}
+	if err := m.Resolve(); err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = strings.ToLower(goName(module))
+	}
+	var buf bytes.Buffer
+	if err := generate(&buf, m, module, pkg); err != nil {
+		return err
+	}
+	if out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,248 @@
+// Code generated by mibgen from ACME-TEST-MIB. DO NOT EDIT.
+
+package acmemib
+
+import (
+	"net"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/mib"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+// Module is the name of the MIB module.
+const Module = "ACME-TEST-MIB"
+
+// The oids of the objects defined by ACME-TEST-MIB.
+var (
+	AcmeTestMIB       = oid.OID{1, 3, 6, 1, 4, 1, 99999}
+	AcmeObjects       = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1}
+	AcmeNotifications = oid.OID{1, 3, 6, 1, 4, 1, 99999, 2}
+	AcmeProducts      = oid.OID{1, 3, 6, 1, 4, 1, 99999, 3}
+	AcmeFeatures      = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 1}
+	AcmeLevel         = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 2}
+	AcmeMask          = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 3}
+	AcmeTemperature   = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 4}
+	AcmeUserTable     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5}
+	AcmeUserEntry     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1}
+	AcmeUserIfIndex   = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1, 1}
+	AcmeUserName      = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1, 2}
+	AcmeUserAddress   = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1, 3}
+	AcmeUserMac       = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1, 4}
+	AcmeUserAdmin     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1, 5}
+	AcmeUserCreated   = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1, 6}
+	AcmeUserStatus    = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 5, 1, 7}
+	AcmeProduct       = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 6}
+	AcmeGateway       = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 7}
+	AcmeRunTime       = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 8}
+	AcmePortTable     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 9}
+	AcmePortEntry     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 9, 1}
+	AcmePortIndex     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 9, 1, 1}
+	AcmePortName      = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 9, 1, 2}
+	AcmePortMac       = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 9, 1, 3}
+	AcmePortInOctets  = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 9, 1, 4}
+	AcmePortState     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 1, 9, 1, 5}
+	AcmeLevelHigh     = oid.OID{1, 3, 6, 1, 4, 1, 99999, 2, 0, 1}
+)
+
+// The enumerations of INTEGER objects and the bit numbers of BITS
+// objects.
+const (
+	AcmeFeaturesAlpha                 = 0
+	AcmeFeaturesBeta                  = 1
+	AcmeFeaturesGamma                 = 7
+	AcmeUserAdminTrue           int32 = 1
+	AcmeUserAdminFalse          int32 = 2
+	AcmeUserStatusActive        int32 = 1
+	AcmeUserStatusNotInService  int32 = 2
+	AcmeUserStatusNotReady      int32 = 3
+	AcmeUserStatusCreateAndGo   int32 = 4
+	AcmeUserStatusCreateAndWait int32 = 5
+	AcmeUserStatusDestroy       int32 = 6
+	AcmePortStateUp             int32 = 1
+	AcmePortStateDown           int32 = 2
+	AcmePortStateUnknown        int32 = 3
+)
+
+// RegisterNames adds the names of the objects to the registry.
+func RegisterNames(r *oid.Registry) error {
+	names := []struct {
+		name string
+		id   oid.OID
+	}{
+		{"acmeTestMIB", AcmeTestMIB},
+		{"acmeObjects", AcmeObjects},
+		{"acmeNotifications", AcmeNotifications},
+		{"acmeProducts", AcmeProducts},
+		{"acmeFeatures", AcmeFeatures},
+		{"acmeLevel", AcmeLevel},
+		{"acmeMask", AcmeMask},
+		{"acmeTemperature", AcmeTemperature},
+		{"acmeUserTable", AcmeUserTable},
+		{"acmeUserEntry", AcmeUserEntry},
+		{"acmeUserIfIndex", AcmeUserIfIndex},
+		{"acmeUserName", AcmeUserName},
+		{"acmeUserAddress", AcmeUserAddress},
+		{"acmeUserMac", AcmeUserMac},
+		{"acmeUserAdmin", AcmeUserAdmin},
+		{"acmeUserCreated", AcmeUserCreated},
+		{"acmeUserStatus", AcmeUserStatus},
+		{"acmeProduct", AcmeProduct},
+		{"acmeGateway", AcmeGateway},
+		{"acmeRunTime", AcmeRunTime},
+		{"acmePortTable", AcmePortTable},
+		{"acmePortEntry", AcmePortEntry},
+		{"acmePortIndex", AcmePortIndex},
+		{"acmePortName", AcmePortName},
+		{"acmePortMac", AcmePortMac},
+		{"acmePortInOctets", AcmePortInOctets},
+		{"acmePortState", AcmePortState},
+		{"acmeLevelHigh", AcmeLevelHigh},
+	}
+	for _, n := range names {
+		if err := r.Register(Module, n.name, n.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AcmeUserEntryRow is the row of acmeUserTable.
+type AcmeUserEntryRow struct {
+	AcmeUserIfIndex int32  `snmp:"1.3.6.1.4.1.99999.1.5.1.1,integer32,index,noaccess"`
+	AcmeUserName    string `snmp:"1.3.6.1.4.1.99999.1.5.1.2,octetstring,index,implied,noaccess"`
+	AcmeUserAddress net.IP `snmp:"1.3.6.1.4.1.99999.1.5.1.3,ipaddress"`
+	AcmeUserMac     []byte `snmp:"1.3.6.1.4.1.99999.1.5.1.4,octetstring"`
+	AcmeUserAdmin   int32  `snmp:"1.3.6.1.4.1.99999.1.5.1.5,integer32"`
+	AcmeUserCreated []byte `snmp:"1.3.6.1.4.1.99999.1.5.1.6,octetstring"`
+	AcmeUserStatus  int32  `snmp:"1.3.6.1.4.1.99999.1.5.1.7,integer32"`
+}
+
+// AcmePortEntryRow is the row of acmePortTable.
+type AcmePortEntryRow struct {
+	AcmePortIndex    int32  `snmp:"1.3.6.1.4.1.99999.1.9.1.1,integer32,index"`
+	AcmePortName     string `snmp:"1.3.6.1.4.1.99999.1.9.1.2,octetstring"`
+	AcmePortMac      []byte `snmp:"1.3.6.1.4.1.99999.1.9.1.3,octetstring"`
+	AcmePortInOctets uint64 `snmp:"1.3.6.1.4.1.99999.1.9.1.4,counter64"`
+	AcmePortState    int32  `snmp:"1.3.6.1.4.1.99999.1.9.1.5,integer32"`
+}
+
+// Handler provides the values of the scalars and the rows of the
+// tables of ACME-TEST-MIB. The setters are defined for the writable
+// scalars, the values passed to setters are not checked against
+// the ranges of the MIB.
+type Handler interface {
+	// AcmeFeatures returns the value of acmeFeatures.0.
+	AcmeFeatures() []byte
+	// SetAcmeFeatures assigns the value of acmeFeatures.0.
+	SetAcmeFeatures(v []byte) pduerror.Error
+	// AcmeLevel returns the value of acmeLevel.0.
+	AcmeLevel() uint32
+	// AcmeMask returns the value of acmeMask.0.
+	AcmeMask() uint32
+	// SetAcmeMask assigns the value of acmeMask.0.
+	SetAcmeMask(v uint32) pduerror.Error
+	// AcmeTemperature returns the value of acmeTemperature.0.
+	AcmeTemperature() int32
+	// AcmeProduct returns the value of acmeProduct.0.
+	AcmeProduct() oid.OID
+	// AcmeGateway returns the value of acmeGateway.0.
+	AcmeGateway() net.IP
+	// SetAcmeGateway assigns the value of acmeGateway.0.
+	SetAcmeGateway(v net.IP) pduerror.Error
+	// AcmeRunTime returns the value of acmeRunTime.0.
+	AcmeRunTime() uint32
+	// AcmeUserTable returns the rows of acmeUserTable.
+	AcmeUserTable() []AcmeUserEntryRow
+	// AcmePortTable returns the rows of acmePortTable.
+	AcmePortTable() []AcmePortEntryRow
+}
+
+// UnimplementedHandler is to be embedded into Handler
+// implementations. Its getters return zero values and its setters
+// return pduerror.NotWritable.
+type UnimplementedHandler struct{}
+
+func (UnimplementedHandler) AcmeFeatures() []byte                    { return nil }
+func (UnimplementedHandler) SetAcmeFeatures(v []byte) pduerror.Error { return pduerror.NotWritable }
+func (UnimplementedHandler) AcmeLevel() uint32                       { return 0 }
+func (UnimplementedHandler) AcmeMask() uint32                        { return 0 }
+func (UnimplementedHandler) SetAcmeMask(v uint32) pduerror.Error     { return pduerror.NotWritable }
+func (UnimplementedHandler) AcmeTemperature() int32                  { return 0 }
+func (UnimplementedHandler) AcmeProduct() oid.OID                    { return oid.OID{0, 0} }
+func (UnimplementedHandler) AcmeGateway() net.IP                     { return nil }
+func (UnimplementedHandler) SetAcmeGateway(v net.IP) pduerror.Error  { return pduerror.NotWritable }
+func (UnimplementedHandler) AcmeRunTime() uint32                     { return 0 }
+func (UnimplementedHandler) AcmeUserTable() []AcmeUserEntryRow       { return nil }
+func (UnimplementedHandler) AcmePortTable() []AcmePortEntryRow       { return nil }
+
+// Register registers the handlers of the scalars and the tables
+// with the mux.
+func Register(mux *mib.Mux, h Handler) error {
+	scalars := []*mib.Scalar{
+		{
+			Oid:      AcmeFeatures,
+			Tag:      asn.TagOctetString,
+			GetValue: func() interface{} { return string(h.AcmeFeatures()) },
+			SetValue: func(v interface{}) pduerror.Error { return h.SetAcmeFeatures([]byte(v.(string))) },
+		},
+		{
+			Oid:      AcmeLevel,
+			Tag:      asn.TagGauge32,
+			GetValue: func() interface{} { return h.AcmeLevel() },
+		},
+		{
+			Oid:      AcmeMask,
+			Tag:      asn.TagGauge32,
+			GetValue: func() interface{} { return h.AcmeMask() },
+			SetValue: func(v interface{}) pduerror.Error { return h.SetAcmeMask(v.(uint32)) },
+		},
+		{
+			Oid:      AcmeTemperature,
+			Tag:      asn.TagInteger32,
+			GetValue: func() interface{} { return h.AcmeTemperature() },
+		},
+		{
+			Oid:      AcmeProduct,
+			Tag:      asn.TagObjectId,
+			GetValue: func() interface{} { return h.AcmeProduct() },
+		},
+		{
+			Oid:      AcmeGateway,
+			Tag:      asn.TagIpAddress,
+			GetValue: func() interface{} { return ipAddressValue(h.AcmeGateway()) },
+			SetValue: func(v interface{}) pduerror.Error { return h.SetAcmeGateway(ipAddress(v.([4]byte))) },
+		},
+		{
+			Oid:      AcmeRunTime,
+			Tag:      asn.TagTimeTicks,
+			GetValue: func() interface{} { return h.AcmeRunTime() },
+		},
+	}
+	for _, s := range scalars {
+		if err := mux.Register(s.Oid, s); err != nil {
+			return err
+		}
+	}
+	if table, err := mib.NewStructTable(h.AcmeUserTable); err != nil {
+		return err
+	} else if err = mux.Register(table.Entry(), table); err != nil {
+		return err
+	}
+	if table, err := mib.NewStructTable(h.AcmePortTable); err != nil {
+		return err
+	} else if err = mux.Register(table.Entry(), table); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ipAddressValue(ip net.IP) (v [4]byte) {
+	copy(v[:], ip.To4())
+	return
+}
+
+func ipAddress(v [4]byte) net.IP {
+	return net.IPv4(v[0], v[1], v[2], v[3]).To4()
+}
@@ -0,0 +1,19 @@
+/*
+Package acmemib is the code generated by mibgen from ACME-TEST-MIB
+(see smi/testdata). It demonstrates the subagent of the module:
+
+	type handler struct {
+		acmemib.UnimplementedHandler
+		level uint32
+	}
+
+	func (h *handler) AcmeLevel() uint32 { return h.level }
+
+	mux := mib.NewMux()
+	if err := acmemib.Register(mux, &handler{}); err != nil {
+		return err
+	}
+*/
+package acmemib
+
+//go:generate go run ../../cmd/mibgen -I ../../smi/testdata -p acmemib -o acme_mib.go ACME-TEST-MIB
@@ -0,0 +1,141 @@
+package acmemib
+
+import (
+	"net"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/mib"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+type testHandler struct {
+	UnimplementedHandler
+	gateway net.IP
+}
+
+func (h *testHandler) AcmeLevel() uint32    { return 4250 }
+func (h *testHandler) AcmeGateway() net.IP  { return h.gateway }
+func (h *testHandler) AcmeProduct() oid.OID { return oid.Cat(AcmeProducts, 1) }
+
+func (h *testHandler) SetAcmeGateway(v net.IP) pduerror.Error {
+	h.gateway = v
+	return pduerror.NoError
+}
+
+func (h *testHandler) AcmePortTable() []AcmePortEntryRow {
+	return []AcmePortEntryRow{
+		{AcmePortIndex: 2, AcmePortName: "eth1", AcmePortState: AcmePortStateDown},
+		{AcmePortIndex: 1, AcmePortName: "eth0", AcmePortInOctets: 1 << 40, AcmePortState: AcmePortStateUp},
+	}
+}
+
+func (h *testHandler) AcmeUserTable() []AcmeUserEntryRow {
+	return []AcmeUserEntryRow{
+		{AcmeUserIfIndex: 2, AcmeUserName: "bob", AcmeUserAddress: net.IPv4(10, 0, 0, 2), AcmeUserAdmin: AcmeUserAdminFalse},
+		{AcmeUserIfIndex: 1, AcmeUserName: "root", AcmeUserAddress: net.IPv4(10, 0, 0, 1), AcmeUserAdmin: AcmeUserAdminTrue},
+	}
+}
+
+func createTestMux(t *testing.T, h Handler) *mib.Mux {
+	mux := mib.NewMux()
+	if err := Register(mux, h); err != nil {
+		t.Fatal(err)
+	}
+	return mux
+}
+
+var testDataGet = []struct {
+	id    oid.OID
+	tag   asn.Tag
+	value interface{}
+}{
+	{id: oid.Cat(AcmeLevel, 0), tag: asn.TagGauge32, value: uint32(4250)},
+	{id: oid.Cat(AcmeGateway, 0), tag: asn.TagIpAddress, value: [4]byte{10, 0, 0, 1}},
+	{id: oid.Cat(AcmeTemperature, 0), tag: asn.TagInteger32, value: int32(0)},
+	{id: oid.Cat(AcmeFeatures, 0), tag: asn.TagOctetString, value: ""},
+	{id: oid.Cat(AcmePortName, 1), tag: asn.TagOctetString, value: "eth0"},
+	{id: oid.Cat(AcmePortInOctets, 1), tag: asn.TagCounter64, value: uint64(1 << 40)},
+	{id: oid.Cat(AcmePortState, 2), tag: asn.TagInteger32, value: int32(AcmePortStateDown)},
+	{id: oid.Cat(AcmePortState, 3), tag: asn.TagNoSuchInstance},
+	{id: oid.Cat(AcmeUserAdmin, 2, 98, 111, 98), tag: asn.TagInteger32, value: int32(AcmeUserAdminFalse)},
+	{id: oid.Cat(AcmeUserAdmin, 2, 3, 98, 111, 98), tag: asn.TagNoSuchInstance},
+	{id: oid.Cat(AcmeUserName, 2, 98, 111, 98), tag: asn.TagNoSuchObject},
+}
+
+func TestGet(t *testing.T) {
+	mux := createTestMux(t, &testHandler{gateway: net.IPv4(10, 0, 0, 1)})
+	for i, test := range testDataGet {
+		vb := mux.Get(test.id)
+		if vb.Tag != test.tag || test.value != nil && vb.Value != test.value {
+			t.Errorf("TestGet[%d]: %s", i, vb.String())
+		}
+	}
+	vb := mux.Get(oid.Cat(AcmeProduct, 0))
+	if v, ok := asn.ObjectIdValue(vb.Value); !ok || !oid.Eq(v, oid.Cat(AcmeProducts, 1)) {
+		t.Errorf("TestGet: %s", vb.String())
+	}
+}
+
+func TestGetNext(t *testing.T) {
+	mux := createTestMux(t, &testHandler{})
+	var ids []string
+	for id := oid.Cat(AcmePortTable); len(ids) < 5; {
+		vb := mux.GetNext(id, nil, false)
+		ids = append(ids, oid.String(vb.Oid))
+		id = vb.Oid
+	}
+	expected := []string{
+		"1.3.6.1.4.1.99999.1.9.1.1.1",
+		"1.3.6.1.4.1.99999.1.9.1.1.2",
+		"1.3.6.1.4.1.99999.1.9.1.2.1",
+		"1.3.6.1.4.1.99999.1.9.1.2.2",
+		"1.3.6.1.4.1.99999.1.9.1.3.1",
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("TestGetNext[%d]: %s", i, ids[i])
+		}
+	}
+}
+
+// TestGetNextIndex checks that the not-accessible index columns of
+// acmeUserTable are skipped.
+func TestGetNextIndex(t *testing.T) {
+	mux := createTestMux(t, &testHandler{})
+	vb := mux.GetNext(AcmeUserTable, nil, false)
+	if !oid.Eq(vb.Oid, oid.Cat(AcmeUserAddress, 1, 114, 111, 111, 116)) {
+		t.Errorf("TestGetNextIndex: %s", vb.String())
+	}
+}
+
+func TestSet(t *testing.T) {
+	h := &testHandler{}
+	mux := createTestMux(t, h)
+	vb := asn.Varbind{Oid: oid.Cat(AcmeGateway, 0), Tag: asn.TagIpAddress, Value: [4]byte{192, 168, 1, 1}}
+	if err := mux.TestSet(vb); err != pduerror.NoError {
+		t.Fatalf("TestSet: TestSet error %s", err)
+	}
+	if err := mux.CommitSet(vb); err != pduerror.NoError || !h.gateway.Equal(net.IPv4(192, 168, 1, 1)) {
+		t.Errorf("TestSet: CommitSet error %s, gateway %s", err, h.gateway)
+	}
+	vb = asn.Varbind{Oid: oid.Cat(AcmeMask, 0), Tag: asn.TagGauge32, Value: uint32(0xff)}
+	if err := mux.CommitSet(vb); err != pduerror.NotWritable {
+		t.Errorf("TestSet: unimplemented setter error %s", err)
+	}
+	vb = asn.Varbind{Oid: oid.Cat(AcmeLevel, 0), Tag: asn.TagGauge32, Value: uint32(1)}
+	if err := mux.TestSet(vb); err == pduerror.NoError {
+		t.Errorf("TestSet: read-only scalar is set")
+	}
+}
+
+func TestRegisterNames(t *testing.T) {
+	r := oid.NewRegistry()
+	if err := RegisterNames(r); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Describe(oid.Cat(AcmePortState, 7)); s != "acmePortState.7" {
+		t.Errorf("TestRegisterNames: %s", s)
+	}
+}
@@ -0,0 +1,90 @@
+package hex
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alexispb/mygosnmp/generics"
+)
+
+// Span labels the byte range data[Start:End].
+type Span struct {
+	Start int
+	End   int
+	Label string
+}
+
+// bytesColumnWidth = len("00 00 00 00")
+const bytesColumnWidth = 11
+
+// DumpAnnotated returns a hex dump of data where each byte range
+// defined by spans is printed starting from a new line and is
+// followed by the span label. Ranges longer than 4 bytes occupy
+// several lines, and the label is printed on the first one. Bytes
+// which are not covered by spans are dumped without label. Spans
+// are clipped to data and to each other (if overlapped).
+// Example:
+//
+//	data := []byte{0, 0, 0, 3, 0x63, 0x74, 0x78, 0}
+//	fmt.Println(DumpAnnotated(data, []Span{
+//		{Start: 0, End: 4, Label: "Context.len"},
+//		{Start: 4, End: 7, Label: "Context.data"},
+//		{Start: 7, End: 8, Label: "Context.pad"}}))
+//
+// results with
+//
+//	00000000  00 00 00 03  Context.len
+//	00000004  63 74 78     Context.data
+//	00000007  00           Context.pad
+func DumpAnnotated(data []byte, spans []Span) string {
+	sorted := make([]Span, len(spans))
+	copy(sorted, spans)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	var sb strings.Builder
+	// 36 = len("00000000  00 00 00 00  ") + average label length
+	sb.Grow((len(data)/4 + len(spans)) * 36)
+
+	pos := 0
+	for _, s := range sorted {
+		if s.Start < pos {
+			s.Start = pos
+		}
+		if s.End > len(data) {
+			s.End = len(data)
+		}
+		if s.Start >= s.End {
+			continue
+		}
+		writeAnnotatedRange(&sb, data, pos, s.Start, "")
+		writeAnnotatedRange(&sb, data, s.Start, s.End, s.Label)
+		pos = s.End
+	}
+	writeAnnotatedRange(&sb, data, pos, len(data), "")
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// writeAnnotatedRange writes data[i1:i2] by 4 bytes per line.
+// The label is written after the first line.
+func writeAnnotatedRange(sb *strings.Builder, data []byte, i1, i2 int, label string) {
+	var buf [10 + bytesColumnWidth + 1]byte
+	for i := i1; i < i2; i += 4 {
+		n := 10
+		encodeOffset(buf[:n], i)
+		n += encodeData(buf[n:], data[i:generics.Min(i+4, i2)])
+		line := buf[:n-1] // without trailing space
+		sb.Write(line)
+		if len(label) > 0 {
+			for w := len(line); w < 10+bytesColumnWidth; w++ {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString("  ")
+			sb.WriteString(label)
+			label = ""
+		}
+		sb.WriteByte('\n')
+	}
+}
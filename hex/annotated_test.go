@@ -0,0 +1,52 @@
+package hex
+
+import (
+	"testing"
+)
+
+var testDataDumpAnnotated = []struct {
+	data  []byte
+	spans []Span
+	res   string
+}{
+	{data: nil, spans: nil, res: ""},
+	{
+		data: []byte{0, 0, 0, 3, 0x63, 0x74, 0x78, 0},
+		spans: []Span{
+			{Start: 0, End: 4, Label: "Context.len"},
+			{Start: 4, End: 7, Label: "Context.data"},
+			{Start: 7, End: 8, Label: "Context.pad"},
+		},
+		res: "00000000  00 00 00 03  Context.len\n" +
+			"00000004  63 74 78     Context.data\n" +
+			"00000007  00           Context.pad",
+	},
+	{
+		// unsorted, overlapped, out of range spans and not covered bytes
+		data: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		spans: []Span{
+			{Start: 10, End: 20, Label: "tail"},
+			{Start: 2, End: 8, Label: "long"},
+			{Start: 6, End: 9, Label: "overlapped"},
+		},
+		res: "00000000  00 01\n" +
+			"00000002  02 03 04 05  long\n" +
+			"00000006  06 07\n" +
+			"00000008  08           overlapped\n" +
+			"00000009  09\n" +
+			"0000000A  0A 0B 0C     tail",
+	},
+}
+
+func TestDumpAnnotated(t *testing.T) {
+	for i, test := range testDataDumpAnnotated {
+		res := DumpAnnotated(test.data, test.spans)
+		if res != test.res {
+			t.Errorf("TestDumpAnnotated[%d]:\n%s", i, res)
+		}
+		data, err := Parse(res)
+		if err != nil || len(data) != len(test.data) {
+			t.Errorf("TestDumpAnnotated[%d]: failed to parse dump: %v", i, err)
+		}
+	}
+}
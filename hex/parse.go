@@ -0,0 +1,112 @@
+package hex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse returns the bytes of a hex dump. It accepts the layout
+// produced by Dump, DumpSub and DumpTail as well as wireshark
+// (tshark -x) hex dumps:
+//
+//	00000004  04 05 06 07
+//	00000008  08 09 0A 0B  annotation
+//	0000   45 00 00 3c 1c 46 40 00  40 06 b1 e6 ac 10 00 01   E..<.F@.@.......
+//
+// Each line may start with an offset (a hex number of 4 or more
+// digits, optionally followed by a colon) which is ignored. The
+// offset is followed by bytes (pairs of hex digits) separated by
+// single spaces. The bytes column ends with a non-hex token or
+// with a gap of two or more spaces (except for the wireshark gap
+// after 8 bytes), so trailing annotations and ascii columns are
+// ignored. Lines which contain no bytes are skipped, as well as
+// lines which start with neither an offset nor a byte (e.g. "add
+// 1" is a log line, not a byte column).
+func Parse(s string) (data []byte, err error) {
+	for n, line := range strings.Split(s, "\n") {
+		if data, err = parseLine(data, line); err != nil {
+			return nil, fmt.Errorf("hex: line %d: %w", n+1, err)
+		}
+	}
+	return
+}
+
+// MustParse is like Parse but panics if the dump can not be
+// parsed. It simplifies initialization of test fixtures.
+func MustParse(s string) []byte {
+	data, err := Parse(s)
+	if err != nil {
+		panic(err.Error())
+	}
+	return data
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func hexDigitValue(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	default:
+		return c - 'a' + 10
+	}
+}
+
+// nextToken returns the token starting at (or after) pos,
+// the position following the token and the number of spaces
+// preceding the token.
+func nextToken(line string, pos int) (token string, next, gap int) {
+	for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+		pos++
+		gap++
+	}
+	next = pos
+	for next < len(line) && line[next] != ' ' && line[next] != '\t' {
+		next++
+	}
+	return line[pos:next], next, gap
+}
+
+func isHexToken(token string) bool {
+	for i := 0; i < len(token); i++ {
+		if !isHexDigit(token[i]) {
+			return false
+		}
+	}
+	return len(token) > 0
+}
+
+func parseLine(data []byte, line string) ([]byte, error) {
+	line = strings.TrimRight(line, " \t\r")
+	token, pos, _ := nextToken(line, 0)
+
+	// skip offset
+	if t := strings.TrimSuffix(token, ":"); len(t) >= 4 && isHexToken(t) {
+		token, pos, _ = nextToken(line, pos)
+	} else if len(token) != 2 || !isHexToken(token) {
+		// annotation line (e.g. log message), the line without
+		// offset must start with a byte
+		return data, nil
+	}
+
+	for count := 0; len(token) > 0; count++ {
+		if !isHexToken(token) {
+			break
+		}
+		if len(token) != 2 {
+			return nil, fmt.Errorf("invalid byte %q", token)
+		}
+		data = append(data, hexDigitValue(token[0])<<4|hexDigitValue(token[1]))
+
+		var gap int
+		token, pos, gap = nextToken(line, pos)
+		if gap > 2 || gap == 2 && count != 7 {
+			break
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,101 @@
+package hex
+
+import (
+	"bytes"
+	"testing"
+)
+
+var testDataParse = []struct {
+	dump string
+	res  []byte
+}{
+	{dump: "", res: nil},
+	{dump: "00000004", res: nil},
+	{
+		dump: "00000000  00 01 02 03\n00000004  04 05",
+		res:  []byte{0, 1, 2, 3, 4, 5},
+	},
+	{
+		// prefixed dump with log lines
+		dump: "parsing int32 data:\n" +
+			"    00000010  1F 2F 3F 4F \n" +
+			"parsed int32: 523190095",
+		res: []byte{0x1F, 0x2F, 0x3F, 0x4F},
+	},
+	{
+		// annotations
+		dump: "00000014  00 00 00 05  Context.len\n" +
+			"00000018  63 74 78  Context.data\n" +
+			"0000001B  00  Context.pad",
+		res: []byte{0, 0, 0, 5, 0x63, 0x74, 0x78, 0},
+	},
+	{
+		// wireshark
+		dump: "0000  45 00 00 3c 1c 46 40 00  40 06 b1 e6 ac 10 00 01   E..<.F@.@.......\n" +
+			"0010  ac 10 00 0c                                       ....",
+		res: []byte{
+			0x45, 0x00, 0x00, 0x3c, 0x1c, 0x46, 0x40, 0x00,
+			0x40, 0x06, 0xb1, 0xe6, 0xac, 0x10, 0x00, 0x01,
+			0xac, 0x10, 0x00, 0x0c,
+		},
+	},
+	{
+		// wireshark ascii column looking like bytes
+		dump: "0000   61 62 63 64   ab cd",
+		res:  []byte{0x61, 0x62, 0x63, 0x64},
+	},
+	{
+		// offsets with colon and no offsets
+		dump: "0000: de ad\nbe ef",
+		res:  []byte{0xDE, 0xAD, 0xBE, 0xEF},
+	},
+	{
+		// log lines starting with hex words
+		dump: "add 01 02\nbad fed\n0000  0a 0b",
+		res:  []byte{0x0A, 0x0B},
+	},
+}
+
+func TestParse(t *testing.T) {
+	for i, test := range testDataParse {
+		res, err := Parse(test.dump)
+		if err != nil {
+			t.Errorf("TestParse[%d]: %v", i, err)
+		} else if !bytes.Equal(res, test.res) {
+			t.Errorf("TestParse[%d]:\n%s", i, DumpDiff(test.res, res))
+		}
+	}
+}
+
+func TestParseDump(t *testing.T) {
+	data := make([]byte, 37)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, dump := range []string{
+		Dump("", data),
+		Dump("    ", data),
+		DumpSub("", data, 0, len(data)),
+	} {
+		res, err := Parse(dump)
+		if err != nil {
+			t.Errorf("TestParseDump: %v", err)
+		} else if !bytes.Equal(res, data) {
+			t.Errorf("TestParseDump:\n%s", DumpDiff(data, res))
+		}
+	}
+}
+
+var testDataParseError = []string{
+	"00000000  00 1 02",
+	"00000000  00 010 02",
+	"00 01\n0000 123",
+}
+
+func TestParseError(t *testing.T) {
+	for i, dump := range testDataParseError {
+		if _, err := Parse(dump); err == nil {
+			t.Errorf("TestParseError[%d]: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package mib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/oid/index"
+)
+
+var (
+	// ErrBinding is returned for the struct types which can not
+	// be bound to a table.
+	ErrBinding = errors.New("mib: invalid struct binding")
+	// ErrConversion is returned if the value can not be converted
+	// to the field type (or vice versa).
+	ErrConversion = errors.New("mib: invalid value conversion")
+)
+
+// tagNames are the asn types of struct tag options.
+var tagNames = map[string]asn.Tag{
+	"integer32":   asn.TagInteger32,
+	"octetstring": asn.TagOctetString,
+	"objectid":    asn.TagObjectId,
+	"ipaddress":   asn.TagIpAddress,
+	"counter32":   asn.TagCounter32,
+	"gauge32":     asn.TagGauge32,
+	"timeticks":   asn.TagTimeTicks,
+	"opaque":      asn.TagOpaque,
+	"counter64":   asn.TagCounter64,
+}
+
+var (
+	typeDuration = reflect.TypeOf(time.Duration(0))
+	typeIP       = reflect.TypeOf(net.IP(nil))
+	typeOid      = reflect.TypeOf([]uint32(nil))
+	typeOID      = reflect.TypeOf(oid.OID(nil))
+	typeBytes    = reflect.TypeOf([]byte(nil))
+)
+
+// Column is the struct field bound to the table column. NoAccess
+// columns are the index objects which are not-accessible.
+type Column struct {
+	Oid      []uint32
+	Tag      asn.Tag
+	Name     string
+	NoAccess bool
+	field    int
+}
+
+// Binding binds the fields of the struct type to the columns of
+// a table with struct tags:
+//
+//	type ifEntry struct {
+//		Index    int32  `snmp:"ifIndex,index"`
+//		Descr    string `snmp:"ifDescr"`
+//		InOctets uint32 `snmp:"1.3.6.1.2.1.2.2.1.10,counter32"`
+//	}
+//
+// The column is the name (see oid.Resolve) or the oid in dotted notation.
+// The options are the asn type of the column (integer32, octetstring,
+// objectid, ipaddress, counter32, gauge32, timeticks, opaque, or
+// counter64), "noaccess" for the not-accessible column, and "index"
+// for the fields which are the row index. The index fields are in
+// the order of the INDEX clause, they are encoded as described in
+// package index with the options "implied" for the IMPLIED last field,
+// and "size=N" for the fixed-size OctetString:
+//
+//	type userEntry struct {
+//		IfIndex int32  `snmp:"1.3.6.1.4.1.999.1.1,index,noaccess"`
+//		Name    string `snmp:"1.3.6.1.4.1.999.1.2,index,implied,noaccess"`
+//		Admin   int32  `snmp:"1.3.6.1.4.1.999.1.3"`
+//	}
+//
+// By default the asn type is derived
+// from the field type: int types are Integer32, uint types are
+// Gauge32 (uint64 is Counter64), string and []byte are OctetString,
+// []uint32 and oid.OID are ObjectId, net.IP is IpAddress, and
+// time.Duration is TimeTicks.
+type Binding struct {
+	Type  reflect.Type
+	Entry []uint32
+	// Columns are sorted by oid.
+	Columns []Column
+	// Schema is the encoding of the index fields.
+	Schema index.Schema
+	// indexes are the columns of the index fields.
+	indexes []int
+}
+
+// NewBinding returns the binding of the struct type (or pointer
+// to struct type).
+func NewBinding(typ reflect.Type) (*Binding, error) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not struct", ErrBinding, typ)
+	}
+	b := &Binding{Type: typ}
+	var err error
+	var indexFields []int
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag, ok := f.Tag.Lookup("snmp")
+		if !ok || tag == "-" {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		id, ok := resolve(options[0])
+		if !ok || len(id) < 2 {
+			return nil, fmt.Errorf("%w: %s: unknown column %q", ErrBinding, f.Name, options[0])
+		}
+		col := Column{Oid: id, Name: f.Name, field: i, Tag: defaultTag(f.Type)}
+		isIndex := false
+		var field index.Field
+		for _, option := range options[1:] {
+			switch tag, ok := tagNames[option]; {
+			case ok:
+				col.Tag = tag
+			case option == "index":
+				isIndex = true
+			case option == "noaccess":
+				col.NoAccess = true
+			case option == "implied":
+				field.Implied = true
+			case strings.HasPrefix(option, "size="):
+				if field.Size, err = strconv.Atoi(option[len("size="):]); err != nil || field.Size <= 0 {
+					return nil, fmt.Errorf("%w: %s: invalid option %q", ErrBinding, f.Name, option)
+				}
+			default:
+				return nil, fmt.Errorf("%w: %s: unknown option %q", ErrBinding, f.Name, option)
+			}
+		}
+		if col.Tag == 0 {
+			return nil, fmt.Errorf("%w: %s: unsupported type %s", ErrBinding, f.Name, f.Type)
+		}
+		if field != (index.Field{}) && !isIndex {
+			return nil, fmt.Errorf("%w: %s: index option of non-index field", ErrBinding, f.Name)
+		}
+		if isIndex {
+			field.Kind = indexKind(col.Tag)
+			b.Schema = append(b.Schema, field)
+			indexFields = append(indexFields, i)
+		}
+		b.Columns = append(b.Columns, col)
+	}
+	if err := b.Schema.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrBinding, typ, err)
+	}
+	if len(b.Columns) == 0 {
+		return nil, fmt.Errorf("%w: %s has no columns", ErrBinding, typ)
+	}
+
+	sort.Slice(b.Columns, func(i, j int) bool {
+		return oid.Lt(b.Columns[i].Oid, b.Columns[j].Oid)
+	})
+	first := b.Columns[0].Oid
+	b.Entry = oid.Clone(first[:len(first)-1])
+	for i, col := range b.Columns {
+		if len(col.Oid) != len(first) || !oid.HasPrefix(col.Oid, b.Entry...) ||
+			i > 0 && oid.Eq(col.Oid, b.Columns[i-1].Oid) {
+			return nil, fmt.Errorf("%w: %s is not column of %s", ErrBinding, col.Name, oid.String(b.Entry))
+		}
+	}
+	for _, field := range indexFields {
+		for i, col := range b.Columns {
+			if col.field == field {
+				b.indexes = append(b.indexes, i)
+			}
+		}
+	}
+	return b, nil
+}
+
+// indexKind returns the index encoding of the asn type.
+func indexKind(tag asn.Tag) index.Kind {
+	switch tag {
+	case asn.TagOctetString, asn.TagOpaque:
+		return index.OctetString
+	case asn.TagIpAddress:
+		return index.IpAddress
+	case asn.TagObjectId:
+		return index.ObjectId
+	}
+	return index.Integer
+}
+
+// resolve returns the oid of the mib name or dotted oid.
+func resolve(s string) ([]uint32, bool) {
+	if id, ok := oid.Name[s]; ok {
+		return id, true
+	}
+	id, err := oid.Resolve(s)
+	return id, err == nil && len(id) > 0
+}
+
+func isOid(typ reflect.Type) bool {
+	return typ == typeOid || typ == typeOID
+}
+
+// defaultTag returns the asn type of the field type, or 0.
+func defaultTag(typ reflect.Type) asn.Tag {
+	switch typ {
+	case typeDuration:
+		return asn.TagTimeTicks
+	case typeIP:
+		return asn.TagIpAddress
+	case typeOid, typeOID:
+		return asn.TagObjectId
+	case typeBytes:
+		return asn.TagOctetString
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return asn.TagOctetString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return asn.TagInteger32
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return asn.TagGauge32
+	case reflect.Uint64:
+		return asn.TagCounter64
+	}
+	return 0
+}
+
+// Column returns the column of the instance id, and the index.
+func (b *Binding) Column(id []uint32) (column int, index []uint32, ok bool) {
+	if len(id) <= len(b.Entry) || !oid.HasPrefix(id, b.Entry...) {
+		return 0, nil, false
+	}
+	subid := id[len(b.Entry)]
+	for i, col := range b.Columns {
+		if col.Oid[len(b.Entry)] == subid {
+			return i, id[len(b.Entry)+1:], true
+		}
+	}
+	return 0, nil, false
+}
+
+// Index returns the row index of the struct value v. It returns
+// nil if there are no index fields.
+func (b *Binding) Index(v reflect.Value) ([]uint32, error) {
+	if len(b.indexes) == 0 {
+		return nil, nil
+	}
+	values := make([]interface{}, len(b.indexes))
+	for i, column := range b.indexes {
+		vb, err := b.Varbind(v, column, nil)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = vb.Value
+	}
+	index, err := b.Schema.Encode(values...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConversion, err)
+	}
+	return index, nil
+}
+
+// setIndex assigns the index to the index fields of v.
+func (b *Binding) setIndex(v reflect.Value, index []uint32) error {
+	values, err := b.Schema.Decode(index)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrConversion, err)
+	}
+	for i, column := range b.indexes {
+		col := &b.Columns[column]
+		vb := asn.Varbind{Tag: col.Tag, Value: values[i]}
+		// the integer index is decoded as uint32 whatever the tag
+		if n, ok := vb.Value.(uint32); ok && col.Tag == asn.TagInteger32 {
+			if n > math.MaxInt32 {
+				return fmt.Errorf("%w: %s: index %d is not Integer32", ErrConversion, col.Name, n)
+			}
+			vb.Value = int32(n)
+		}
+		if err := b.SetField(v, column, vb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Varbind returns the varbind of the column of the row index
+// with the field value of the struct value v.
+func (b *Binding) Varbind(v reflect.Value, column int, index []uint32) (asn.Varbind, error) {
+	col := &b.Columns[column]
+	vb := asn.Varbind{Oid: oid.Cat(col.Oid, index...), Tag: col.Tag}
+	f := reflect.Indirect(v).Field(col.field)
+	ok := false
+	switch col.Tag {
+	case asn.TagOctetString:
+		switch {
+		case f.Kind() == reflect.String:
+			vb.Value, ok = f.String(), true
+		case f.Type() == typeBytes:
+			vb.Value, ok = string(f.Bytes()), true
+		}
+	case asn.TagOpaque:
+		if f.Type() == typeBytes {
+			vb.Value, ok = append([]byte(nil), f.Bytes()...), true
+		}
+	case asn.TagObjectId:
+		if isOid(f.Type()) {
+			vb.Value, ok = oid.Clone(f.Convert(typeOid).Interface().([]uint32)), true
+		}
+	case asn.TagIpAddress:
+		if f.Type() == typeIP {
+			if ip := f.Interface().(net.IP).To4(); ip != nil {
+				vb.Value, ok = [4]byte{ip[0], ip[1], ip[2], ip[3]}, true
+			}
+		}
+	case asn.TagInteger32:
+		if n, isNum := intValue(f); isNum && n >= math.MinInt32 && n <= math.MaxInt32 {
+			vb.Value, ok = int32(n), true
+		}
+	case asn.TagCounter32, asn.TagGauge32, asn.TagTimeTicks:
+		if col.Tag == asn.TagTimeTicks && f.Type() == typeDuration {
+			vb.Value, ok = uint32(f.Int()/int64(10*time.Millisecond)), true
+		} else if n, isNum := uintValue(f); isNum && n <= math.MaxUint32 {
+			vb.Value, ok = uint32(n), true
+		}
+	case asn.TagCounter64:
+		vb.Value, ok = uintValue(f)
+	}
+	if !ok {
+		return vb, fmt.Errorf("%w: %s: %s value %v", ErrConversion, col.Name, col.Tag, f.Interface())
+	}
+	return vb, nil
+}
+
+// SetField assigns the varbind value to the column field of the
+// struct value v (which is to be addressable).
+func (b *Binding) SetField(v reflect.Value, column int, vb asn.Varbind) error {
+	col := &b.Columns[column]
+	f := reflect.Indirect(v).Field(col.field)
+	ok := false
+	switch value := vb.Value.(type) {
+	case string:
+		switch {
+		case f.Kind() == reflect.String:
+			f.SetString(value)
+			ok = true
+		case f.Type() == typeBytes:
+			f.SetBytes([]byte(value))
+			ok = true
+		}
+	case []byte:
+		if ok = f.Type() == typeBytes; ok {
+			f.SetBytes(append([]byte(nil), value...))
+		}
+	case []uint32, oid.OID:
+		id, _ := asn.ObjectIdValue(value)
+		switch {
+		case isOid(f.Type()):
+			f.Set(reflect.ValueOf(oid.Clone(id)).Convert(f.Type()))
+			ok = true
+		case f.Kind() == reflect.String:
+			f.SetString(oid.String(id))
+			ok = true
+		}
+	case [4]byte:
+		switch {
+		case f.Type() == typeIP:
+			f.Set(reflect.ValueOf(net.IPv4(value[0], value[1], value[2], value[3]).To4()))
+			ok = true
+		case f.Kind() == reflect.String:
+			f.SetString(net.IP(value[:]).String())
+			ok = true
+		}
+	case int32:
+		ok = setInt(f, int64(value))
+	case uint32:
+		if vb.Tag == asn.TagTimeTicks && f.Type() == typeDuration {
+			f.SetInt(int64(value) * int64(10*time.Millisecond))
+			ok = true
+		} else {
+			ok = setInt(f, int64(value))
+		}
+	case uint64:
+		if isUint(f.Kind()) && !f.OverflowUint(value) {
+			f.SetUint(value)
+			ok = true
+		} else if value <= math.MaxInt64 {
+			ok = setInt(f, int64(value))
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s: %s value to %s", ErrConversion, col.Name, vb.Tag, f.Type())
+	}
+	return nil
+}
+
+// Unmarshal assigns the varbinds of the row to the fields of the
+// struct value v (which is to be addressable). The varbinds of
+// other columns are ignored. If the index fields are not assigned
+// by the varbinds, they are decoded from index.
+func (b *Binding) Unmarshal(v reflect.Value, index []uint32, vbs []asn.Varbind) error {
+	v = reflect.Indirect(v)
+	indexSet := 0
+	for _, vb := range vbs {
+		column, vbIndex, ok := b.Column(vb.Oid)
+		if !ok || !oid.Eq(vbIndex, index) {
+			continue
+		}
+		if err := b.SetField(v, column, vb); err != nil {
+			return err
+		}
+		if b.isIndex(column) {
+			indexSet++
+		}
+	}
+	if indexSet < len(b.indexes) {
+		return b.setIndex(v, index)
+	}
+	return nil
+}
+
+func (b *Binding) isIndex(column int) bool {
+	for _, i := range b.indexes {
+		if i == column {
+			return true
+		}
+	}
+	return false
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+func intValue(f reflect.Value) (int64, bool) {
+	switch {
+	case isInt(f.Kind()):
+		return f.Int(), true
+	case isUint(f.Kind()) && f.Uint() <= math.MaxInt64:
+		return int64(f.Uint()), true
+	}
+	return 0, false
+}
+
+func uintValue(f reflect.Value) (uint64, bool) {
+	switch {
+	case isUint(f.Kind()):
+		return f.Uint(), true
+	case isInt(f.Kind()) && f.Int() >= 0:
+		return uint64(f.Int()), true
+	}
+	return 0, false
+}
+
+func setInt(f reflect.Value, n int64) bool {
+	switch {
+	case isInt(f.Kind()) && !f.OverflowInt(n):
+		f.SetInt(n)
+	case isUint(f.Kind()) && n >= 0 && !f.OverflowUint(uint64(n)):
+		f.SetUint(uint64(n))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,294 @@
+package mib
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/internal"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+type testIfEntry struct {
+	Index    int32         `snmp:"ifIndex,index"`
+	Descr    string        `snmp:"ifDescr"`
+	Speed    uint32        `snmp:"ifSpeed"`
+	Phys     []byte        `snmp:"ifPhysAddress"`
+	InOctets uint64        `snmp:"1.3.6.1.2.1.2.2.1.10,counter32"`
+	Change   time.Duration `snmp:"1.3.6.1.2.1.2.2.1.9"`
+	Addr     net.IP        `snmp:"1.3.6.1.2.1.2.2.1.30"`
+	Other    string
+}
+
+// testIpEntry is indexed by not-accessible column.
+type testIpEntry struct {
+	Addr   net.IP `snmp:"1.3.6.1.4.1.999.5.1.1,index"`
+	Name   string `snmp:"1.3.6.1.4.1.999.5.1.2"`
+	Status int    `snmp:"1.3.6.1.4.1.999.5.1.3"`
+}
+
+// testUserEntry is indexed by the integer and the IMPLIED string
+// which are not-accessible, and has the fixed-size string column.
+type testUserEntry struct {
+	IfIndex uint32 `snmp:"1.3.6.1.4.1.999.6.1.1,index,noaccess"`
+	Name    string `snmp:"1.3.6.1.4.1.999.6.1.2,index,implied,noaccess"`
+	Mac     []byte `snmp:"1.3.6.1.4.1.999.6.1.3"`
+}
+
+// testMacEntry is indexed by the fixed-size string.
+type testMacEntry struct {
+	Mac  []byte `snmp:"1.3.6.1.4.1.999.7.1.1,index,size=6"`
+	Port int    `snmp:"1.3.6.1.4.1.999.7.1.2"`
+}
+
+// testPortEntry is indexed by the Integer32 column of int64 field.
+type testPortEntry struct {
+	Port int64  `snmp:"1.3.6.1.4.1.999.8.1.1,index"`
+	Name string `snmp:"1.3.6.1.4.1.999.8.1.2"`
+}
+
+var testDataBindingError = []interface{}{
+	0,
+	struct{ A int }{},
+	struct {
+		A int `snmp:"noSuchName"`
+	}{},
+	struct {
+		A int `snmp:"ifIndex,bad"`
+	}{},
+	struct {
+		A bool `snmp:"ifIndex"`
+	}{},
+	struct {
+		A int `snmp:"ifIndex"`
+		B int `snmp:"sysORIndex"`
+	}{},
+	struct {
+		A int `snmp:"ifIndex,implied"`
+	}{},
+	struct {
+		A string `snmp:"ifDescr,index,size=0"`
+	}{},
+	struct {
+		A string `snmp:"ifDescr,index,implied"`
+		B int    `snmp:"ifType,index"`
+	}{},
+	struct {
+		A int `snmp:"ifIndex,index,implied"`
+	}{},
+}
+
+func TestBindingError(t *testing.T) {
+	for i, v := range testDataBindingError {
+		if _, err := NewBinding(reflect.TypeOf(v)); !errors.Is(err, ErrBinding) {
+			t.Errorf("TestBindingError[%d]: error %v", i, err)
+		}
+	}
+}
+
+func TestBinding(t *testing.T) {
+	b, err := NewBinding(reflect.TypeOf(testIfEntry{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !oid.Eq(b.Entry, oid.Name["ifEntry"]) || len(b.Columns) != 7 {
+		t.Fatalf("TestBinding: entry %s, %d columns", oid.String(b.Entry), len(b.Columns))
+	}
+
+	entry := testIfEntry{
+		Index:    7,
+		Descr:    "eth0",
+		Speed:    1000000000,
+		Phys:     []byte{0, 1, 2, 3, 4, 5},
+		InOctets: 123456,
+		Change:   15 * time.Second,
+		Addr:     net.IPv4(192, 0, 2, 1).To4(),
+	}
+	index, err := b.Index(reflect.ValueOf(entry))
+	if err != nil || !oid.Eq(index, []uint32{7}) {
+		t.Fatalf("TestBinding: index %v, %v", index, err)
+	}
+	var vbs []asn.Varbind
+	for column := range b.Columns {
+		vb, err := b.Varbind(reflect.ValueOf(entry), column, index)
+		if err != nil {
+			t.Fatalf("TestBinding: column %d: %v", column, err)
+		}
+		vbs = append(vbs, vb)
+	}
+	expected := []asn.Varbind{
+		{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.1.7"), Tag: asn.TagInteger32, Value: int32(7)},
+		{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.2.7"), Tag: asn.TagOctetString, Value: "eth0"},
+		{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.5.7"), Tag: asn.TagGauge32, Value: uint32(1000000000)},
+		{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.6.7"), Tag: asn.TagOctetString, Value: "\x00\x01\x02\x03\x04\x05"},
+		{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.9.7"), Tag: asn.TagTimeTicks, Value: uint32(1500)},
+		{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.10.7"), Tag: asn.TagCounter32, Value: uint32(123456)},
+		{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.30.7"), Tag: asn.TagIpAddress, Value: [4]byte{192, 0, 2, 1}},
+	}
+	if diff := internal.StructsDiff(vbs, expected); diff != "" {
+		t.Errorf("TestBinding: varbinds:\n%s", diff)
+	}
+
+	var res testIfEntry
+	if err = b.Unmarshal(reflect.ValueOf(&res), index, vbs); err != nil {
+		t.Fatal(err)
+	}
+	if diff := internal.StructsDiff(res, entry); diff != "" {
+		t.Errorf("TestBinding: unmarshaled:\n%s", diff)
+	}
+
+	// conversion errors
+	entry.InOctets = 1 << 40
+	if _, err = b.Varbind(reflect.ValueOf(entry), 5, index); !errors.Is(err, ErrConversion) {
+		t.Errorf("TestBinding: Counter32 overflow error %v", err)
+	}
+	vbs[0].Value = "7"
+	if err = b.Unmarshal(reflect.ValueOf(&res), index, vbs); !errors.Is(err, ErrConversion) {
+		t.Errorf("TestBinding: string to int32 error %v", err)
+	}
+}
+
+func TestBindingIndex(t *testing.T) {
+	b, err := NewBinding(reflect.TypeOf(testIpEntry{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := []uint32{10, 0, 0, 1}
+	var res testIpEntry
+	vbs := []asn.Varbind{{Oid: oid.MustParse("1.3.6.1.4.1.999.5.1.2.10.0.0.1"), Tag: asn.TagOctetString, Value: "a"}}
+	if err = b.Unmarshal(reflect.ValueOf(&res), index, vbs); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Addr.Equal(net.IPv4(10, 0, 0, 1)) || res.Name != "a" {
+		t.Errorf("TestBindingIndex: %+v", res)
+	}
+	if id, err := b.Index(reflect.ValueOf(res)); err != nil || !oid.Eq(id, index) {
+		t.Errorf("TestBindingIndex: index %v, %v", id, err)
+	}
+}
+
+var testDataBindingSchema = []struct {
+	v     interface{}
+	index string
+}{
+	{v: testUserEntry{IfIndex: 2, Name: "ab"}, index: "2.97.98"},
+	{v: testMacEntry{Mac: []byte{0, 1, 2, 3, 4, 5}}, index: "0.1.2.3.4.5"},
+	{v: testIpEntry{Addr: net.IPv4(10, 0, 0, 1).To4()}, index: "10.0.0.1"},
+	{v: testPortEntry{Port: 2147483647}, index: "2147483647"},
+}
+
+func TestBindingSchema(t *testing.T) {
+	for i, test := range testDataBindingSchema {
+		b, err := NewBinding(reflect.TypeOf(test.v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		index, err := b.Index(reflect.ValueOf(test.v))
+		if err != nil || oid.String(index) != test.index {
+			t.Errorf("TestBindingSchema[%d]: index %v, %v", i, index, err)
+			continue
+		}
+		res := reflect.New(b.Type)
+		if err = b.Unmarshal(res, index, nil); err != nil || !reflect.DeepEqual(res.Elem().Interface(), test.v) {
+			t.Errorf("TestBindingSchema[%d]: %+v, %v", i, res.Elem().Interface(), err)
+		}
+	}
+
+	b, _ := NewBinding(reflect.TypeOf(testMacEntry{}))
+	if _, err := b.Index(reflect.ValueOf(testMacEntry{Mac: []byte{1}})); !errors.Is(err, ErrConversion) {
+		t.Errorf("TestBindingSchema: index error %v", err)
+	}
+	var res testMacEntry
+	if err := b.Unmarshal(reflect.ValueOf(&res), []uint32{1, 2}, nil); !errors.Is(err, ErrConversion) {
+		t.Errorf("TestBindingSchema: unmarshal error %v", err)
+	}
+	b, _ = NewBinding(reflect.TypeOf(testPortEntry{}))
+	var port testPortEntry
+	if err := b.Unmarshal(reflect.ValueOf(&port), []uint32{2147483648}, nil); !errors.Is(err, ErrConversion) {
+		t.Errorf("TestBindingSchema: Integer32 index %d, error %v", port.Port, err)
+	}
+}
+
+func TestStructTableNoAccess(t *testing.T) {
+	table, err := NewStructTable(func() []testUserEntry {
+		return []testUserEntry{
+			{IfIndex: 2, Name: "b", Mac: []byte{2}},
+			{IfIndex: 1, Name: "zz", Mac: []byte{1}},
+			{IfIndex: 1, Name: "a", Mac: []byte{0}},
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vb := table.Get(oid.MustParse("1.3.6.1.4.1.999.6.1.2.1.97")); vb.Tag != asn.TagNoSuchObject {
+		t.Errorf("TestStructTableNoAccess: Get %s", vb.String())
+	}
+	var walk []string
+	for id := table.Entry(); ; {
+		vb := table.GetNext(id, nil, false)
+		if vb.Tag == asn.TagEndOfMibView {
+			break
+		}
+		walk = append(walk, oid.String(vb.Oid))
+		id = vb.Oid
+	}
+	expected := []string{
+		"1.3.6.1.4.1.999.6.1.3.1.97",
+		"1.3.6.1.4.1.999.6.1.3.1.122.122",
+		"1.3.6.1.4.1.999.6.1.3.2.98",
+	}
+	if diff := internal.StringsLinesDiff(strings.Join(walk, "\n"), strings.Join(expected, "\n")); diff != "" {
+		t.Errorf("TestStructTableNoAccess: walk:\n%s", diff)
+	}
+}
+
+func TestStructTable(t *testing.T) {
+	rows := []testIpEntry{
+		{Addr: net.IPv4(10, 0, 0, 2), Name: "b", Status: 2},
+		{Addr: net.IPv4(10, 0, 0, 1), Name: "a", Status: 1},
+	}
+	table, err := NewStructTable(func() []testIpEntry { return rows })
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMux()
+	if err = m.Register(table.Entry(), table); err != nil {
+		t.Fatal(err)
+	}
+
+	if vb := m.Get(oid.MustParse("1.3.6.1.4.1.999.5.1.2.10.0.0.2")); vb.Value != "b" {
+		t.Errorf("TestStructTable: Get %s", vb.String())
+	}
+	if vb := m.Get(oid.MustParse("1.3.6.1.4.1.999.5.1.2.10.0.0.3")); vb.Tag != asn.TagNoSuchInstance {
+		t.Errorf("TestStructTable: Get %s", vb.String())
+	}
+	if vb := m.Get(oid.MustParse("1.3.6.1.4.1.999.5.1.4.10.0.0.1")); vb.Tag != asn.TagNoSuchObject {
+		t.Errorf("TestStructTable: Get %s", vb.String())
+	}
+
+	// the walk returns the columns in index order
+	var walk []string
+	for id := table.Entry(); ; {
+		vb := m.GetNext(id, nil, false)
+		if vb.Tag == asn.TagEndOfMibView {
+			break
+		}
+		walk = append(walk, vb.String())
+		id = vb.Oid
+	}
+	expected := []string{
+		asn.Varbind{Oid: oid.MustParse("1.3.6.1.4.1.999.5.1.1.10.0.0.1"), Tag: asn.TagIpAddress, Value: [4]byte{10, 0, 0, 1}}.String(),
+		asn.Varbind{Oid: oid.MustParse("1.3.6.1.4.1.999.5.1.1.10.0.0.2"), Tag: asn.TagIpAddress, Value: [4]byte{10, 0, 0, 2}}.String(),
+		asn.Varbind{Oid: oid.MustParse("1.3.6.1.4.1.999.5.1.2.10.0.0.1"), Tag: asn.TagOctetString, Value: "a"}.String(),
+		asn.Varbind{Oid: oid.MustParse("1.3.6.1.4.1.999.5.1.2.10.0.0.2"), Tag: asn.TagOctetString, Value: "b"}.String(),
+		asn.Varbind{Oid: oid.MustParse("1.3.6.1.4.1.999.5.1.3.10.0.0.1"), Tag: asn.TagInteger32, Value: int32(1)}.String(),
+		asn.Varbind{Oid: oid.MustParse("1.3.6.1.4.1.999.5.1.3.10.0.0.2"), Tag: asn.TagInteger32, Value: int32(2)}.String(),
+	}
+	if diff := internal.StringsLinesDiff(strings.Join(walk, "\n"), strings.Join(expected, "\n")); diff != "" {
+		t.Errorf("TestStructTable: walk:\n%s", diff)
+	}
+}
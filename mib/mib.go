@@ -0,0 +1,80 @@
+/*
+Package mib defines the interface of MIB implementations which
+is shared by the SNMP agent (snmp.Agent) and Agentx subagents,
+so one MIB implementation can be served either natively or
+through Agentx.
+
+The Handler interface follows the Agentx operations (RFC 2741):
+Get, GetNext within a search range, and the four phases of Set
+(TestSet, CommitSet, UndoSet, and CleanupSet). The Mux is the
+Handler which dispatches requests to the handlers registered
+for subtrees:
+
+	mux := mib.NewMux()
+	mux.Register(sysDescr, &mib.Scalar{
+		Oid:      sysDescr,
+		Tag:      asn.TagOctetString,
+		GetValue: func() interface{} { return "my agent" },
+	})
+	agent := snmp.NewAgent(mux)
+
+The table rows can be bound to struct fields with struct tags (see
+Binding), e.g. the StructTable serves a slice of structs:
+
+	type ifRow struct {
+		Index int32  `snmp:"ifIndex,index"`
+		Descr string `snmp:"ifDescr"`
+	}
+	table, _ := mib.NewStructTable(func() []ifRow { return rows })
+	mux.Register(table.Entry(), table)
+*/
+package mib
+
+import (
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+// Handler implements the MIB objects of a subtree. The object
+// identifiers passed to the handler are full instance identifiers.
+type Handler interface {
+	// Get returns the varbind of the object instance id. If there
+	// is no such object or instance, the varbind tag is
+	// asn.TagNoSuchObject or asn.TagNoSuchInstance.
+	Get(id []uint32) asn.Varbind
+	// GetNext returns the varbind of the first object instance
+	// which follows start (or equals start if include is true)
+	// and precedes end (if end is not empty). If there is no
+	// such instance, the varbind tag is asn.TagEndOfMibView.
+	GetNext(start, end []uint32, include bool) asn.Varbind
+
+	// TestSet checks whether the value can be assigned.
+	TestSet(vb asn.Varbind) pduerror.Error
+	// CommitSet assigns the value which passed TestSet.
+	CommitSet(vb asn.Varbind) pduerror.Error
+	// UndoSet restores the value changed by CommitSet.
+	UndoSet(vb asn.Varbind) pduerror.Error
+	// CleanupSet releases the resources allocated by TestSet
+	// or CommitSet. It is called after all the varbinds of
+	// the request are processed (or TestSet fails).
+	CleanupSet(vb asn.Varbind)
+}
+
+// ReadOnly implements the Set phases of Handler for read-only
+// objects. It is to be embedded into handlers.
+type ReadOnly struct{}
+
+func (ReadOnly) TestSet(vb asn.Varbind) pduerror.Error   { return pduerror.NotWritable }
+func (ReadOnly) CommitSet(vb asn.Varbind) pduerror.Error { return pduerror.CommitFailed }
+func (ReadOnly) UndoSet(vb asn.Varbind) pduerror.Error   { return pduerror.UndoFailed }
+func (ReadOnly) CleanupSet(vb asn.Varbind)               {}
+
+// InRange tests whether the instance id satisfies the search
+// range of GetNext.
+func InRange(id, start, end []uint32, include bool) bool {
+	if cmp := oid.Compare(id, start); cmp < 0 || cmp == 0 && !include {
+		return false
+	}
+	return len(end) == 0 || oid.Lt(id, end)
+}
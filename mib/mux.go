@@ -0,0 +1,148 @@
+package mib
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+var ErrRegistration = errors.New("mib: subtree overlaps registered subtree")
+
+// registration is the value of the oid.Tree node of the
+// registered subtree.
+type registration struct {
+	subtree []uint32
+	handler Handler
+}
+
+// Mux is the Handler which dispatches requests to the handlers
+// registered for non-overlapping subtrees. The handlers are
+// stored in oid.Tree. Mux can be used concurrently.
+type Mux struct {
+	// mu makes the overlap checks and the updates of tree atomic.
+	mu   sync.RWMutex
+	tree *oid.Tree[*registration]
+}
+
+func NewMux() *Mux {
+	return &Mux{tree: oid.NewTree[*registration]()}
+}
+
+// Register registers the handler for the subtree. It returns
+// ErrRegistration if the subtree includes or is included in
+// a registered subtree.
+func (m *Mux) Register(subtree []uint32, h Handler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(subtree) == 0 || m.lookupLocked(subtree) != nil || m.tree.Node(subtree) != nil {
+		// subtree is included in or includes a registered subtree
+		return ErrRegistration
+	}
+	m.tree.Insert(subtree, &registration{subtree: oid.Clone(subtree), handler: h})
+	return nil
+}
+
+// Unregister removes the handler registered for the subtree.
+func (m *Mux) Unregister(subtree []uint32) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r := m.lookupLocked(subtree); r == nil || !oid.Eq(r.subtree, subtree) {
+		return false
+	}
+	m.tree.Delete(subtree)
+	return true
+}
+
+// Lookup returns the handler of the registered subtree which
+// includes id.
+func (m *Mux) Lookup(id []uint32) (subtree []uint32, h Handler) {
+	if r := m.lookup(id); r != nil {
+		return r.subtree, r.handler
+	}
+	return nil, nil
+}
+
+func (m *Mux) lookup(id []uint32) *registration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lookupLocked(id)
+}
+
+func (m *Mux) lookupLocked(id []uint32) *registration {
+	for i := len(id); i > 0; i-- {
+		if r, ok := m.tree.Get(id[:i]); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// next returns the first registered subtree which follows id
+// and is not a prefix of id.
+func (m *Mux) next(id []uint32) *registration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	// the subtrees do not overlap, so the subtree including id
+	// (if any) precedes id and is skipped
+	if _, node := m.tree.GetNext(id, nil, false); node != nil {
+		return node.Value
+	}
+	return nil
+}
+
+func (m *Mux) Get(id []uint32) asn.Varbind {
+	if r := m.lookup(id); r != nil {
+		return r.handler.Get(id)
+	}
+	return asn.Varbind{Oid: id, Tag: asn.TagNoSuchObject}
+}
+
+func (m *Mux) GetNext(start, end []uint32, include bool) asn.Varbind {
+	id := start
+	if r := m.lookup(start); r != nil {
+		if vb := r.handler.GetNext(start, end, include); vb.Tag != asn.TagEndOfMibView {
+			return vb
+		}
+		id = r.subtree
+	}
+	for r := m.next(id); r != nil; r = m.next(r.subtree) {
+		if len(end) > 0 && oid.Ge(r.subtree, end) {
+			break
+		}
+		// all instances of the subtree follow start
+		if vb := r.handler.GetNext(r.subtree, end, true); vb.Tag != asn.TagEndOfMibView {
+			return vb
+		}
+	}
+	return asn.Varbind{Oid: start, Tag: asn.TagEndOfMibView}
+}
+
+func (m *Mux) TestSet(vb asn.Varbind) pduerror.Error {
+	if r := m.lookup(vb.Oid); r != nil {
+		return r.handler.TestSet(vb)
+	}
+	return pduerror.NotWritable
+}
+
+func (m *Mux) CommitSet(vb asn.Varbind) pduerror.Error {
+	if r := m.lookup(vb.Oid); r != nil {
+		return r.handler.CommitSet(vb)
+	}
+	return pduerror.CommitFailed
+}
+
+func (m *Mux) UndoSet(vb asn.Varbind) pduerror.Error {
+	if r := m.lookup(vb.Oid); r != nil {
+		return r.handler.UndoSet(vb)
+	}
+	return pduerror.UndoFailed
+}
+
+func (m *Mux) CleanupSet(vb asn.Varbind) {
+	if r := m.lookup(vb.Oid); r != nil {
+		r.handler.CleanupSet(vb)
+	}
+}
@@ -0,0 +1,113 @@
+package mib
+
+import (
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+func testScalar(id []uint32, value int32) *Scalar {
+	return &Scalar{
+		Oid:      id,
+		Tag:      asn.TagInteger32,
+		GetValue: func() interface{} { return value },
+		SetValue: func(v interface{}) pduerror.Error {
+			value = v.(int32)
+			return pduerror.NoError
+		},
+	}
+}
+
+var (
+	testOid1 = []uint32{1, 3, 6, 1, 4, 1, 999, 1}
+	testOid2 = []uint32{1, 3, 6, 1, 4, 1, 999, 2}
+	testOid3 = []uint32{1, 3, 6, 1, 4, 1, 999, 3, 1}
+)
+
+func createTestMux(t *testing.T) *Mux {
+	m := NewMux()
+	for i, id := range [][]uint32{testOid1, testOid2, testOid3} {
+		if err := m.Register(id, testScalar(id, int32(i+1))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestMuxRegister(t *testing.T) {
+	m := createTestMux(t)
+	for i, id := range [][]uint32{
+		testOid1,
+		oid.Cat(testOid2, 0),
+		testOid3[:len(testOid3)-1],
+		nil,
+	} {
+		if err := m.Register(id, &Scalar{}); err != ErrRegistration {
+			t.Errorf("TestMuxRegister[%d]: expected ErrRegistration, received %v", i, err)
+		}
+	}
+
+	if m.Unregister(oid.Cat(testOid1, 0)) || !m.Unregister(testOid3) {
+		t.Errorf("unexpected result of Unregister")
+	}
+	if subtree, _ := m.Lookup(oid.Cat(testOid3, 0)); subtree != nil {
+		t.Errorf("unregistered subtree %s is found", oid.String(subtree))
+	}
+	if err := m.Register(testOid3[:len(testOid3)-1], &Scalar{}); err != nil {
+		t.Errorf("failed to register parent of unregistered subtree: %v", err)
+	}
+}
+
+var testDataMuxGet = []struct {
+	id  []uint32
+	tag asn.Tag
+}{
+	{id: oid.Cat(testOid1, 0), tag: asn.TagInteger32},
+	{id: oid.Cat(testOid1, 1), tag: asn.TagNoSuchInstance},
+	{id: testOid1, tag: asn.TagNoSuchInstance},
+	{id: []uint32{1, 3, 6, 1, 4, 1, 999, 3, 2}, tag: asn.TagNoSuchObject},
+}
+
+func TestMuxGet(t *testing.T) {
+	m := createTestMux(t)
+	for i, test := range testDataMuxGet {
+		if vb := m.Get(test.id); vb.Tag != test.tag {
+			t.Errorf("TestMuxGet[%d]: %s", i, vb.String())
+		}
+	}
+}
+
+var testDataMuxGetNext = []struct {
+	start   []uint32
+	end     []uint32
+	include bool
+	res     []uint32
+	value   int32
+}{
+	{start: nil, res: oid.Cat(testOid1, 0), value: 1},
+	{start: oid.Cat(testOid1, 0), res: oid.Cat(testOid2, 0), value: 2},
+	{start: oid.Cat(testOid1, 0), include: true, res: oid.Cat(testOid1, 0), value: 1},
+	{start: oid.Cat(testOid1, 5), res: oid.Cat(testOid2, 0), value: 2},
+	{start: testOid2, res: oid.Cat(testOid2, 0), value: 2},
+	{start: oid.Cat(testOid2, 0), res: oid.Cat(testOid3, 0), value: 3},
+	{start: oid.Cat(testOid2, 0), end: testOid3, res: nil},
+	{start: oid.Cat(testOid3, 0), res: nil},
+}
+
+func TestMuxGetNext(t *testing.T) {
+	m := createTestMux(t)
+	for i, test := range testDataMuxGetNext {
+		vb := m.GetNext(test.start, test.end, test.include)
+		if test.res == nil {
+			if vb.Tag != asn.TagEndOfMibView {
+				t.Errorf("TestMuxGetNext[%d]: expected EndOfMibView, received %s", i, vb.String())
+			}
+			continue
+		}
+		if !oid.Eq(vb.Oid, test.res) || vb.Value != test.value {
+			t.Errorf("TestMuxGetNext[%d]: %s", i, vb.String())
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package mib
+
+import (
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+// Scalar is the Handler of a scalar object, i.e. the object with
+// the single instance Oid.0. The handler is to be registered for
+// the Oid subtree. If SetValue is nil, the object is read-only.
+type Scalar struct {
+	Oid      []uint32
+	Tag      asn.Tag
+	GetValue func() interface{}
+	SetValue func(v interface{}) pduerror.Error
+
+	// undo is the value before CommitSet.
+	undo interface{}
+}
+
+func (s *Scalar) instance() []uint32 {
+	return oid.Cat(s.Oid, 0)
+}
+
+func (s *Scalar) varbind() asn.Varbind {
+	return asn.Varbind{Oid: s.instance(), Tag: s.Tag, Value: s.GetValue()}
+}
+
+func (s *Scalar) Get(id []uint32) asn.Varbind {
+	switch {
+	case oid.Eq(id, s.instance()):
+		return s.varbind()
+	case oid.HasPrefix(id, s.Oid...):
+		return asn.Varbind{Oid: id, Tag: asn.TagNoSuchInstance}
+	}
+	return asn.Varbind{Oid: id, Tag: asn.TagNoSuchObject}
+}
+
+func (s *Scalar) GetNext(start, end []uint32, include bool) asn.Varbind {
+	if InRange(s.instance(), start, end, include) {
+		return s.varbind()
+	}
+	return asn.Varbind{Oid: start, Tag: asn.TagEndOfMibView}
+}
+
+func (s *Scalar) TestSet(vb asn.Varbind) pduerror.Error {
+	switch {
+	case s.SetValue == nil:
+		return pduerror.NotWritable
+	case !oid.Eq(vb.Oid, s.instance()):
+		return pduerror.NoCreation
+	case vb.Tag != s.Tag || !vb.Tag.IsValidValue(vb.Value):
+		return pduerror.WrongType
+	}
+	return pduerror.NoError
+}
+
+func (s *Scalar) CommitSet(vb asn.Varbind) pduerror.Error {
+	s.undo = s.GetValue()
+	return s.SetValue(vb.Value)
+}
+
+func (s *Scalar) UndoSet(vb asn.Varbind) pduerror.Error {
+	if s.SetValue(s.undo) != pduerror.NoError {
+		return pduerror.UndoFailed
+	}
+	return pduerror.NoError
+}
+
+func (s *Scalar) CleanupSet(vb asn.Varbind) {
+	s.undo = nil
+}
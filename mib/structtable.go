@@ -0,0 +1,100 @@
+package mib
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// StructTable is the read-only Handler of the table whose rows
+// are the structs returned by Rows (see Binding for the struct
+// tags). The row index is encoded from the index fields, or it is
+// the 1-based position of the row if there are no index fields.
+// The noaccess columns are not served. The table is to be
+// registered for its Entry.
+type StructTable[T any] struct {
+	ReadOnly
+	Rows    func() []T
+	binding *Binding
+}
+
+// NewStructTable returns the table of the rows.
+func NewStructTable[T any](rows func() []T) (*StructTable[T], error) {
+	b, err := NewBinding(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &StructTable[T]{Rows: rows, binding: b}, nil
+}
+
+// Entry returns the oid of the table entry.
+func (t *StructTable[T]) Entry() []uint32 {
+	return t.binding.Entry
+}
+
+type indexedRow struct {
+	index []uint32
+	value reflect.Value
+}
+
+// rows returns the rows sorted by index. The rows with invalid
+// index are skipped.
+func (t *StructTable[T]) rows() []indexedRow {
+	values := t.Rows()
+	rows := make([]indexedRow, 0, len(values))
+	for i := range values {
+		v := reflect.ValueOf(&values[i]).Elem()
+		index, err := t.binding.Index(v)
+		if err != nil {
+			continue
+		}
+		if index == nil {
+			index = []uint32{uint32(i + 1)}
+		}
+		rows = append(rows, indexedRow{index: index, value: v})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return oid.Lt(rows[i].index, rows[j].index)
+	})
+	return rows
+}
+
+func (t *StructTable[T]) Get(id []uint32) asn.Varbind {
+	column, index, ok := t.binding.Column(id)
+	if !ok || t.binding.Columns[column].NoAccess {
+		return asn.Varbind{Oid: id, Tag: asn.TagNoSuchObject}
+	}
+	for _, row := range t.rows() {
+		if oid.Eq(row.index, index) {
+			if vb, err := t.binding.Varbind(row.value, column, index); err == nil {
+				return vb
+			}
+			break
+		}
+	}
+	return asn.Varbind{Oid: id, Tag: asn.TagNoSuchInstance}
+}
+
+func (t *StructTable[T]) GetNext(start, end []uint32, include bool) asn.Varbind {
+	rows := t.rows()
+	for column, col := range t.binding.Columns {
+		if col.NoAccess || oid.Compare(col.Oid, start) < 0 && !oid.HasPrefix(start, col.Oid...) {
+			continue
+		}
+		for _, row := range rows {
+			id := oid.Cat(col.Oid, row.index...)
+			if !InRange(id, start, end, include) {
+				if len(end) != 0 && oid.Ge(id, end) {
+					return asn.Varbind{Oid: start, Tag: asn.TagEndOfMibView}
+				}
+				continue
+			}
+			if vb, err := t.binding.Varbind(row.value, column, row.index); err == nil {
+				return vb
+			}
+		}
+	}
+	return asn.Varbind{Oid: start, Tag: asn.TagEndOfMibView}
+}
@@ -0,0 +1,289 @@
+/*
+Package index encodes the values of the table INDEX objects into the
+oid suffix of the column instances, and decodes them back (RFC 2578,
+7.7). The encoding is defined by the schema of the INDEX clause:
+
+	// INDEX { ifIndex, IMPLIED userName }
+	s := index.Schema{{Kind: index.Integer}, {Kind: index.OctetString, Implied: true}}
+	suffix, err := s.Encode(int32(3), "bob") // 3.98.111.98
+	values, err := s.Decode(suffix)           // uint32(3), "bob"
+
+The values are the asn varbind values: uint32 for Integer (the
+integer types are accepted by Encode), string for OctetString ([]byte
+is accepted), [4]byte for IpAddress (net.IP is accepted), and []uint32
+for ObjectId (oid.OID is accepted). The schema does not know the asn
+tags, so Decode returns uint32 for Integer32 indexes as well, and the
+caller converts them (see mib.Binding).
+*/
+package index
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+var (
+	// ErrSchema is returned if the schema is invalid.
+	ErrSchema = errors.New("index: invalid schema")
+	// ErrValue is returned if the value can not be encoded with
+	// the schema, or the suffix can not be decoded.
+	ErrValue = errors.New("index: invalid value")
+)
+
+// Kind is the encoding of the index object.
+type Kind int
+
+const (
+	// Integer is the single subid of INTEGER (non-negative) and
+	// Unsigned32 (Gauge32, TimeTicks, etc) values.
+	Integer Kind = iota
+	// OctetString is the octets preceded by the length, unless
+	// the length is fixed (see Field) or the string is IMPLIED.
+	OctetString
+	// IpAddress is the four octets of the address.
+	IpAddress
+	// ObjectId is the subids preceded by the length, unless the
+	// oid is IMPLIED.
+	ObjectId
+)
+
+var kindNames = map[Kind]string{
+	Integer:     "Integer",
+	OctetString: "OctetString",
+	IpAddress:   "IpAddress",
+	ObjectId:    "ObjectId",
+}
+
+func (k Kind) String() string {
+	if s, ok := kindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Field is the index object. Size is the length of the fixed-size
+// OctetString (e.g. 6 for MacAddress), it is 0 for the variable
+// length strings. Implied is set for the last object of the INDEX
+// clause which has IMPLIED keyword.
+type Field struct {
+	Kind    Kind
+	Size    int
+	Implied bool
+}
+
+// Schema is the list of the index objects in the order of INDEX
+// clause.
+type Schema []Field
+
+// Validate tests whether only the last field is IMPLIED, and only
+// the variable length fields are IMPLIED or sized.
+func (s Schema) Validate() error {
+	for i, f := range s {
+		switch {
+		case f.Kind < Integer || f.Kind > ObjectId:
+			return fmt.Errorf("%w: field %d: unknown kind %s", ErrSchema, i, f.Kind)
+		case f.Size < 0 || f.Size > 0 && f.Kind != OctetString:
+			return fmt.Errorf("%w: field %d: size %d of %s", ErrSchema, i, f.Size, f.Kind)
+		case f.Implied && i != len(s)-1:
+			return fmt.Errorf("%w: field %d: IMPLIED is not last", ErrSchema, i)
+		case f.Implied && (f.Size > 0 || f.Kind != OctetString && f.Kind != ObjectId):
+			return fmt.Errorf("%w: field %d: IMPLIED %s of fixed length", ErrSchema, i, f.Kind)
+		}
+	}
+	return nil
+}
+
+// Encode returns the oid suffix of the index values. The number
+// of values is to be the length of the schema.
+func (s Schema) Encode(values ...interface{}) (oid.OID, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if len(values) != len(s) {
+		return nil, fmt.Errorf("%w: %d values for %d fields", ErrValue, len(values), len(s))
+	}
+	var suffix oid.OID
+	for i, f := range s {
+		var err error
+		if suffix, err = f.encode(suffix, values[i]); err != nil {
+			return nil, fmt.Errorf("%w: field %d: %v", ErrValue, i, err)
+		}
+	}
+	if len(suffix) > oid.MaxLength {
+		return nil, fmt.Errorf("%w: suffix length %d > %d", ErrValue, len(suffix), oid.MaxLength)
+	}
+	return suffix, nil
+}
+
+func (f Field) encode(suffix oid.OID, v interface{}) (oid.OID, error) {
+	switch f.Kind {
+	case Integer:
+		n, ok := integerValue(v)
+		if !ok {
+			return nil, fmt.Errorf("%T value %v is not Integer", v, v)
+		}
+		return append(suffix, n), nil
+	case OctetString:
+		var s string
+		switch v := v.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		default:
+			return nil, fmt.Errorf("%T value is not OctetString", v)
+		}
+		switch {
+		case f.Size > 0 && len(s) != f.Size:
+			return nil, fmt.Errorf("OctetString length %d != %d", len(s), f.Size)
+		case f.Size == 0 && !f.Implied:
+			suffix = append(suffix, uint32(len(s)))
+		}
+		for i := 0; i < len(s); i++ {
+			suffix = append(suffix, uint32(s[i]))
+		}
+		return suffix, nil
+	case IpAddress:
+		var ip [4]byte
+		switch v := v.(type) {
+		case [4]byte:
+			ip = v
+		case net.IP:
+			ip4 := v.To4()
+			if ip4 == nil {
+				return nil, fmt.Errorf("%s is not IPv4 address", v)
+			}
+			copy(ip[:], ip4)
+		default:
+			return nil, fmt.Errorf("%T value is not IpAddress", v)
+		}
+		return append(suffix, uint32(ip[0]), uint32(ip[1]), uint32(ip[2]), uint32(ip[3])), nil
+	case ObjectId:
+		var id []uint32
+		switch v := v.(type) {
+		case []uint32:
+			id = v
+		case oid.OID:
+			id = v
+		default:
+			return nil, fmt.Errorf("%T value is not ObjectId", v)
+		}
+		if !f.Implied {
+			suffix = append(suffix, uint32(len(id)))
+		}
+		return append(suffix, id...), nil
+	}
+	return nil, fmt.Errorf("unknown kind %s", f.Kind)
+}
+
+// integerValue converts the value of an integer type to the subid.
+func integerValue(v interface{}) (uint32, bool) {
+	var n int64
+	switch v := v.(type) {
+	case int:
+		n = int64(v)
+	case int8:
+		n = int64(v)
+	case int16:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint:
+		if uint64(v) > math.MaxUint32 {
+			return 0, false
+		}
+		n = int64(v)
+	case uint8:
+		n = int64(v)
+	case uint16:
+		n = int64(v)
+	case uint32:
+		n = int64(v)
+	case uint64:
+		if v > math.MaxUint32 {
+			return 0, false
+		}
+		n = int64(v)
+	default:
+		return 0, false
+	}
+	if n < 0 || n > math.MaxUint32 {
+		return 0, false
+	}
+	return uint32(n), true
+}
+
+// Decode returns the index values of the oid suffix. The whole
+// suffix is to be decoded. The Integer values are uint32, they are
+// to be converted to int32 for Integer32 objects.
+func (s Schema) Decode(suffix []uint32) ([]interface{}, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(s))
+	rest := suffix
+	for i, f := range s {
+		var err error
+		if values[i], rest, err = f.decode(rest); err != nil {
+			return nil, fmt.Errorf("%w: field %d of %s: %v", ErrValue, i, oid.String(suffix), err)
+		}
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: %s has %d extra subids", ErrValue, oid.String(suffix), len(rest))
+	}
+	return values, nil
+}
+
+func (f Field) decode(suffix []uint32) (v interface{}, rest []uint32, err error) {
+	n := 0
+	switch f.Kind {
+	case Integer:
+		n = 1
+	case IpAddress:
+		n = 4
+	case OctetString, ObjectId:
+		switch {
+		case f.Size > 0:
+			n = f.Size
+		case f.Implied:
+			n = len(suffix)
+		case len(suffix) == 0:
+			return nil, nil, errors.New("no length")
+		case suffix[0] > uint32(len(suffix)-1):
+			// compared before the conversion, int is 32-bit on some platforms
+			return nil, nil, fmt.Errorf("%s length %d > %d", f.Kind, suffix[0], len(suffix)-1)
+		default:
+			n, suffix = int(suffix[0]), suffix[1:]
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown kind %s", f.Kind)
+	}
+	if n > len(suffix) {
+		return nil, nil, fmt.Errorf("%s length %d > %d", f.Kind, n, len(suffix))
+	}
+	subids, rest := suffix[:n], suffix[n:]
+
+	switch f.Kind {
+	case Integer:
+		return subids[0], rest, nil
+	case ObjectId:
+		return oid.Clone(subids), rest, nil
+	}
+	b := make([]byte, n)
+	for i, subid := range subids {
+		if subid > math.MaxUint8 {
+			return nil, nil, fmt.Errorf("octet %d > 255", subid)
+		}
+		b[i] = byte(subid)
+	}
+	if f.Kind == IpAddress {
+		return [4]byte{b[0], b[1], b[2], b[3]}, rest, nil
+	}
+	return string(b), rest, nil
+}
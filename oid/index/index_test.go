@@ -0,0 +1,143 @@
+package index
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+var testDataEncode = []struct {
+	schema Schema
+	values []interface{}
+	suffix string
+	// decoded are the values returned by Decode if they differ
+	// from values.
+	decoded []interface{}
+}{
+	{
+		schema: Schema{{Kind: Integer}},
+		values: []interface{}{uint32(7)},
+		suffix: "7",
+	},
+	{
+		schema:  Schema{{Kind: Integer}, {Kind: Integer}},
+		values:  []interface{}{int32(1), int64(4294967295)},
+		suffix:  "1.4294967295",
+		decoded: []interface{}{uint32(1), uint32(4294967295)},
+	},
+	{
+		schema: Schema{{Kind: OctetString}},
+		values: []interface{}{"abc"},
+		suffix: "3.97.98.99",
+	},
+	{
+		schema: Schema{{Kind: OctetString}},
+		values: []interface{}{""},
+		suffix: "0",
+	},
+	{
+		schema:  Schema{{Kind: OctetString, Size: 6}},
+		values:  []interface{}{[]byte{0, 1, 2, 3, 4, 255}},
+		suffix:  "0.1.2.3.4.255",
+		decoded: []interface{}{"\x00\x01\x02\x03\x04\xff"},
+	},
+	{
+		schema: Schema{{Kind: Integer}, {Kind: OctetString, Implied: true}},
+		values: []interface{}{uint32(3), "bob"},
+		suffix: "3.98.111.98",
+	},
+	{
+		schema:  Schema{{Kind: IpAddress}, {Kind: Integer}},
+		values:  []interface{}{net.IPv4(10, 0, 0, 1), uint16(161)},
+		suffix:  "10.0.0.1.161",
+		decoded: []interface{}{[4]byte{10, 0, 0, 1}, uint32(161)},
+	},
+	{
+		schema: Schema{{Kind: ObjectId}, {Kind: ObjectId, Implied: true}},
+		values: []interface{}{[]uint32{1, 3, 6}, []uint32{2, 5}},
+		suffix: "3.1.3.6.2.5",
+	},
+	{
+		schema:  Schema{{Kind: OctetString}, {Kind: ObjectId}},
+		values:  []interface{}{"", oid.OID{0, 0}},
+		suffix:  "0.2.0.0",
+		decoded: []interface{}{"", []uint32{0, 0}},
+	},
+	{
+		schema: Schema{},
+		values: []interface{}{},
+		suffix: "",
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for i, test := range testDataEncode {
+		suffix, err := test.schema.Encode(test.values...)
+		if err != nil || suffix.String() != test.suffix {
+			t.Errorf("TestEncode[%d]: %s, %v", i, suffix, err)
+			continue
+		}
+		values, err := test.schema.Decode(suffix)
+		decoded := test.decoded
+		if decoded == nil {
+			decoded = test.values
+		}
+		if err != nil || !reflect.DeepEqual(values, decoded) {
+			t.Errorf("TestEncode[%d]: decoded %#v, %v", i, values, err)
+		}
+	}
+}
+
+var testDataEncodeError = []struct {
+	schema Schema
+	values []interface{}
+	err    error
+}{
+	{schema: Schema{{Kind: Integer}}, values: []interface{}{-1}, err: ErrValue},
+	{schema: Schema{{Kind: Integer}}, values: []interface{}{uint64(1 << 32)}, err: ErrValue},
+	{schema: Schema{{Kind: Integer}}, values: []interface{}{"1"}, err: ErrValue},
+	{schema: Schema{{Kind: Integer}}, values: []interface{}{1, 2}, err: ErrValue},
+	{schema: Schema{{Kind: OctetString, Size: 6}}, values: []interface{}{"abc"}, err: ErrValue},
+	{schema: Schema{{Kind: IpAddress}}, values: []interface{}{net.ParseIP("::1")}, err: ErrValue},
+	{schema: Schema{{Kind: ObjectId}}, values: []interface{}{make([]uint32, oid.MaxLength)}, err: ErrValue},
+	{schema: Schema{{Kind: OctetString, Implied: true}, {Kind: Integer}}, values: []interface{}{"a", 1}, err: ErrSchema},
+	{schema: Schema{{Kind: Integer, Implied: true}}, values: []interface{}{1}, err: ErrSchema},
+	{schema: Schema{{Kind: OctetString, Size: 6, Implied: true}}, values: []interface{}{"abcdef"}, err: ErrSchema},
+	{schema: Schema{{Kind: IpAddress, Size: 4}}, values: []interface{}{[4]byte{}}, err: ErrSchema},
+	{schema: Schema{{Kind: Kind(9)}}, values: []interface{}{1}, err: ErrSchema},
+}
+
+func TestEncodeError(t *testing.T) {
+	for i, test := range testDataEncodeError {
+		if _, err := test.schema.Encode(test.values...); !errors.Is(err, test.err) {
+			t.Errorf("TestEncodeError[%d]: error %v", i, err)
+		}
+	}
+}
+
+var testDataDecodeError = []struct {
+	schema Schema
+	suffix string
+}{
+	{schema: Schema{{Kind: Integer}}, suffix: ""},
+	{schema: Schema{{Kind: Integer}}, suffix: "1.2"},
+	{schema: Schema{{Kind: OctetString}}, suffix: ""},
+	{schema: Schema{{Kind: OctetString}}, suffix: "3.97.98"},
+	{schema: Schema{{Kind: OctetString}}, suffix: "1.256"},
+	{schema: Schema{{Kind: OctetString, Size: 2}}, suffix: "97.98.99"},
+	{schema: Schema{{Kind: IpAddress}}, suffix: "10.0.0"},
+	{schema: Schema{{Kind: IpAddress}}, suffix: "10.0.0.300"},
+	{schema: Schema{{Kind: ObjectId}}, suffix: "4294967295.1"},
+	{schema: Schema{{Kind: OctetString}}, suffix: "4294967295.97"},
+}
+
+func TestDecodeError(t *testing.T) {
+	for i, test := range testDataDecodeError {
+		if _, err := test.schema.Decode(oid.MustParse(test.suffix)); !errors.Is(err, ErrValue) {
+			t.Errorf("TestDecodeError[%d]: error %v", i, err)
+		}
+	}
+}
@@ -1,43 +1,95 @@
 package oid
 
-// MibName maps mib name to []uint32
-var Name = map[string][]uint32{
-	"internet":     {1, 3, 6, 1},
-	"directory":    {1, 3, 6, 1, 1},
-	"mgmt":         {1, 3, 6, 1, 2},
-	"experimental": {1, 3, 6, 1, 3},
-	"private":      {1, 3, 6, 1, 4},
-	"security":     {1, 3, 6, 1, 5},
-	"snmpV2":       {1, 3, 6, 1, 6},
-	"mib2":         {1, 3, 6, 1, 2, 1},
-	"enterprises":  {1, 3, 6, 1, 4, 1},
-	// System
-	"system":          {1, 3, 6, 1, 2, 1},
-	"sysDescr":        {1, 3, 6, 1, 2, 1, 1}, // OctetString  read-only
-	"sysOid":          {1, 3, 6, 1, 2, 1, 2}, // ObjectID     read-only
-	"sysUpTime":       {1, 3, 6, 1, 2, 1, 3}, // TimeTicks    read-only
-	"sysContact":      {1, 3, 6, 1, 2, 1, 4}, // OctetString  read-write
-	"sysName":         {1, 3, 6, 1, 2, 1, 5}, // OctetString  read-write
-	"sysLocation":     {1, 3, 6, 1, 2, 1, 6}, // OctetString  read-write
-	"sysServices":     {1, 3, 6, 1, 2, 1, 7}, // Integer32    read-only
-	"sysORLastChange": {1, 3, 6, 1, 2, 1, 8}, // TimeTicks    read-only
-	"sysORTable":      {1, 3, 6, 1, 2, 1, 9},
-	"sysOREntry":      {1, 3, 6, 1, 2, 1, 9, 1},
-	"sysORIndex":      {1, 3, 6, 1, 2, 1, 9, 1, 1}, // Integer32    not-accessible
-	"sysORID":         {1, 3, 6, 1, 2, 1, 9, 1, 2}, // ObjectID     read-only
-	"sysORDescr":      {1, 3, 6, 1, 2, 1, 9, 1, 3}, // OctetString  read-only
-	"sysORUpTime":     {1, 3, 6, 1, 2, 1, 9, 1, 4}, // TimeTicks    read-only
-	// Interfaces
-	"interfaces":    {1, 3, 6, 1, 2, 1, 2},
-	"ifNumber":      {1, 3, 6, 1, 2, 1, 2, 1}, // Integer32  read-only
-	"ifTable":       {1, 3, 6, 1, 2, 1, 2, 2},
-	"ifEntry":       {1, 3, 6, 1, 2, 1, 2, 2, 1},
-	"ifIndex":       {1, 3, 6, 1, 2, 1, 2, 2, 1, 1}, // Integer32    read-only
-	"ifDescr":       {1, 3, 6, 1, 2, 1, 2, 2, 1, 2}, // OctetString  read-only
-	"ifType":        {1, 3, 6, 1, 2, 1, 2, 2, 1, 3}, // Integer32    read-only
-	"ifMtu":         {1, 3, 6, 1, 2, 1, 2, 2, 1, 4}, // Integer32    read-only
-	"ifSpeed":       {1, 3, 6, 1, 2, 1, 2, 2, 1, 5}, // Unsigned32   read-only
-	"ifPhysAddress": {1, 3, 6, 1, 2, 1, 2, 2, 1, 6}, // OctetString  read-only
-	"ifAdminStatus": {1, 3, 6, 1, 2, 1, 2, 2, 1, 7}, // Integer32    read-write
-	// to be continued ...
+// builtinNames are the names of DefaultRegistry by module.
+var builtinNames = []struct {
+	module string
+	names  map[string][]uint32
+}{
+	{"SNMPv2-SMI", map[string][]uint32{
+		"iso":          {1},
+		"org":          {1, 3},
+		"dod":          {1, 3, 6},
+		"internet":     {1, 3, 6, 1},
+		"directory":    {1, 3, 6, 1, 1},
+		"mgmt":         {1, 3, 6, 1, 2},
+		"mib-2":        {1, 3, 6, 1, 2, 1},
+		"transmission": {1, 3, 6, 1, 2, 1, 10},
+		"experimental": {1, 3, 6, 1, 3},
+		"private":      {1, 3, 6, 1, 4},
+		"enterprises":  {1, 3, 6, 1, 4, 1},
+		"security":     {1, 3, 6, 1, 5},
+		"snmpV2":       {1, 3, 6, 1, 6},
+		"snmpDomains":  {1, 3, 6, 1, 6, 1},
+		"snmpProxys":   {1, 3, 6, 1, 6, 2},
+		"snmpModules":  {1, 3, 6, 1, 6, 3},
+	}},
+	{"SNMPv2-MIB", map[string][]uint32{
+		"system":                {1, 3, 6, 1, 2, 1, 1},
+		"sysDescr":              {1, 3, 6, 1, 2, 1, 1, 1}, // OctetString  read-only
+		"sysObjectID":           {1, 3, 6, 1, 2, 1, 1, 2}, // ObjectID     read-only
+		"sysUpTime":             {1, 3, 6, 1, 2, 1, 1, 3}, // TimeTicks    read-only
+		"sysContact":            {1, 3, 6, 1, 2, 1, 1, 4}, // OctetString  read-write
+		"sysName":               {1, 3, 6, 1, 2, 1, 1, 5}, // OctetString  read-write
+		"sysLocation":           {1, 3, 6, 1, 2, 1, 1, 6}, // OctetString  read-write
+		"sysServices":           {1, 3, 6, 1, 2, 1, 1, 7}, // Integer32    read-only
+		"sysORLastChange":       {1, 3, 6, 1, 2, 1, 1, 8}, // TimeTicks    read-only
+		"sysORTable":            {1, 3, 6, 1, 2, 1, 1, 9},
+		"sysOREntry":            {1, 3, 6, 1, 2, 1, 1, 9, 1},
+		"sysORIndex":            {1, 3, 6, 1, 2, 1, 1, 9, 1, 1}, // Integer32    not-accessible
+		"sysORID":               {1, 3, 6, 1, 2, 1, 1, 9, 1, 2}, // ObjectID     read-only
+		"sysORDescr":            {1, 3, 6, 1, 2, 1, 1, 9, 1, 3}, // OctetString  read-only
+		"sysORUpTime":           {1, 3, 6, 1, 2, 1, 1, 9, 1, 4}, // TimeTicks    read-only
+		"snmp":                  {1, 3, 6, 1, 2, 1, 11},
+		"snmpMIB":               {1, 3, 6, 1, 6, 3, 1},
+		"snmpTrapOID":           {1, 3, 6, 1, 6, 3, 1, 1, 4, 1},
+		"snmpTraps":             {1, 3, 6, 1, 6, 3, 1, 1, 5},
+		"coldStart":             {1, 3, 6, 1, 6, 3, 1, 1, 5, 1},
+		"warmStart":             {1, 3, 6, 1, 6, 3, 1, 1, 5, 2},
+		"authenticationFailure": {1, 3, 6, 1, 6, 3, 1, 1, 5, 5},
+	}},
+	{"IF-MIB", map[string][]uint32{
+		"interfaces":    {1, 3, 6, 1, 2, 1, 2},
+		"ifNumber":      {1, 3, 6, 1, 2, 1, 2, 1}, // Integer32    read-only
+		"ifTable":       {1, 3, 6, 1, 2, 1, 2, 2},
+		"ifEntry":       {1, 3, 6, 1, 2, 1, 2, 2, 1},
+		"ifIndex":       {1, 3, 6, 1, 2, 1, 2, 2, 1, 1},  // Integer32    read-only
+		"ifDescr":       {1, 3, 6, 1, 2, 1, 2, 2, 1, 2},  // OctetString  read-only
+		"ifType":        {1, 3, 6, 1, 2, 1, 2, 2, 1, 3},  // Integer32    read-only
+		"ifMtu":         {1, 3, 6, 1, 2, 1, 2, 2, 1, 4},  // Integer32    read-only
+		"ifSpeed":       {1, 3, 6, 1, 2, 1, 2, 2, 1, 5},  // Gauge32      read-only
+		"ifPhysAddress": {1, 3, 6, 1, 2, 1, 2, 2, 1, 6},  // OctetString  read-only
+		"ifAdminStatus": {1, 3, 6, 1, 2, 1, 2, 2, 1, 7},  // Integer32    read-write
+		"ifOperStatus":  {1, 3, 6, 1, 2, 1, 2, 2, 1, 8},  // Integer32    read-only
+		"ifLastChange":  {1, 3, 6, 1, 2, 1, 2, 2, 1, 9},  // TimeTicks    read-only
+		"ifInOctets":    {1, 3, 6, 1, 2, 1, 2, 2, 1, 10}, // Counter32    read-only
+		"ifOutOctets":   {1, 3, 6, 1, 2, 1, 2, 2, 1, 16}, // Counter32    read-only
+	}},
+}
+
+// nameAliases are the former keys of Name which are not the MIB
+// names. They are kept in Name for compatibility, but are not
+// registered.
+var nameAliases = map[string]string{
+	"mib2":   "mib-2",
+	"sysOid": "sysObjectID",
+}
+
+// Name maps the names of DefaultRegistry to oids. It is a flat
+// map kept for convenience, the names added to it are not known
+// to the registry (see Register). The former keys "mib2" and
+// "sysOid" are the aliases of "mib-2" and "sysObjectID".
+var Name = map[string][]uint32{}
+
+func init() {
+	for _, m := range builtinNames {
+		for name, id := range m.names {
+			Name[name] = id
+			if err := DefaultRegistry.Register(m.module, name, id); err != nil {
+				panic(err.Error())
+			}
+		}
+	}
+	for alias, name := range nameAliases {
+		Name[alias] = Name[name]
+	}
 }
@@ -9,3 +9,12 @@ func TestMibName(t *testing.T) {
 		t.Error("TestMibName")
 	}
 }
+
+func TestMibNameAliases(t *testing.T) {
+	if !Eq(Name["mib2"], []uint32{1, 3, 6, 1, 2, 1}) || !Eq(Name["sysOid"], []uint32{1, 3, 6, 1, 2, 1, 1, 2}) {
+		t.Errorf("TestMibNameAliases: mib2 %s, sysOid %s", String(Name["mib2"]), String(Name["sysOid"]))
+	}
+	if _, err := Resolve("sysOid"); err == nil {
+		t.Errorf("TestMibNameAliases: alias is registered")
+	}
+}
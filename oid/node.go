@@ -1,21 +1,43 @@
 package oid
 
-// !!! Status: in progress (draft, not tested, to be continued)
-
 import "sort"
 
-type Node struct {
+// Node is the node of the Tree. The node holds a value if the
+// value was inserted for its oid, the other nodes only lead to
+// the descendants holding values.
+type Node[T any] struct {
 	Subid    uint32
-	Parent   *Node
-	Children []*Node
-	Data     interface{}
+	Parent   *Node[T]
+	Children []*Node[T]
+	Value    T
+	hasValue bool
+}
+
+// HasValue tests whether the node holds a value.
+func (node *Node[T]) HasValue() bool {
+	return node.hasValue
 }
 
-// searchShild searches for child with the specified subid in the
+// Oid returns the oid of the node. The nodes of snapshots have
+// no parents (see Snapshot), so their oids are not known.
+func (node *Node[T]) Oid() (id []uint32) {
+	n := 0
+	for p := node; p.Parent != nil; p = p.Parent {
+		n++
+	}
+	id = make([]uint32, n)
+	for ; node.Parent != nil; node = node.Parent {
+		n--
+		id[n] = node.Subid
+	}
+	return
+}
+
+// searchChild searches for child with the specified subid in the
 // sorted slice of node's children. If the child exists, it returns
 // its index and ok = true. Otherwise, it returns ok = false and
 // the index to insert a child.
-func (node *Node) searchChild(subid uint32) (ind int, ok bool) {
+func (node *Node[T]) searchChild(subid uint32) (ind int, ok bool) {
 	ind = sort.Search(len(node.Children), func(i int) bool {
 		return node.Children[i].Subid >= subid
 	})
@@ -23,26 +45,24 @@ func (node *Node) searchChild(subid uint32) (ind int, ok bool) {
 	return
 }
 
-// addChild adds new child with the specified subid to the sorted
-// slice of node's children. If the child already exists, it
-// returns nil. Otherwise, it returns the added child.
-func (node *Node) addChild(subid uint32) *Node {
-	if ind, ok := node.searchChild(subid); !ok {
-		child := &Node{Subid: subid, Parent: node}
-		if ind == len(node.Children) {
-			node.Children = append(node.Children, child)
-		} else {
-			node.Children = append(node.Children[:ind+1], node.Children[ind:]...)
-			node.Children[ind] = child
-		}
-		return child
+// addChild returns node's child with the specified subid. If the
+// child does not exist, it is added to the sorted slice of node's
+// children.
+func (node *Node[T]) addChild(subid uint32) *Node[T] {
+	ind, ok := node.searchChild(subid)
+	if ok {
+		return node.Children[ind]
 	}
-	return nil
+	child := &Node[T]{Subid: subid, Parent: node}
+	node.Children = append(node.Children, nil)
+	copy(node.Children[ind+1:], node.Children[ind:])
+	node.Children[ind] = child
+	return child
 }
 
 // getChild returns node's child with the specified subid. If the
 // child does not exist, it returns nil.
-func (node *Node) getChild(subid uint32) *Node {
+func (node *Node[T]) getChild(subid uint32) *Node[T] {
 	if ind, ok := node.searchChild(subid); ok {
 		return node.Children[ind]
 	}
@@ -52,36 +72,29 @@ func (node *Node) getChild(subid uint32) *Node {
 // removeChild removes child with the specified subid from the
 // slice of node's children. It returns false if there is no
 // such a child, and true otherwise.
-func (node *Node) removeChild(subid uint32) bool {
+func (node *Node[T]) removeChild(subid uint32) bool {
 	if ind, ok := node.searchChild(subid); ok {
-		node.Children = append(node.Children[:ind], node.Children[ind+1:]...)
+		copy(node.Children[ind:], node.Children[ind+1:])
+		node.Children[len(node.Children)-1] = nil
+		node.Children = node.Children[:len(node.Children)-1]
 		return true
 	}
 	return false
 }
 
-// addNode
-func (node *Node) addNode(oid []uint32) *Node {
-	i := 0
-	for ; i < len(oid); i++ {
-		if ind, ok := node.searchChild(oid[i]); ok {
-			node = node.Children[ind]
-			continue
-		}
-		break
-	}
-	if i == len(oid) {
-		return nil
-	}
-	for _, subid := range oid[i:] {
+// addNode returns the descendant node with the relative oid. The
+// missing nodes of the path are added.
+func (node *Node[T]) addNode(id []uint32) *Node[T] {
+	for _, subid := range id {
 		node = node.addChild(subid)
 	}
 	return node
 }
 
-// getNode
-func (node *Node) getNode(oid []uint32) *Node {
-	for _, subid := range oid {
+// getNode returns the descendant node with the relative oid. If
+// the node does not exist, it returns nil.
+func (node *Node[T]) getNode(id []uint32) *Node[T] {
+	for _, subid := range id {
 		if node = node.getChild(subid); node == nil {
 			break
 		}
@@ -89,93 +102,68 @@ func (node *Node) getNode(oid []uint32) *Node {
 	return node
 }
 
-// prune
-func (node *Node) prune() {
-	for len(node.Children) == 0 && node.Parent != nil {
-		node.Parent.removeChild(node.Subid)
-		node = node.Parent
+// prune removes the node and its ancestors which neither hold
+// a value nor have children.
+func (node *Node[T]) prune() {
+	for !node.hasValue && len(node.Children) == 0 && node.Parent != nil {
+		parent := node.Parent
+		parent.removeChild(node.Subid)
+		node.Parent = nil
+		node = parent
 	}
 }
 
-// removeNode
-func (node *Node) removeNode(oid []uint32, prune bool) bool {
-	if node = node.getNode(oid); node == nil || node.Parent == nil {
-		return false
-	}
-	node.Parent.removeChild(node.Subid)
-	if prune {
-		node.Parent.prune()
-	}
-	return true
+// walker visits the nodes holding values within the search range
+// (see SearchRange of RFC 2741, 5.2):
+//
+//	start <= oid < end  if include == true
+//	start <  oid < end  if include == false
+//
+// The empty end means no upper bound. The nodes are visited in
+// the lexicographical order of oids until fn returns false. The
+// oid passed to fn is reused, so it is only valid during the call.
+type walker[T any] struct {
+	id      []uint32
+	start   []uint32
+	end     []uint32
+	include bool
+	fn      func(id []uint32, node *Node[T]) bool
 }
 
-// getNextNode returns repeatition'th leaf node with oid such that
-// (see specification of SNMP GetNext and GetBulk commands):
-//		oid1 <= oid         if include1 == true  && oid2 == nil
-//		oid1 <  oid         if include1 == false && oid2 == nil
-//		oid1 <= oid < oid2  if include1 == true  && oid2 != nil
-//		oid1 <  oid < oid2  if include1 == false && oid2 != nil
-// If there is no such node or repeatition < 1, it returns nil.
-// Note that empty oid < not empty oid, so in case oid1 == nil,
-// it returns leaf node with the least oid.
-func (node *Node) getNextNode(oid1, oid2 []uint32, include1 bool, repeatition int16) (resnode *Node) {
-	if repeatition < 1 {
-		return
+// visit visits the subtree of the node with oid w.id; onStart
+// tells that w.id is a prefix of w.start. It returns false if
+// the walk is finished.
+func (w *walker[T]) visit(node *Node[T], onStart bool) bool {
+	if len(w.end) > 0 && Compare(w.id, w.end) >= 0 {
+		return false
 	}
-
-	var iterate func(*Node, int, bool, bool)
-	iterate = func(node *Node, ind int, eq1, eq2 bool) {
-		if len(node.Children) == 0 {
-			if (!eq1 || include1) && !eq2 {
-				if repeatition == 1 {
-					resnode = node
-				} else {
-					repeatition--
-				}
-			}
-			return
+	if node.hasValue && (!onStart || w.include && len(w.id) == len(w.start)) {
+		if !w.fn(w.id, node) {
+			return false
 		}
-		for _, child := range node.Children {
-			if eq1 && ind < len(oid1) && oid1[ind] > child.Subid {
-				continue
-			}
-			if eq2 && (ind >= len(oid2) || oid2[ind] < child.Subid) {
-				continue
-			}
-			if ind >= len(oid1) {
-				if ind >= len(oid2) {
-					iterate(child, ind, false, false)
-				} else {
-					iterate(child, ind+1, false, eq2 && child.Subid == oid2[ind])
-				}
-			} else {
-				if ind >= len(oid2) {
-					iterate(child, ind+1, eq1 && child.Subid == oid1[ind], false)
-				} else {
-					iterate(child, ind+1, eq1 && child.Subid == oid1[ind], eq2 && child.Subid == oid2[ind])
-				}
-			}
-			if resnode != nil {
-				return
+	}
+	children := node.Children
+	if onStart && len(w.id) < len(w.start) {
+		ind, ok := node.searchChild(w.start[len(w.id)])
+		if ok {
+			if !w.visitChild(children[ind], true) {
+				return false
 			}
+			ind++
 		}
+		children = children[ind:]
 	}
-
-	iterate(node, 0, true, len(oid2) > 0)
-	return
-}
-
-func (node *Node) forRangeLeaves(do func([]uint32, *Node)) {
-	var iterate func([]uint32, []*Node)
-	iterate = func(parentOid []uint32, nodes []*Node) {
-		for _, node := range nodes {
-			nodeOid := Cat(parentOid, node.Subid)
-			if len(node.Children) == 0 {
-				do(nodeOid, node)
-			} else {
-				iterate(nodeOid, node.Children)
-			}
+	for _, child := range children {
+		if !w.visitChild(child, false) {
+			return false
 		}
 	}
-	iterate(nil, node.Children)
+	return true
+}
+
+func (w *walker[T]) visitChild(child *Node[T], onStart bool) bool {
+	w.id = append(w.id, child.Subid)
+	ok := w.visit(child, onStart)
+	w.id = w.id[:len(w.id)-1]
+	return ok
 }
@@ -1,6 +1,7 @@
 package oid
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -111,39 +112,51 @@ func String(id []uint32) string {
 	return sb.String()
 }
 
-// Parse interprets a string s as oid string representation
-// and returns the corresponding oid. It panics if the string
-// can not be interpreted as oid string representation.
-// (Note the string may start with a leading dot which is
-// ignored in this case).
-func Parse(s string) (id []uint32) {
-	var err error
-	defer func() {
-		if err != nil {
-			panic(err.Error())
-		}
-	}()
+// MaxLength is the maximal number of subids in oid (RFC 2578, 3.5).
+const MaxLength = 128
+
+// ErrSyntax is returned by Parse if the string is not oid string
+// representation.
+var ErrSyntax = errors.New("oid: invalid syntax")
 
+// Parse interprets a string s as oid string representation and
+// returns the corresponding oid (the string may start with a leading
+// dot which is ignored). The empty string (or the single dot) is the
+// empty oid. It returns ErrSyntax if the string has empty or invalid
+// subids, or more than MaxLength subids.
+func Parse(s string) (OID, error) {
 	if s = strings.TrimPrefix(s, "."); len(s) == 0 {
-		return
+		return OID{}, nil
 	}
 	nsubids := strings.Count(s, ".") + 1
-	if nsubids > 128 {
-		err = fmt.Errorf("ParseOid: oid length %d > 128", nsubids)
-		return
+	if nsubids > MaxLength {
+		return nil, fmt.Errorf("%w: oid length %d > %d", ErrSyntax, nsubids, MaxLength)
 	}
 
-	id = make([]uint32, nsubids)
+	id := make(OID, nsubids)
 	for i, ind := 0, 0; i < nsubids; i++ {
 		s = s[ind:]
 		if ind = strings.IndexByte(s, '.'); ind == -1 {
 			ind = len(s)
 		}
-		var subid uint64
-		if subid, err = strconv.ParseUint(s[:ind], 10, 32); err != nil {
-			return
+		if ind == 0 {
+			return nil, fmt.Errorf("%w: empty subid %d", ErrSyntax, i+1)
+		}
+		subid, err := strconv.ParseUint(s[:ind], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: subid %q", ErrSyntax, s[:ind])
 		}
 		id[i], ind = uint32(subid), ind+1
 	}
-	return
+	return id, nil
+}
+
+// MustParse is like Parse but panics if the string can not be
+// parsed. It simplifies initialization of oid variables.
+func MustParse(s string) OID {
+	id, err := Parse(s)
+	if err != nil {
+		panic(err.Error())
+	}
+	return id
 }
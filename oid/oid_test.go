@@ -1,6 +1,11 @@
 package oid
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
 
 var testDataOidClone = []struct {
 	id []uint32
@@ -112,20 +117,102 @@ func TestOidString(t *testing.T) {
 	}
 }
 
+// testOnes returns the oid of n subids 1.
+func testOnes(n int) []uint32 {
+	id := make([]uint32, n)
+	for i := range id {
+		id[i] = 1
+	}
+	return id
+}
+
 var testDataOidParse = []struct {
 	str string
 	res []uint32
+	err bool
 }{
 	{str: "", res: []uint32{}},
 	{str: ".", res: []uint32{}},
 	{str: "1.2.3", res: []uint32{1, 2, 3}},
 	{str: ".1.2.3", res: []uint32{1, 2, 3}},
+	{str: "0.4294967295", res: []uint32{0, 4294967295}},
+	{str: "1..3", err: true},
+	{str: "1.2.", err: true},
+	{str: "..1", err: true},
+	{str: "1.a.3", err: true},
+	{str: "1.-2", err: true},
+	{str: "1.4294967296", err: true},
+	{str: strings.Repeat("1.", 127) + "1", res: testOnes(128)},
+	{str: strings.Repeat("1.", 128) + "1", err: true},
 }
 
 func TestOidParse(t *testing.T) {
 	for i, test := range testDataOidParse {
-		if res := Parse(test.str); !Eq(res, test.res) {
-			t.Errorf("TestOidParse[%d]", i)
+		res, err := Parse(test.str)
+		switch {
+		case test.err:
+			if !errors.Is(err, ErrSyntax) {
+				t.Errorf("TestOidParse[%d]: error %v", i, err)
+			}
+		case err != nil:
+			t.Errorf("TestOidParse[%d]: %v", i, err)
+		case len(res) != len(test.res) || !Eq(res, test.res):
+			t.Errorf("TestOidParse[%d]: %s", i, res)
 		}
 	}
 }
+
+func TestOID(t *testing.T) {
+	id := MustParse("1.3.6.1.2.1")
+	if id.String() != "1.3.6.1.2.1" {
+		t.Errorf("TestOID: String %s", id.String())
+	}
+	if id.Compare([]uint32{1, 3, 6, 1, 2, 2}) != -1 || !id.Equal([]uint32{1, 3, 6, 1, 2, 1}) {
+		t.Error("TestOID: Compare")
+	}
+	if !id.HasPrefix([]uint32{1, 3, 6}) || id.HasPrefix([]uint32{1, 3, 7}) {
+		t.Error("TestOID: HasPrefix")
+	}
+	child := id.Append(1, 1)
+	parent := child.Parent()
+	if child.String() != "1.3.6.1.2.1.1.1" || parent.String() != "1.3.6.1.2.1.1" {
+		t.Errorf("TestOID: Append %s, Parent %s", child, parent)
+	}
+	// the parent is not changed by appending to it
+	_ = append(parent, 5)
+	if child.String() != "1.3.6.1.2.1.1.1" {
+		t.Errorf("TestOID: Parent shares capacity %s", child)
+	}
+	if OID(nil).Parent() != nil {
+		t.Error("TestOID: Parent of empty oid")
+	}
+	// OID is accepted as []uint32
+	if !HasPrefix(child, id...) || !Eq(Cat(id, 1, 1), child) {
+		t.Error("TestOID: []uint32 functions")
+	}
+}
+
+func TestOIDMarshal(t *testing.T) {
+	type object struct {
+		Oid  OID   `json:"oid"`
+		Oids []OID `json:"oids"`
+	}
+	obj := object{Oid: MustParse("1.3.6.1"), Oids: []OID{{1, 2}, {}}}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"oid":"1.3.6.1","oids":["1.2",""]}` {
+		t.Errorf("TestOIDMarshal: %s", data)
+	}
+	var res object
+	if err = json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Oid.Equal(obj.Oid) || len(res.Oids) != 2 || !res.Oids[0].Equal(obj.Oids[0]) || len(res.Oids[1]) != 0 {
+		t.Errorf("TestOIDMarshal: %v", res)
+	}
+	if err = json.Unmarshal([]byte(`{"oid":"1..2"}`), &res); !errors.Is(err, ErrSyntax) {
+		t.Errorf("TestOIDMarshal: error %v", err)
+	}
+}
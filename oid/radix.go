@@ -0,0 +1,335 @@
+package oid
+
+import (
+	"sync"
+
+	"github.com/alexispb/mygosnmp/generics"
+)
+
+// radixNode is the node of RadixTree. The node is reached from its
+// parent by the edge of one or more subids (prefix). Only the root
+// and the nodes holding values may have less than two children.
+type radixNode[T any] struct {
+	prefix   []uint32
+	children []radixEdge[T]
+	value    T
+	hasValue bool
+}
+
+// radixEdge is the edge to the child node. The key is the first
+// subid of the child prefix, so the search for child does not
+// touch the children.
+type radixEdge[T any] struct {
+	key  uint32
+	node *radixNode[T]
+}
+
+// searchChild searches for child whose prefix starts with subid in
+// the sorted slice of node's children (see Node.searchChild).
+func (node *radixNode[T]) searchChild(subid uint32) (ind int, ok bool) {
+	lo, hi := 0, len(node.children)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if node.children[m].key < subid {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	return lo, lo < len(node.children) && node.children[lo].key == subid
+}
+
+func (node *radixNode[T]) insertChild(ind int, child *radixNode[T]) {
+	node.children = append(node.children, radixEdge[T]{})
+	copy(node.children[ind+1:], node.children[ind:])
+	node.children[ind] = radixEdge[T]{key: child.prefix[0], node: child}
+}
+
+func (node *radixNode[T]) removeChild(ind int) {
+	copy(node.children[ind:], node.children[ind+1:])
+	node.children[len(node.children)-1] = radixEdge[T]{}
+	node.children = node.children[:len(node.children)-1]
+}
+
+// mergeChild merges the node without value with its only child.
+func (node *radixNode[T]) mergeChild() {
+	child := node.children[0].node
+	prefix := make([]uint32, 0, len(node.prefix)+len(child.prefix))
+	prefix = append(prefix, node.prefix...)
+	node.prefix = append(prefix, child.prefix...)
+	node.children = child.children
+	node.value, node.hasValue = child.value, child.hasValue
+}
+
+// commonPrefix returns the length of the common prefix of oids.
+func commonPrefix(id1, id2 []uint32) int {
+	n := generics.Min(len(id1), len(id2))
+	for i := 0; i < n; i++ {
+		if id1[i] != id2[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// getNode returns the descendant node with the relative oid. If
+// the node does not exist, it returns nil.
+func (node *radixNode[T]) getNode(id []uint32) *radixNode[T] {
+	for len(id) > 0 {
+		ind, ok := node.searchChild(id[0])
+		if !ok {
+			return nil
+		}
+		node = node.children[ind].node
+		if !HasPrefix(id, node.prefix...) {
+			return nil
+		}
+		id = id[len(node.prefix):]
+	}
+	return node
+}
+
+// addNode returns the descendant node with the relative oid. The
+// missing node is added, the edge is split if oid ends inside it.
+func (node *radixNode[T]) addNode(id []uint32) *radixNode[T] {
+	for len(id) > 0 {
+		ind, ok := node.searchChild(id[0])
+		if !ok {
+			child := &radixNode[T]{prefix: Clone(id)}
+			node.insertChild(ind, child)
+			return child
+		}
+		child := node.children[ind].node
+		n := commonPrefix(child.prefix, id)
+		if n < len(child.prefix) {
+			// split the edge
+			mid := &radixNode[T]{
+				prefix:   child.prefix[:n:n],
+				children: []radixEdge[T]{{key: child.prefix[n], node: child}},
+			}
+			child.prefix = child.prefix[n:]
+			node.children[ind].node = mid
+			child = mid
+		}
+		node, id = child, id[n:]
+	}
+	return node
+}
+
+// radixWalker visits the nodes holding values within the search
+// range (see walker).
+type radixWalker[T any] struct {
+	id      []uint32
+	start   []uint32
+	end     []uint32
+	include bool
+	fn      func(id []uint32, value T) bool
+}
+
+// visit visits the subtree of the node with oid w.id; onStart
+// tells that w.id is a prefix of w.start. It returns false if
+// the walk is finished.
+func (w *radixWalker[T]) visit(node *radixNode[T], onStart bool) bool {
+	if len(w.end) > 0 && Compare(w.id, w.end) >= 0 {
+		return false
+	}
+	if node.hasValue && (!onStart || w.include && len(w.id) == len(w.start)) {
+		if !w.fn(w.id, node.value) {
+			return false
+		}
+	}
+	children := node.children
+	if onStart && len(w.id) < len(w.start) {
+		rest := w.start[len(w.id):]
+		ind, ok := node.searchChild(rest[0])
+		if ok {
+			child := node.children[ind].node
+			n := generics.Min(len(child.prefix), len(rest))
+			switch c := Compare(child.prefix[:n], rest[:n]); {
+			case c < 0:
+				// the subtree precedes start
+				ind++
+			case c == 0 && len(child.prefix) <= len(rest):
+				if !w.visitChild(child, true) {
+					return false
+				}
+				ind++
+			}
+		}
+		children = children[ind:]
+	}
+	for _, e := range children {
+		if !w.visitChild(e.node, false) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *radixWalker[T]) visitChild(child *radixNode[T], onStart bool) bool {
+	w.id = append(w.id, child.prefix...)
+	ok := w.visit(child, onStart)
+	w.id = w.id[:len(w.id)-len(child.prefix)]
+	return ok
+}
+
+// RadixTree is the path-compressed variant of Tree: the chain of
+// nodes with single child and without value is stored in one node,
+// so the large tables (e.g. with the indices of several subids)
+// take less memory and the walks chase less pointers. RadixTree
+// can be used concurrently.
+//
+// RadixTree is a separate API, not a replacement of Tree: it has no
+// nodes, so Insert returns nothing, GetNext returns the value, and
+// GetBulk returns the oids and the values, while Tree returns nodes
+// and entries, and there is no Node method. Only the methods of Map
+// are common with Tree.
+type RadixTree[T any] struct {
+	lock sync.RWMutex
+	root *radixNode[T]
+}
+
+func NewRadixTree[T any]() *RadixTree[T] {
+	return &RadixTree[T]{root: &radixNode[T]{}}
+}
+
+// Clear removes all values of the tree.
+func (t *RadixTree[T]) Clear() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.root = &radixNode[T]{}
+}
+
+// Insert sets the value for oid. The previous value (if any) is
+// replaced.
+func (t *RadixTree[T]) Insert(id []uint32, value T) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	node := t.root.addNode(id)
+	node.value, node.hasValue = value, true
+}
+
+// Get returns the value for oid. It returns ok = false if there
+// is no value for oid.
+func (t *RadixTree[T]) Get(id []uint32) (value T, ok bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if node := t.root.getNode(id); node != nil && node.hasValue {
+		return node.value, true
+	}
+	return
+}
+
+// Delete removes the value for oid (see Tree.Delete). It returns
+// false if there is no value for oid.
+func (t *RadixTree[T]) Delete(id []uint32) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var parent *radixNode[T]
+	node, ind := t.root, 0
+	for len(id) > 0 {
+		var ok bool
+		if ind, ok = node.searchChild(id[0]); !ok {
+			return false
+		}
+		parent, node = node, node.children[ind].node
+		if !HasPrefix(id, node.prefix...) {
+			return false
+		}
+		id = id[len(node.prefix):]
+	}
+	if !node.hasValue {
+		return false
+	}
+	var zero T
+	node.value, node.hasValue = zero, false
+	if parent == nil {
+		// root
+		return true
+	}
+	switch len(node.children) {
+	case 0:
+		parent.removeChild(ind)
+		if parent != t.root && !parent.hasValue && len(parent.children) == 1 {
+			parent.mergeChild()
+		}
+	case 1:
+		node.mergeChild()
+	}
+	return true
+}
+
+// GetNext returns the first value within the search range (see
+// Tree.GetNext) together with its oid. It returns ok = false if
+// there is no such value.
+func (t *RadixTree[T]) GetNext(start, end []uint32, include bool) (id []uint32, value T, ok bool) {
+	t.WalkRange(start, end, include, func(vid []uint32, v T) bool {
+		id, value, ok = Clone(vid), v, true
+		return false
+	})
+	return
+}
+
+// GetBulk returns up to n successive values within the search
+// range (see GetNext) together with their oids.
+func (t *RadixTree[T]) GetBulk(start, end []uint32, include bool, n int) (ids [][]uint32, values []T) {
+	if n < 1 {
+		return
+	}
+	t.WalkRange(start, end, include, func(id []uint32, value T) bool {
+		ids = append(ids, Clone(id))
+		values = append(values, value)
+		return len(ids) < n
+	})
+	return
+}
+
+// Walk calls fn for each value in the lexicographical order of
+// oids until fn returns false.
+func (t *RadixTree[T]) Walk(fn func(id []uint32, value T) bool) {
+	t.WalkRange(nil, nil, true, fn)
+}
+
+// WalkRange calls fn for each value within the search range (see
+// GetNext) in the lexicographical order of oids until fn returns
+// false.
+func (t *RadixTree[T]) WalkRange(start, end []uint32, include bool, fn func(id []uint32, value T) bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	w := &radixWalker[T]{
+		id:      make([]uint32, 0, 32),
+		start:   start,
+		end:     end,
+		include: include,
+		fn:      fn,
+	}
+	w.visit(t.root, true)
+}
+
+// WalkPrefix calls fn for the value of prefix and the values of
+// its descendants in the lexicographical order of oids until fn
+// returns false.
+func (t *RadixTree[T]) WalkPrefix(prefix []uint32, fn func(id []uint32, value T) bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	w := &radixWalker[T]{
+		id: make([]uint32, 0, len(prefix)+16),
+		fn: fn,
+	}
+	node, rest := t.root, prefix
+	for len(rest) > 0 {
+		ind, ok := node.searchChild(rest[0])
+		if !ok {
+			return
+		}
+		node = node.children[ind].node
+		n := commonPrefix(node.prefix, rest)
+		if n < len(node.prefix) && n < len(rest) {
+			return
+		}
+		// the edge may end beyond prefix
+		w.id = append(w.id, node.prefix...)
+		rest = rest[n:]
+	}
+	w.visit(node, false)
+}
@@ -0,0 +1,176 @@
+package oid
+
+import (
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func createTestRadixTree() *RadixTree[string] {
+	t := NewRadixTree[string]()
+	for _, s := range testTreeOids {
+		t.Insert(MustParse(s), s)
+	}
+	return t
+}
+
+func TestRadixTree(t *testing.T) {
+	tree := createTestRadixTree()
+	for i, s := range testTreeOids {
+		if value, ok := tree.Get(MustParse(s)); !ok || value != s {
+			t.Errorf("TestRadixTree[%d]: %v, %v", i, value, ok)
+		}
+	}
+	for _, s := range []string{"", "1.3.6.1", "1.3.6.1.2.1.2.2", "1.3.6.1.2.1.1.1.0.1", "1.3.6.1.2.1.1.1", "2"} {
+		if value, ok := tree.Get(MustParse(s)); ok {
+			t.Errorf("TestRadixTree: %q: %v", s, value)
+		}
+	}
+	for i, test := range testDataTreeGetNext {
+		id, value, ok := tree.GetNext(MustParse(test.start), MustParse(test.end), test.include)
+		if test.res == "" && ok || test.res != "" && (String(id) != test.res || value != test.res) {
+			t.Errorf("TestRadixTreeGetNext[%d]: %s (%v)", i, String(id), value)
+		}
+	}
+	for i, test := range testDataTreeGetBulk {
+		_, values := tree.GetBulk(MustParse(test.start), MustParse(test.end), test.include, test.n)
+		if strings.Join(values, " ") != strings.Join(test.res, " ") {
+			t.Errorf("TestRadixTreeGetBulk[%d]: %v", i, values)
+		}
+	}
+	for i, test := range testDataTreeWalk {
+		var res []string
+		fn := func(id []uint32, value string) bool {
+			res = append(res, value)
+			return true
+		}
+		if test.prefix != "" {
+			tree.WalkPrefix(MustParse(test.prefix), fn)
+		} else {
+			tree.WalkRange(MustParse(test.start), MustParse(test.end), test.include, fn)
+		}
+		if strings.Join(res, " ") != strings.Join(test.res, " ") {
+			t.Errorf("TestRadixTreeWalk[%d]: %v", i, res)
+		}
+	}
+
+	// the prefix ends inside the edge
+	var res []string
+	tree.WalkPrefix(MustParse("1.3.6.1.4"), func(id []uint32, value string) bool {
+		res = append(res, value)
+		return true
+	})
+	if len(res) != 1 || res[0] != "1.3.6.1.4.1.9999" {
+		t.Errorf("TestRadixTreeWalk: %v", res)
+	}
+
+	for _, s := range testTreeOids {
+		if !tree.Delete(MustParse(s)) || tree.Delete(MustParse(s)) {
+			t.Errorf("TestRadixTree: Delete %s", s)
+		}
+	}
+	if len(tree.root.children) != 0 {
+		t.Error("TestRadixTree: tree is not empty")
+	}
+}
+
+// TestRadixTreeRandom compares RadixTree with Tree after random
+// updates.
+func TestRadixTreeRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randOid := func() []uint32 {
+		id := make([]uint32, 1+rnd.Intn(6))
+		for i := range id {
+			id[i] = uint32(rnd.Intn(3))
+		}
+		return id
+	}
+	tree := NewTree[int]()
+	radix := NewRadixTree[int]()
+	for i := 0; i < 5000; i++ {
+		id := randOid()
+		if rnd.Intn(3) == 0 {
+			if res, expected := radix.Delete(id), tree.Delete(id); res != expected {
+				t.Fatalf("TestRadixTreeRandom[%d]: Delete %s: %v", i, String(id), res)
+			}
+		} else {
+			tree.Insert(id, i)
+			radix.Insert(id, i)
+		}
+
+		start, end := randOid(), randOid()
+		if rnd.Intn(2) == 0 {
+			end = nil
+		}
+		include := rnd.Intn(2) == 0
+		id1, node := tree.GetNext(start, end, include)
+		id2, value, ok := radix.GetNext(start, end, include)
+		if (node != nil) != ok || ok && (!Eq(id1, id2) || node.Value != value) {
+			t.Fatalf("TestRadixTreeRandom[%d]: GetNext(%s, %s, %v) = %s, expected %s",
+				i, String(start), String(end), include, String(id2), String(id1))
+		}
+	}
+
+	var expected, res []string
+	tree.Walk(func(id []uint32, value int) bool {
+		expected = append(expected, String(id))
+		return true
+	})
+	radix.Walk(func(id []uint32, value int) bool {
+		res = append(res, String(id))
+		return true
+	})
+	if diff := strings.Join(res, " "); diff != strings.Join(expected, " ") {
+		t.Errorf("TestRadixTreeRandom: %s", diff)
+	}
+}
+
+const benchLargeRows = 100000
+
+// benchLargeOid returns the oid of ipRouteTable (10 columns) cell,
+// so there are 1M oids. The route destinations are scattered as in
+// the real routing tables.
+func benchLargeOid(i int) []uint32 {
+	dest := uint32(i%benchLargeRows) * 2654435761 // Knuth's hash
+	return []uint32{1, 3, 6, 1, 2, 1, 4, 21, 1, uint32(1 + i/benchLargeRows),
+		dest >> 24, dest >> 16 & 0xff, dest >> 8 & 0xff, dest & 0xff}
+}
+
+// benchLarge builds the tree of 1M oids with insert and returns
+// the memory used per oid.
+func benchLarge(b *testing.B, insert func(id []uint32, value int)) float64 {
+	if testing.Short() {
+		b.Skip("large tree")
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 10*benchLargeRows; i++ {
+		insert(benchLargeOid(i), i)
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	b.ResetTimer()
+	return float64(after.HeapAlloc-before.HeapAlloc) / (10 * benchLargeRows)
+}
+
+func BenchmarkLargeTreeGetNext(b *testing.B) {
+	tree := NewTree[int]()
+	mem := benchLarge(b, func(id []uint32, value int) { tree.Insert(id, value) })
+	for i := 0; i < b.N; i++ {
+		tree.GetNext(benchLargeOid(i*7919%(10*benchLargeRows)), nil, false)
+	}
+	b.ReportMetric(mem, "B/oid")
+	runtime.KeepAlive(tree)
+}
+
+func BenchmarkLargeRadixTreeGetNext(b *testing.B) {
+	tree := NewRadixTree[int]()
+	mem := benchLarge(b, tree.Insert)
+	for i := 0; i < b.N; i++ {
+		tree.GetNext(benchLargeOid(i*7919%(10*benchLargeRows)), nil, false)
+	}
+	b.ReportMetric(mem, "B/oid")
+	runtime.KeepAlive(tree)
+}
@@ -0,0 +1,176 @@
+package oid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownName is returned if the name is not registered.
+	ErrUnknownName = errors.New("oid: unknown name")
+	// ErrAmbiguousName is returned if the name without module is
+	// registered for different oids by several modules.
+	ErrAmbiguousName = errors.New("oid: ambiguous name")
+	// ErrDuplicateName is returned if the name is registered by
+	// the module for another oid.
+	ErrDuplicateName = errors.New("oid: duplicate name")
+)
+
+// DefaultRegistry is the registry of the standard names used by
+// Resolve and Describe.
+var DefaultRegistry = NewRegistry()
+
+// registeredName is the name registered by the module.
+type registeredName struct {
+	module string
+	name   string
+	id     []uint32
+}
+
+// Registry maps the names defined by MIB modules to oids and vice
+// versa. The same name may be defined by several modules, then the
+// name is qualified with the module (e.g. IF-MIB::ifDescr). If one
+// oid has several names, the first registered name is used for
+// the oid description. Registry can be used concurrently.
+type Registry struct {
+	mu    sync.RWMutex
+	names map[string][]registeredName
+	oids  *Tree[registeredName]
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string][]registeredName),
+		oids:  NewTree[registeredName](),
+	}
+}
+
+// Register adds the name defined by the module. It returns
+// ErrDuplicateName if the module has defined the name for another
+// oid.
+func (r *Registry) Register(module, name string, id []uint32) error {
+	if name == "" || len(id) == 0 {
+		return fmt.Errorf("%w: empty name or oid of %q", ErrSyntax, name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range r.names[name] {
+		if n.module != module {
+			continue
+		}
+		if Eq(n.id, id) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s::%s is %s", ErrDuplicateName, module, name, String(n.id))
+	}
+	n := registeredName{module: module, name: name, id: Clone(id)}
+	r.names[name] = append(r.names[name], n)
+	if _, ok := r.oids.Get(id); !ok {
+		r.oids.Insert(n.id, n)
+	}
+	return nil
+}
+
+// RegisterModule adds the names defined by the module.
+func (r *Registry) RegisterModule(module string, names map[string][]uint32) error {
+	for name, id := range names {
+		if err := r.Register(module, name, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the oid of the name, which may be qualified with
+// the module (e.g. IF-MIB::ifDescr).
+func (r *Registry) Lookup(name string) (OID, error) {
+	module := ""
+	if ind := strings.Index(name, "::"); ind >= 0 {
+		module, name = name[:ind], name[ind+2:]
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var id []uint32
+	for _, n := range r.names[name] {
+		switch {
+		case module != "" && n.module != module:
+		case id == nil:
+			id = n.id
+		case !Eq(id, n.id):
+			return nil, fmt.Errorf("%w: %s", ErrAmbiguousName, name)
+		}
+	}
+	if id == nil {
+		if module != "" {
+			name = module + "::" + name
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnknownName, name)
+	}
+	return Clone(id), nil
+}
+
+// Resolve returns the oid of the name followed by subids (e.g.
+// ifDescr.3 or IF-MIB::ifDescr.3), or of the dotted oid.
+func (r *Registry) Resolve(s string) (OID, error) {
+	name, subids := s, ""
+	if ind := strings.IndexByte(s, '.'); ind >= 0 {
+		name, subids = s[:ind], s[ind:]
+	}
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		return Parse(s)
+	}
+	id, err := r.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if subids == "" {
+		return id, nil
+	}
+	suffix, err := Parse(subids)
+	if err != nil {
+		return nil, err
+	}
+	if len(suffix) == 0 || len(id)+len(suffix) > MaxLength {
+		return nil, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	return id.Append(suffix...), nil
+}
+
+// Describe returns the name of the longest registered prefix of
+// oid followed by the remaining subids (e.g. ifDescr.3). The name
+// is qualified with the module if it is ambiguous. If no prefix
+// is registered, it returns the dotted oid.
+func (r *Registry) Describe(id []uint32) string {
+	n, rn, ok := r.oids.LongestPrefix(id)
+	if !ok {
+		return String(id)
+	}
+	name := rn.name
+	if _, err := r.Lookup(name); err != nil {
+		name = rn.module + "::" + name
+	}
+	if n == len(id) {
+		return name
+	}
+	return name + "." + String(id[n:])
+}
+
+// Register adds the name defined by the module to DefaultRegistry
+// (see Registry.Register).
+func Register(module, name string, id []uint32) error {
+	return DefaultRegistry.Register(module, name, id)
+}
+
+// Resolve returns the oid of the name followed by subids with
+// DefaultRegistry (see Registry.Resolve).
+func Resolve(s string) (OID, error) {
+	return DefaultRegistry.Resolve(s)
+}
+
+// Describe returns the name of oid with DefaultRegistry (see
+// Registry.Describe).
+func Describe(id []uint32) string {
+	return DefaultRegistry.Describe(id)
+}
@@ -0,0 +1,124 @@
+package oid
+
+import (
+	"errors"
+	"testing"
+)
+
+var testDataResolve = []struct {
+	str string
+	res string
+	err error
+}{
+	{str: "ifDescr", res: "1.3.6.1.2.1.2.2.1.2"},
+	{str: "ifDescr.3", res: "1.3.6.1.2.1.2.2.1.2.3"},
+	{str: "IF-MIB::ifDescr.3", res: "1.3.6.1.2.1.2.2.1.2.3"},
+	{str: "sysUpTime.0", res: "1.3.6.1.2.1.1.3.0"},
+	{str: "system", res: "1.3.6.1.2.1.1"},
+	{str: "1.3.6.1.2.1.1.3.0", res: "1.3.6.1.2.1.1.3.0"},
+	{str: ".1.3.6", res: "1.3.6"},
+	{str: "", res: ""},
+	{str: "noSuchName.1", err: ErrUnknownName},
+	{str: "SNMPv2-MIB::ifDescr", err: ErrUnknownName},
+	{str: "ifDescr.", err: ErrSyntax},
+	{str: "ifDescr..1", err: ErrSyntax},
+	{str: "ifDescr.x", err: ErrSyntax},
+	{str: "1.x", err: ErrSyntax},
+}
+
+func TestResolve(t *testing.T) {
+	for i, test := range testDataResolve {
+		res, err := Resolve(test.str)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("TestResolve[%d]: error %v", i, err)
+			}
+			continue
+		}
+		if err != nil || res.String() != test.res {
+			t.Errorf("TestResolve[%d]: %s, %v", i, res, err)
+		}
+	}
+}
+
+var testDataDescribe = []struct {
+	id  string
+	res string
+}{
+	{id: "1.3.6.1.2.1.2.2.1.2", res: "ifDescr"},
+	{id: "1.3.6.1.2.1.2.2.1.2.3", res: "ifDescr.3"},
+	{id: "1.3.6.1.2.1.1.3.0", res: "sysUpTime.0"},
+	{id: "1.3.6.1.2.1.2.2.1.99.1", res: "ifEntry.99.1"},
+	{id: "1.3.6.1.4.1.9999.1", res: "enterprises.9999.1"},
+	{id: "1", res: "iso"},
+	{id: "2.1", res: "2.1"},
+	{id: "", res: ""},
+}
+
+func TestDescribe(t *testing.T) {
+	for i, test := range testDataDescribe {
+		if res := Describe(MustParse(test.id)); res != test.res {
+			t.Errorf("TestDescribe[%d]: %s", i, res)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	err := r.RegisterModule("A-MIB", map[string][]uint32{
+		"a":      {1, 3, 6, 1, 4, 1, 9999},
+		"aTable": {1, 3, 6, 1, 4, 1, 9999, 1},
+		"status": {1, 3, 6, 1, 4, 1, 9999, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same name in another module
+	if err = r.Register("B-MIB", "status", []uint32{1, 3, 6, 1, 4, 1, 8888, 2}); err != nil {
+		t.Fatal(err)
+	}
+	// the same name for the same oid
+	if err = r.Register("C-MIB", "aTable", []uint32{1, 3, 6, 1, 4, 1, 9999, 1}); err != nil {
+		t.Fatal(err)
+	}
+	// another name for the same oid
+	if err = r.Register("C-MIB", "alias", []uint32{1, 3, 6, 1, 4, 1, 9999}); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Register("A-MIB", "a", []uint32{1, 3, 6, 1, 4, 1, 9999}); err != nil {
+		t.Errorf("TestRegistry: repeated registration %v", err)
+	}
+	if err = r.Register("A-MIB", "a", []uint32{1, 3, 6, 1, 4, 1, 7777}); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("TestRegistry: duplicate error %v", err)
+	}
+	if err = r.Register("A-MIB", "", []uint32{1}); !errors.Is(err, ErrSyntax) {
+		t.Errorf("TestRegistry: empty name error %v", err)
+	}
+
+	if _, err = r.Resolve("status.1"); !errors.Is(err, ErrAmbiguousName) {
+		t.Errorf("TestRegistry: ambiguous error %v", err)
+	}
+	for s, expected := range map[string]string{
+		"A-MIB::status.1": "1.3.6.1.4.1.9999.2.1",
+		"B-MIB::status.1": "1.3.6.1.4.1.8888.2.1",
+		"aTable.1.2":      "1.3.6.1.4.1.9999.1.1.2",
+		"alias":           "1.3.6.1.4.1.9999",
+	} {
+		if id, err := r.Resolve(s); err != nil || id.String() != expected {
+			t.Errorf("TestRegistry: Resolve(%s) = %s, %v", s, id, err)
+		}
+	}
+
+	for id, expected := range map[string]string{
+		"1.3.6.1.4.1.9999":       "a",
+		"1.3.6.1.4.1.9999.1.5":   "aTable.5",
+		"1.3.6.1.4.1.9999.2.1":   "A-MIB::status.1",
+		"1.3.6.1.4.1.8888.2":     "B-MIB::status",
+		"1.3.6.1.4.1.8888.3":     "1.3.6.1.4.1.8888.3",
+		"1.3.6.1.4.1.9999.3.1.1": "a.3.1.1",
+	} {
+		if res := r.Describe(MustParse(id)); res != expected {
+			t.Errorf("TestRegistry: Describe(%s) = %s", id, res)
+		}
+	}
+}
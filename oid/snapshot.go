@@ -0,0 +1,239 @@
+package oid
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Snapshot is the read-only view of the tree nodes. The snapshots
+// of SnapshotTree are immutable, so they are read without locking.
+// The nodes of the snapshots have no parents (Parent is nil).
+type Snapshot[T any] struct {
+	root *Node[T]
+}
+
+// Get returns the value for oid. It returns ok = false if there
+// is no value for oid.
+func (s Snapshot[T]) Get(id []uint32) (value T, ok bool) {
+	if node := s.root.getNode(id); node != nil && node.hasValue {
+		return node.Value, true
+	}
+	return
+}
+
+// Node returns the node for oid (see Tree.Node).
+func (s Snapshot[T]) Node(id []uint32) *Node[T] {
+	return s.root.getNode(id)
+}
+
+// GetNext returns the first node holding a value within the search
+// range together with its oid (see Tree.GetNext).
+func (s Snapshot[T]) GetNext(start, end []uint32, include bool) (id []uint32, node *Node[T]) {
+	s.walk(start, end, include, func(nid []uint32, n *Node[T]) bool {
+		id, node = Clone(nid), n
+		return false
+	})
+	return
+}
+
+// GetBulk returns up to n successive nodes holding values within
+// the search range together with their oids (see Tree.GetBulk).
+func (s Snapshot[T]) GetBulk(start, end []uint32, include bool, n int) (entries []Entry[T]) {
+	if n < 1 {
+		return nil
+	}
+	s.walk(start, end, include, func(id []uint32, node *Node[T]) bool {
+		entries = append(entries, Entry[T]{Oid: Clone(id), Node: node})
+		return len(entries) < n
+	})
+	return
+}
+
+// Walk calls fn for each value (see Tree.Walk).
+func (s Snapshot[T]) Walk(fn func(id []uint32, value T) bool) {
+	s.WalkRange(nil, nil, true, fn)
+}
+
+// WalkRange calls fn for each value within the search range (see
+// Tree.WalkRange).
+func (s Snapshot[T]) WalkRange(start, end []uint32, include bool, fn func(id []uint32, value T) bool) {
+	s.walk(start, end, include, func(id []uint32, node *Node[T]) bool {
+		return fn(id, node.Value)
+	})
+}
+
+// WalkPrefix calls fn for the value of prefix and the values of its
+// descendants (see Tree.WalkPrefix).
+func (s Snapshot[T]) WalkPrefix(prefix []uint32, fn func(id []uint32, value T) bool) {
+	node := s.root.getNode(prefix)
+	if node == nil {
+		return
+	}
+	w := &walker[T]{
+		id: append(make([]uint32, 0, len(prefix)+16), prefix...),
+		fn: func(id []uint32, node *Node[T]) bool {
+			return fn(id, node.Value)
+		},
+	}
+	w.visit(node, false)
+}
+
+func (s Snapshot[T]) walk(start, end []uint32, include bool, fn func(id []uint32, node *Node[T]) bool) {
+	w := &walker[T]{
+		id:      make([]uint32, 0, 32),
+		start:   start,
+		end:     end,
+		include: include,
+		fn:      fn,
+	}
+	w.visit(s.root, true)
+}
+
+// SnapshotTree is the copy-on-write variant of Tree. The readers
+// get the immutable snapshot of the tree without locking, so they
+// are never blocked by the writers. The writers copy the path to
+// the updated node (including the children slice of each node on
+// the path) and publish the new root atomically, so the update
+// costs O(depth) allocations of O(sum of fan-out on the path)
+// total size. E.g. the update of a table with 100k rows under
+// one node copies 100k pointers. The bulk updates are to be done
+// with Reload.
+type SnapshotTree[T any] struct {
+	// mu serializes the writers.
+	mu   sync.Mutex
+	root atomic.Value // *Node[T]
+}
+
+func NewSnapshotTree[T any]() *SnapshotTree[T] {
+	t := &SnapshotTree[T]{}
+	t.root.Store(&Node[T]{})
+	return t
+}
+
+// Snapshot returns the current snapshot of the tree.
+func (t *SnapshotTree[T]) Snapshot() Snapshot[T] {
+	return Snapshot[T]{t.root.Load().(*Node[T])}
+}
+
+// Get returns the value for oid in the current snapshot.
+func (t *SnapshotTree[T]) Get(id []uint32) (value T, ok bool) {
+	return t.Snapshot().Get(id)
+}
+
+// GetNext returns the first node within the search range in the
+// current snapshot (see Tree.GetNext).
+func (t *SnapshotTree[T]) GetNext(start, end []uint32, include bool) (id []uint32, node *Node[T]) {
+	return t.Snapshot().GetNext(start, end, include)
+}
+
+// Clear publishes the empty tree.
+func (t *SnapshotTree[T]) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root.Store(&Node[T]{})
+}
+
+// Insert publishes the tree with the value set for oid.
+func (t *SnapshotTree[T]) Insert(id []uint32, value T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root.Store(t.Snapshot().root.insertCopy(id, value))
+}
+
+// Delete publishes the tree without the value for oid (see
+// Tree.Delete). It returns false if there is no value for oid.
+func (t *SnapshotTree[T]) Delete(id []uint32) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	root, ok := t.Snapshot().root.deleteCopy(id)
+	if !ok {
+		return false
+	}
+	if root == nil {
+		root = &Node[T]{}
+	}
+	t.root.Store(root)
+	return true
+}
+
+// Reload publishes the tree built by fn. The readers keep using
+// the current snapshot while the tree is built. The tree must not
+// be used after fn returns.
+func (t *SnapshotTree[T]) Reload(fn func(tree *Tree[T])) {
+	tree := NewTree[T]()
+	fn(tree)
+	tree.root.clearParents()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root.Store(tree.root)
+}
+
+// insertCopy returns the copy of the node with the value set for
+// the relative oid. The nodes which are not on the path to oid
+// are shared with the node.
+func (node *Node[T]) insertCopy(id []uint32, value T) *Node[T] {
+	n := &Node[T]{Subid: node.Subid, Value: node.Value, hasValue: node.hasValue}
+	if len(id) == 0 {
+		n.Value, n.hasValue = value, true
+		n.Children = node.Children
+		return n
+	}
+	ind, ok := node.searchChild(id[0])
+	if ok {
+		n.Children = make([]*Node[T], len(node.Children))
+		copy(n.Children, node.Children)
+		n.Children[ind] = node.Children[ind].insertCopy(id[1:], value)
+	} else {
+		n.Children = make([]*Node[T], len(node.Children)+1)
+		copy(n.Children, node.Children[:ind])
+		copy(n.Children[ind+1:], node.Children[ind:])
+		n.Children[ind] = (&Node[T]{Subid: id[0]}).insertCopy(id[1:], value)
+	}
+	return n
+}
+
+// deleteCopy returns the copy of the node without the value for
+// the relative oid. The copy is nil if it neither holds a value
+// nor has children. It returns ok = false if there is no value
+// for oid.
+func (node *Node[T]) deleteCopy(id []uint32) (n *Node[T], ok bool) {
+	if len(id) == 0 {
+		if !node.hasValue {
+			return node, false
+		}
+		if len(node.Children) == 0 {
+			return nil, true
+		}
+		return &Node[T]{Subid: node.Subid, Children: node.Children}, true
+	}
+	ind, found := node.searchChild(id[0])
+	if !found {
+		return node, false
+	}
+	child, ok := node.Children[ind].deleteCopy(id[1:])
+	if !ok {
+		return node, false
+	}
+	if child == nil && !node.hasValue && len(node.Children) == 1 {
+		return nil, true
+	}
+	n = &Node[T]{Subid: node.Subid, Value: node.Value, hasValue: node.hasValue}
+	if child == nil {
+		n.Children = make([]*Node[T], 0, len(node.Children)-1)
+		n.Children = append(n.Children, node.Children[:ind]...)
+		n.Children = append(n.Children, node.Children[ind+1:]...)
+	} else {
+		n.Children = make([]*Node[T], len(node.Children))
+		copy(n.Children, node.Children)
+		n.Children[ind] = child
+	}
+	return n, true
+}
+
+// clearParents clears the parents of the node and its descendants.
+func (node *Node[T]) clearParents() {
+	node.Parent = nil
+	for _, child := range node.Children {
+		child.clearParents()
+	}
+}
@@ -0,0 +1,204 @@
+package oid
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func createTestSnapshotTree() *SnapshotTree[string] {
+	t := NewSnapshotTree[string]()
+	for _, s := range testTreeOids {
+		t.Insert(MustParse(s), s)
+	}
+	return t
+}
+
+func snapshotValues(s Snapshot[string]) string {
+	var res []string
+	s.Walk(func(id []uint32, value string) bool {
+		res = append(res, value)
+		return true
+	})
+	return strings.Join(res, " ")
+}
+
+func TestSnapshotTreeGetNext(t *testing.T) {
+	tree := createTestSnapshotTree()
+	for i, test := range testDataTreeGetNext {
+		id, node := tree.GetNext(MustParse(test.start), MustParse(test.end), test.include)
+		switch {
+		case test.res == "" && node != nil:
+			t.Errorf("TestSnapshotTreeGetNext[%d]: unexpected %s", i, String(id))
+		case test.res == "":
+		case node == nil:
+			t.Errorf("TestSnapshotTreeGetNext[%d]: no node", i)
+		case String(id) != test.res || node.Value != test.res:
+			t.Errorf("TestSnapshotTreeGetNext[%d]: %s (%v)", i, String(id), node.Value)
+		}
+	}
+	for i, test := range testDataTreeWalk {
+		var res []string
+		fn := func(id []uint32, value string) bool {
+			res = append(res, value)
+			return true
+		}
+		if test.prefix != "" {
+			tree.Snapshot().WalkPrefix(MustParse(test.prefix), fn)
+		} else {
+			tree.Snapshot().WalkRange(MustParse(test.start), MustParse(test.end), test.include, fn)
+		}
+		if strings.Join(res, " ") != strings.Join(test.res, " ") {
+			t.Errorf("TestSnapshotTreeWalk[%d]: %v", i, res)
+		}
+	}
+}
+
+func TestSnapshotTreeUpdate(t *testing.T) {
+	tree := createTestSnapshotTree()
+	all := strings.Join(testTreeOids, " ")
+	snapshot := tree.Snapshot()
+
+	tree.Insert(MustParse("1.3.6.1.2.1.1.2.0"), "new")
+	tree.Insert(MustParse("1.3.6.1.2.1.1.1.0"), "replaced")
+	if value, ok := tree.Get(MustParse("1.3.6.1.2.1.1.2.0")); !ok || value != "new" {
+		t.Errorf("TestSnapshotTreeUpdate: inserted %q, %v", value, ok)
+	}
+	if value, _ := tree.Get(MustParse("1.3.6.1.2.1.1.1.0")); value != "replaced" {
+		t.Errorf("TestSnapshotTreeUpdate: replaced %q", value)
+	}
+
+	// the descendants are kept, the path nodes are pruned
+	if !tree.Delete(MustParse("1.3.6.1.2.1.2")) || tree.Delete(MustParse("1.3.6.1.2.1.2")) {
+		t.Error("TestSnapshotTreeUpdate: Delete")
+	}
+	if tree.Delete(MustParse("1.3.6.1.2.1.2.2")) || tree.Delete(MustParse("1.3.6.1.4.1.9999.1")) {
+		t.Error("TestSnapshotTreeUpdate: deleted missing value")
+	}
+	for _, s := range testTreeOids[3:6] {
+		tree.Delete(MustParse(s))
+	}
+	if tree.Snapshot().Node(MustParse("1.3.6.1.2.1.2")) != nil || tree.Snapshot().Node(MustParse("1.3.6.1.2.1.1")) == nil {
+		t.Error("TestSnapshotTreeUpdate: path is not pruned")
+	}
+	res := snapshotValues(tree.Snapshot())
+	if res != "replaced new 1.3.6.1.2.1.1.3.0 1.3.6.1.4.1.9999" {
+		t.Errorf("TestSnapshotTreeUpdate: %s", res)
+	}
+
+	// the snapshot is not changed
+	if res := snapshotValues(snapshot); res != all {
+		t.Errorf("TestSnapshotTreeUpdate: snapshot %s", res)
+	}
+
+	tree.Reload(func(tree *Tree[string]) {
+		for _, s := range testTreeOids {
+			tree.Insert(MustParse(s), s)
+		}
+	})
+	if res := snapshotValues(tree.Snapshot()); res != all {
+		t.Errorf("TestSnapshotTreeUpdate: reloaded %s", res)
+	}
+	for _, s := range testTreeOids {
+		tree.Delete(MustParse(s))
+	}
+	if tree.Snapshot().Node(MustParse("1")) != nil {
+		t.Error("TestSnapshotTreeUpdate: tree is not empty")
+	}
+}
+
+// benchTree is the tree of GetNext benchmarks.
+type benchTree interface {
+	Insert(id []uint32, value int)
+	GetNext(start, end []uint32, include bool) ([]uint32, *Node[int])
+}
+
+type benchLockTree struct{ *Tree[int] }
+
+func (t benchLockTree) Insert(id []uint32, value int) { t.Tree.Insert(id, value) }
+
+const benchTreeRows = 10000
+
+func benchTreeOid(i int) []uint32 {
+	return []uint32{1, 3, 6, 1, 2, 1, 4, 21, 1, 1, 10, uint32(i >> 8), uint32(i & 0xff), 0, 1}
+}
+
+// benchGetNext runs GetNext in parallel while one goroutine
+// updates the tree if update is set.
+func benchGetNext(b *testing.B, tree benchTree, update bool) {
+	for i := 0; i < benchTreeRows; i++ {
+		tree.Insert(benchTreeOid(i), i)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	if update {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+					tree.Insert(benchTreeOid(i%benchTreeRows), i)
+				}
+			}
+		}()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			tree.GetNext(benchTreeOid(i%benchTreeRows), nil, false)
+			i += 7919
+		}
+	})
+	b.StopTimer()
+	close(done)
+	wg.Wait()
+}
+
+func BenchmarkTreeGetNext(b *testing.B) {
+	benchGetNext(b, benchLockTree{NewTree[int]()}, false)
+}
+
+func BenchmarkTreeGetNextUpdate(b *testing.B) {
+	benchGetNext(b, benchLockTree{NewTree[int]()}, true)
+}
+
+func BenchmarkSnapshotTreeGetNext(b *testing.B) {
+	benchGetNext(b, NewSnapshotTree[int](), false)
+}
+
+func BenchmarkSnapshotTreeGetNextUpdate(b *testing.B) {
+	benchGetNext(b, NewSnapshotTree[int](), true)
+}
+
+// benchInsertWide updates the values of the node with n children.
+func benchInsertWide(b *testing.B, tree benchTree, n int) {
+	prefix := []uint32{1, 3, 6, 1, 4, 1, 999, 1}
+	fill := func(t benchTree) {
+		for i := 0; i < n; i++ {
+			t.Insert(Cat(prefix, uint32(i)), i)
+		}
+	}
+	if st, ok := tree.(*SnapshotTree[int]); ok {
+		st.Reload(func(t *Tree[int]) { fill(benchLockTree{t}) })
+	} else {
+		fill(tree)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Insert(Cat(prefix, uint32(i%n)), i)
+	}
+}
+
+func BenchmarkTreeInsertWide(b *testing.B) {
+	benchInsertWide(b, benchLockTree{NewTree[int]()}, 100000)
+}
+
+func BenchmarkSnapshotTreeInsertWide(b *testing.B) {
+	benchInsertWide(b, NewSnapshotTree[int](), 100000)
+}
@@ -1,50 +1,158 @@
 package oid
 
-// !!! Status: in progress (draft, not tested, to be continued)
-
 import "sync"
 
-type Tree struct {
+// Tree maps oids to values of type T. The values are stored in the
+// nodes of the tree, so the lookups of oid prefixes and the walks
+// in the lexicographical order of oids are cheap. Tree can be used
+// concurrently.
+//
+// The walk functions are called with the tree locked for reading,
+// so they must not modify the tree. The oid passed to the walk
+// function is only valid during the call (use Clone to keep it).
+type Tree[T any] struct {
 	lock sync.RWMutex
-	root *Node
+	root *Node[T]
+}
+
+// Map is the access to the values of the oid trees. It is
+// implemented by Tree and RadixTree, so the code which only gets,
+// deletes and walks the values can use either of them. Insert,
+// GetNext and GetBulk differ (see RadixTree) and are not in Map.
+type Map[T any] interface {
+	Get(id []uint32) (value T, ok bool)
+	Delete(id []uint32) bool
+	Clear()
+	Walk(fn func(id []uint32, value T) bool)
+	WalkRange(start, end []uint32, include bool, fn func(id []uint32, value T) bool)
+	WalkPrefix(prefix []uint32, fn func(id []uint32, value T) bool)
 }
 
-func NewTree() *Tree {
-	return &Tree{root: &Node{}}
+// Entry is the node of the tree together with its oid.
+type Entry[T any] struct {
+	Oid  []uint32
+	Node *Node[T]
 }
 
-func (t *Tree) Clear() {
+func NewTree[T any]() *Tree[T] {
+	return &Tree[T]{root: &Node[T]{}}
+}
+
+// Clear removes all values of the tree.
+func (t *Tree[T]) Clear() {
 	t.lock.Lock()
 	defer t.lock.Unlock()
-	t.root.Children = nil
+	t.root = &Node[T]{}
 }
 
-func (t *Tree) AddNode(oid []uint32) *Node {
+// Insert sets the value for oid and returns the node holding
+// the value. The previous value (if any) is replaced.
+func (t *Tree[T]) Insert(id []uint32, value T) *Node[T] {
 	t.lock.Lock()
 	defer t.lock.Unlock()
-	return t.root.addNode(oid)
+	node := t.root.addNode(id)
+	node.Value, node.hasValue = value, true
+	return node
+}
+
+// Get returns the value for oid. It returns ok = false if there
+// is no value for oid.
+func (t *Tree[T]) Get(id []uint32) (value T, ok bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if node := t.root.getNode(id); node != nil && node.hasValue {
+		return node.Value, true
+	}
+	return
 }
 
-func (t *Tree) GetNode(oid []uint32) *Node {
+// Node returns the node for oid, either holding a value or leading
+// to the descendants holding values. If there is no such node, it
+// returns nil.
+func (t *Tree[T]) Node(id []uint32) *Node[T] {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
-	return t.root.getNode(oid)
+	return t.root.getNode(id)
 }
 
-func (t *Tree) GetNextNode(oid1, oid2 []uint32, include1 bool) *Node {
+// LongestPrefix returns the value of the longest prefix of oid
+// which has a value. The prefix may be oid itself. It returns
+// n = 0 and ok = false if there is no such prefix.
+func (t *Tree[T]) LongestPrefix(id []uint32) (n int, value T, ok bool) {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
-	return t.root.getNextNode(oid1, oid2, include1, 1)
+	node := t.root
+	for i := 0; ; i++ {
+		if node.hasValue {
+			n, value, ok = i, node.Value, true
+		}
+		if i == len(id) {
+			return
+		}
+		if node = node.getChild(id[i]); node == nil {
+			return
+		}
+	}
 }
 
-func (t *Tree) RemoveNode(oid []uint32) bool {
+// Delete removes the value for oid, the values of the descendant
+// oids are kept. The nodes which no longer lead to values are
+// removed. It returns false if there is no value for oid.
+func (t *Tree[T]) Delete(id []uint32) bool {
 	t.lock.Lock()
 	defer t.lock.Unlock()
-	return t.root.removeNode(oid, false)
+	node := t.root.getNode(id)
+	if node == nil || !node.hasValue {
+		return false
+	}
+	var zero T
+	node.Value, node.hasValue = zero, false
+	node.prune()
+	return true
+}
+
+// GetNext returns the first node holding a value within the search
+// range (RFC 2741, 5.2) together with its oid:
+//
+//	start <= oid < end  if include == true
+//	start <  oid < end  if include == false
+//
+// The empty end means no upper bound. If there is no such node,
+// it returns nil.
+func (t *Tree[T]) GetNext(start, end []uint32, include bool) (id []uint32, node *Node[T]) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return Snapshot[T]{t.root}.GetNext(start, end, include)
+}
+
+// GetBulk returns up to n successive nodes holding values within
+// the search range (see GetNext) together with their oids.
+func (t *Tree[T]) GetBulk(start, end []uint32, include bool, n int) []Entry[T] {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return Snapshot[T]{t.root}.GetBulk(start, end, include, n)
+}
+
+// Walk calls fn for each value in the lexicographical order of
+// oids until fn returns false.
+func (t *Tree[T]) Walk(fn func(id []uint32, value T) bool) {
+	t.WalkRange(nil, nil, true, fn)
+}
+
+// WalkRange calls fn for each value within the search range (see
+// GetNext) in the lexicographical order of oids until fn returns
+// false.
+func (t *Tree[T]) WalkRange(start, end []uint32, include bool, fn func(id []uint32, value T) bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	Snapshot[T]{t.root}.WalkRange(start, end, include, fn)
 }
 
-func (t *Tree) ForRangeLeaves(do func([]uint32, *Node)) {
+// WalkPrefix calls fn for the value of prefix and the values of
+// its descendants in the lexicographical order of oids until fn
+// returns false.
+func (t *Tree[T]) WalkPrefix(prefix []uint32, fn func(id []uint32, value T) bool) {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
-	t.root.forRangeLeaves(do)
+	Snapshot[T]{t.root}.WalkPrefix(prefix, fn)
 }
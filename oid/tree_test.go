@@ -1,21 +1,324 @@
 package oid
 
 import (
-	"fmt"
+	"strings"
 	"testing"
 )
 
-func createTestTree() (t *Tree) {
-	t = NewTree()
-	t.AddNode([]uint32{1, 3, 6, 1, 4, 1, 9999, 1})
-	t.AddNode([]uint32{1, 3, 6, 1, 4, 1, 9999, 2})
-	t.AddNode([]uint32{1, 3, 6, 1, 4, 1, 9999, 3})
-	return
+var testTreeOids = []string{
+	"1.3.6.1.2.1.1.1.0",
+	"1.3.6.1.2.1.1.3.0",
+	"1.3.6.1.2.1.2",
+	"1.3.6.1.2.1.2.1.0",
+	"1.3.6.1.2.1.2.2.1.1.1",
+	"1.3.6.1.2.1.2.2.1.1.2",
+	"1.3.6.1.4.1.9999",
 }
 
-func TestForRangeLeaves(t *testing.T) {
+// createTestTree returns the tree with the values of testTreeOids
+// (the value is the oid string).
+func createTestTree() *Tree[string] {
+	t := NewTree[string]()
+	for _, s := range testTreeOids {
+		t.Insert(MustParse(s), s)
+	}
+	return t
+}
+
+func TestTreeGet(t *testing.T) {
+	tree := createTestTree()
+	for i, s := range testTreeOids {
+		if data, ok := tree.Get(MustParse(s)); !ok || data != s {
+			t.Errorf("TestTreeGet[%d]: %v, %v", i, data, ok)
+		}
+		if node := tree.Node(MustParse(s)); node == nil || String(node.Oid()) != s {
+			t.Errorf("TestTreeGet[%d]: node %v", i, node)
+		}
+	}
+	for _, s := range []string{"", "1.3.6.1", "1.3.6.1.2.1.2.2", "1.3.6.1.2.1.1.1.0.1", "2"} {
+		if data, ok := tree.Get(MustParse(s)); ok {
+			t.Errorf("TestTreeGet: %q: %v", s, data)
+		}
+	}
+	if node := tree.Node(MustParse("1.3.6.1.2.1.2.2")); node == nil || node.HasValue() {
+		t.Errorf("TestTreeGet: path node %v", node)
+	}
+
+	// replace the value
+	node := tree.Insert(MustParse("1.3.6.1.2.1.2"), "")
+	if data, ok := tree.Get(MustParse("1.3.6.1.2.1.2")); !ok || data != "" || !node.HasValue() {
+		t.Errorf("TestTreeGet: replaced value %v, %v", data, ok)
+	}
+}
+
+var testDataTreeGetNext = []struct {
+	start   string
+	end     string
+	include bool
+	res     string
+}{
+	{start: "", res: "1.3.6.1.2.1.1.1.0"},
+	{start: "", include: true, res: "1.3.6.1.2.1.1.1.0"},
+	{start: "1.3.6.1.2.1.1.1.0", include: true, res: "1.3.6.1.2.1.1.1.0"},
+	{start: "1.3.6.1.2.1.1.1.0", res: "1.3.6.1.2.1.1.3.0"},
+	// start is not in the tree
+	{start: "1.3.6.1.2.1.1.2", res: "1.3.6.1.2.1.1.3.0"},
+	{start: "1.3.6.1.2.1.1.2", include: true, res: "1.3.6.1.2.1.1.3.0"},
+	{start: "1.3.6.1.2.1.1.1.0.1", res: "1.3.6.1.2.1.1.3.0"},
+	{start: "1.3.6.1.2.1.2.2.1.1", include: true, res: "1.3.6.1.2.1.2.2.1.1.1"},
+	{start: "1.3.6.1.3", res: "1.3.6.1.4.1.9999"},
+	{start: "0.1", res: "1.3.6.1.2.1.1.1.0"},
+	// the value of the node with descendants
+	{start: "1.3.6.1.2.1.1.3.0", res: "1.3.6.1.2.1.2"},
+	{start: "1.3.6.1.2.1.2", include: true, res: "1.3.6.1.2.1.2"},
+	{start: "1.3.6.1.2.1.2", res: "1.3.6.1.2.1.2.1.0"},
+	{start: "1.3.6.1.2.1.2.1.0.5", res: "1.3.6.1.2.1.2.2.1.1.1"},
+	// the end of the tree
+	{start: "1.3.6.1.4.1.9999", res: ""},
+	{start: "1.3.6.1.4.1.9999", include: true, res: "1.3.6.1.4.1.9999"},
+	{start: "1.3.6.1.4.1.9999.1", include: true, res: ""},
+	{start: "2", res: ""},
+	// the end of the search range
+	{start: "", end: "1.3.6.1.2.1.1.1.0", res: ""},
+	{start: "1.3.6.1.2.1.1.1.0", end: "1.3.6.1.2.1.1.1.0", include: true, res: ""},
+	{start: "1.3.6.1.2.1.1.1.0", end: "1.3.6.1.2.1.1.3.0", res: ""},
+	{start: "1.3.6.1.2.1.1.1.0", end: "1.3.6.1.2.1.1.3.0.0", res: "1.3.6.1.2.1.1.3.0"},
+	{start: "1.3.6.1.2.1.1.3.0", end: "1.3.6.1.2.1.2", res: ""},
+	{start: "1.3.6.1.2.1.1.3.0", end: "1.3.6.1.2.1.2.0", res: "1.3.6.1.2.1.2"},
+	{start: "1.3.6.1.2.1.2", end: "1.3.6.1.2.1.3", res: "1.3.6.1.2.1.2.1.0"},
+	{start: "1.3.6.1.2.1.2.2.1.1.2", end: "1.3.6.1.4.1.9999", res: ""},
+	{start: "1.3.6.1.2.1.2.2.1.1.2", end: "1.3.6.1.5", res: "1.3.6.1.4.1.9999"},
+}
+
+func TestTreeGetNext(t *testing.T) {
 	tree := createTestTree()
-	tree.ForRangeLeaves(func(oid []uint32, node *Node) {
-		fmt.Println(String(oid))
+	for i, test := range testDataTreeGetNext {
+		id, node := tree.GetNext(MustParse(test.start), MustParse(test.end), test.include)
+		switch {
+		case test.res == "" && node != nil:
+			t.Errorf("TestTreeGetNext[%d]: unexpected %s", i, String(id))
+		case test.res == "":
+		case node == nil:
+			t.Errorf("TestTreeGetNext[%d]: no node", i)
+		case String(id) != test.res || node.Value != test.res:
+			t.Errorf("TestTreeGetNext[%d]: %s (%v)", i, String(id), node.Value)
+		}
+	}
+}
+
+var testDataTreeGetBulk = []struct {
+	start   string
+	end     string
+	include bool
+	n       int
+	res     []string
+}{
+	{start: "", n: 0, res: nil},
+	{start: "", n: 1, res: testTreeOids[:1]},
+	{start: "", n: 3, res: testTreeOids[:3]},
+	{start: "", n: 100, res: testTreeOids},
+	{start: "1.3.6.1.2.1.2", n: 2, res: testTreeOids[3:5]},
+	{start: "1.3.6.1.2.1.2", include: true, n: 2, res: testTreeOids[2:4]},
+	{start: "1.3.6.1.2.1.2", end: "1.3.6.1.2.1.3", n: 10, res: testTreeOids[3:6]},
+	{start: "1.3.6.1.4.1.9999", n: 10, res: nil},
+}
+
+func TestTreeGetBulk(t *testing.T) {
+	tree := createTestTree()
+	for i, test := range testDataTreeGetBulk {
+		entries := tree.GetBulk(MustParse(test.start), MustParse(test.end), test.include, test.n)
+		var res []string
+		for _, e := range entries {
+			if e.Node.Value != String(e.Oid) {
+				t.Errorf("TestTreeGetBulk[%d]: %s (%v)", i, String(e.Oid), e.Node.Value)
+			}
+			res = append(res, String(e.Oid))
+		}
+		if strings.Join(res, " ") != strings.Join(test.res, " ") {
+			t.Errorf("TestTreeGetBulk[%d]: %v", i, res)
+		}
+	}
+}
+
+func TestTreeDelete(t *testing.T) {
+	tree := createTestTree()
+	for _, s := range []string{"", "1.3.6.1", "1.3.6.1.2.1.2.2", "1.3.6.1.2.1.1.1.0.1"} {
+		if tree.Delete(MustParse(s)) {
+			t.Errorf("TestTreeDelete: deleted %q", s)
+		}
+	}
+
+	// the descendants are kept
+	if !tree.Delete(MustParse("1.3.6.1.2.1.2")) {
+		t.Fatal("TestTreeDelete: not deleted")
+	}
+	if _, ok := tree.Get(MustParse("1.3.6.1.2.1.2")); ok {
+		t.Error("TestTreeDelete: value is not deleted")
+	}
+	if _, ok := tree.Get(MustParse("1.3.6.1.2.1.2.1.0")); !ok {
+		t.Error("TestTreeDelete: descendant is deleted")
+	}
+	if tree.Delete(MustParse("1.3.6.1.2.1.2")) {
+		t.Error("TestTreeDelete: deleted twice")
+	}
+
+	// the path nodes are pruned
+	tree.Delete(MustParse("1.3.6.1.2.1.2.2.1.1.1"))
+	if tree.Node(MustParse("1.3.6.1.2.1.2.2.1.1")) == nil {
+		t.Error("TestTreeDelete: pruned node leading to value")
+	}
+	tree.Delete(MustParse("1.3.6.1.2.1.2.2.1.1.2"))
+	if tree.Node(MustParse("1.3.6.1.2.1.2.2")) != nil {
+		t.Error("TestTreeDelete: path is not pruned")
+	}
+	tree.Delete(MustParse("1.3.6.1.2.1.2.1.0"))
+	if tree.Node(MustParse("1.3.6.1.2.1.2")) != nil || tree.Node(MustParse("1.3.6.1.2.1")) == nil {
+		t.Error("TestTreeDelete: path is not pruned")
+	}
+
+	var res []string
+	tree.Walk(func(id []uint32, value string) bool {
+		res = append(res, String(id))
+		return true
 	})
+	if strings.Join(res, " ") != "1.3.6.1.2.1.1.1.0 1.3.6.1.2.1.1.3.0 1.3.6.1.4.1.9999" {
+		t.Errorf("TestTreeDelete: %v", res)
+	}
+
+	for _, s := range res {
+		tree.Delete(MustParse(s))
+	}
+	if tree.Node(MustParse("1")) != nil {
+		t.Error("TestTreeDelete: tree is not empty")
+	}
+
+	tree = createTestTree()
+	tree.Clear()
+	if id, node := tree.GetNext(nil, nil, true); node != nil {
+		t.Errorf("TestTreeDelete: cleared tree has %s", String(id))
+	}
+}
+
+var testDataTreeWalk = []struct {
+	start   string
+	end     string
+	include bool
+	prefix  string
+	res     []string
+}{
+	{res: testTreeOids},
+	{start: "1.3.6.1.2.1.1.3.0", res: testTreeOids[2:]},
+	{start: "1.3.6.1.2.1.1.3.0", include: true, end: "1.3.6.1.2.1.2.2", res: testTreeOids[1:4]},
+	{start: "1.3.6.1.4.1.9999", res: nil},
+	{prefix: "1.3.6.1.2.1.2", res: testTreeOids[2:6]},
+	{prefix: "1.3.6.1.2.1.2.2", res: testTreeOids[4:6]},
+	{prefix: "1.3.6.1.4.1.9999", res: testTreeOids[6:]},
+	{prefix: "1.3.6.1.4.1.9999.1", res: nil},
+	{prefix: "1.3.6.1.3", res: nil},
+}
+
+func TestTreeWalk(t *testing.T) {
+	tree := createTestTree()
+	for i, test := range testDataTreeWalk {
+		var res []string
+		fn := func(id []uint32, value string) bool {
+			if String(id) != value {
+				t.Errorf("TestTreeWalk[%d]: %s (%s)", i, String(id), value)
+			}
+			res = append(res, value)
+			return true
+		}
+		if test.prefix != "" {
+			tree.WalkPrefix(MustParse(test.prefix), fn)
+		} else {
+			tree.WalkRange(MustParse(test.start), MustParse(test.end), test.include, fn)
+		}
+		if strings.Join(res, " ") != strings.Join(test.res, " ") {
+			t.Errorf("TestTreeWalk[%d]: %v", i, res)
+		}
+	}
+
+	// stop the walk
+	n := 0
+	tree.Walk(func(id []uint32, value string) bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Errorf("TestTreeWalk: %d values visited", n)
+	}
+}
+
+func BenchmarkTreeWalk(b *testing.B) {
+	tree := NewTree[int]()
+	for i := 0; i < 10000; i++ {
+		tree.Insert([]uint32{1, 3, 6, 1, 2, 1, 4, 20, 1, 1, uint32(i / 256), uint32(i % 256)}, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Walk(func(id []uint32, value int) bool {
+			return true
+		})
+	}
+}
+
+var testDataTreeLongestPrefix = []struct {
+	id  string
+	n   int
+	res string
+}{
+	{id: "1.3.6.1.2.1.1.1.0", n: 9, res: "1.3.6.1.2.1.1.1.0"},
+	{id: "1.3.6.1.2.1.1.1.0.5.6", n: 9, res: "1.3.6.1.2.1.1.1.0"},
+	{id: "1.3.6.1.2.1.2.2.1.1.3", n: 7, res: "1.3.6.1.2.1.2"},
+	{id: "1.3.6.1.2.1.2.1.0", n: 9, res: "1.3.6.1.2.1.2.1.0"},
+	{id: "1.3.6.1.2.1.1", n: 0},
+	{id: "", n: 0},
+}
+
+func TestTreeLongestPrefix(t *testing.T) {
+	tree := createTestTree()
+	for i, test := range testDataTreeLongestPrefix {
+		n, value, ok := tree.LongestPrefix(MustParse(test.id))
+		if n != test.n || value != test.res || ok != (test.res != "") {
+			t.Errorf("TestTreeLongestPrefix[%d]: %d, %s, %v", i, n, value, ok)
+		}
+	}
+}
+
+// TestMap tests the trees through the common interface.
+func TestMap(t *testing.T) {
+	for name, m := range map[string]Map[string]{
+		"Tree":      createTestTree(),
+		"RadixTree": createTestRadixTree(),
+	} {
+		for i, s := range testTreeOids {
+			if value, ok := m.Get(MustParse(s)); !ok || value != s {
+				t.Errorf("TestMap: %s: Get[%d]: %v, %v", name, i, value, ok)
+			}
+		}
+		for i, test := range testDataTreeWalk {
+			var res []string
+			fn := func(id []uint32, value string) bool {
+				res = append(res, value)
+				return true
+			}
+			if test.prefix != "" {
+				m.WalkPrefix(MustParse(test.prefix), fn)
+			} else {
+				m.WalkRange(MustParse(test.start), MustParse(test.end), test.include, fn)
+			}
+			if strings.Join(res, " ") != strings.Join(test.res, " ") {
+				t.Errorf("TestMap: %s: Walk[%d]: %v", name, i, res)
+			}
+		}
+		if !m.Delete(MustParse(testTreeOids[0])) || m.Delete(MustParse(testTreeOids[0])) {
+			t.Errorf("TestMap: %s: Delete", name)
+		}
+		m.Clear()
+		m.Walk(func(id []uint32, value string) bool {
+			t.Errorf("TestMap: %s: %s after Clear", name, String(id))
+			return true
+		})
+	}
 }
@@ -0,0 +1,55 @@
+package oid
+
+// OID is the object identifier. OID can be passed wherever the
+// oid is []uint32, and the functions of the package apply to it.
+type OID []uint32
+
+// String returns oid string representation (see String).
+func (id OID) String() string {
+	return String(id)
+}
+
+// Compare compares oids lexicographically (see Compare).
+func (id OID) Compare(other []uint32) int {
+	return Compare(id, other)
+}
+
+// Equal tests whether oids are equal.
+func (id OID) Equal(other []uint32) bool {
+	return Compare(id, other) == 0
+}
+
+// HasPrefix tests whether oid begins with prefix.
+func (id OID) HasPrefix(prefix []uint32) bool {
+	return HasPrefix(id, prefix...)
+}
+
+// Append returns new oid with the subids appended.
+func (id OID) Append(subids ...uint32) OID {
+	return Cat(id, subids...)
+}
+
+// Parent returns the oid without the last subid. The parent of
+// the empty oid is nil.
+func (id OID) Parent() OID {
+	if len(id) == 0 {
+		return nil
+	}
+	return id[: len(id)-1 : len(id)-1]
+}
+
+// MarshalText implements encoding.TextMarshaler, so the oid is
+// encoded as string (e.g. in JSON).
+func (id OID) MarshalText() ([]byte, error) {
+	return []byte(String(id)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *OID) UnmarshalText(text []byte) error {
+	res, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*id = res
+	return nil
+}
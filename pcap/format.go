@@ -0,0 +1,309 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"time"
+)
+
+// frame is a captured link layer frame.
+type frame struct {
+	time     time.Time
+	linkType uint32
+	data     []byte
+}
+
+type frameReader interface {
+	next() (frame, error)
+}
+
+const (
+	pcapMagicMicro = 0xA1B2C3D4
+	pcapMagicNano  = 0xA1B23C4D
+	pcapngMagicSHB = 0x0A0D0D0A
+	pcapngMagicBO  = 0x1A2B3C4D
+
+	// maxBlockSize limits the size of a record/block
+	// in order to reject garbage length fields.
+	maxBlockSize = 1 << 24
+)
+
+// newFrameReader detects file format by the magic number.
+func newFrameReader(r io.Reader) (frameReader, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, ErrFormat
+	}
+	switch {
+	case binary.BigEndian.Uint32(magic[:]) == pcapngMagicSHB:
+		return newPcapngReader(r)
+	case binary.LittleEndian.Uint32(magic[:]) == pcapMagicMicro:
+		return newPcapReader(r, binary.LittleEndian, time.Microsecond)
+	case binary.BigEndian.Uint32(magic[:]) == pcapMagicMicro:
+		return newPcapReader(r, binary.BigEndian, time.Microsecond)
+	case binary.LittleEndian.Uint32(magic[:]) == pcapMagicNano:
+		return newPcapReader(r, binary.LittleEndian, time.Nanosecond)
+	case binary.BigEndian.Uint32(magic[:]) == pcapMagicNano:
+		return newPcapReader(r, binary.BigEndian, time.Nanosecond)
+	}
+	return nil, ErrFormat
+}
+
+// readFull is io.ReadFull which reports truncated data
+// as ErrMalformed.
+func readFull(r io.Reader, buf []byte) error {
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return ErrMalformed
+		}
+		return err
+	}
+	return nil
+}
+
+// pcapReader reads classic libpcap file format.
+type pcapReader struct {
+	r        io.Reader
+	order    binary.ByteOrder
+	tsunit   time.Duration
+	linkType uint32
+}
+
+func newPcapReader(r io.Reader, order binary.ByteOrder, tsunit time.Duration) (*pcapReader, error) {
+	// the rest of the global header (magic is already read):
+	// version major/minor, thiszone, sigfigs, snaplen, network
+	var hdr [20]byte
+	if err := readFull(r, hdr[:]); err != nil {
+		return nil, ErrMalformed
+	}
+	return &pcapReader{
+		r:        r,
+		order:    order,
+		tsunit:   tsunit,
+		linkType: order.Uint32(hdr[16:]) & 0x0FFFFFFF,
+	}, nil
+}
+
+func (p *pcapReader) next() (f frame, err error) {
+	var hdr [16]byte
+	if err = readFull(p.r, hdr[:]); err != nil {
+		return
+	}
+	sec := p.order.Uint32(hdr[0:])
+	frac := p.order.Uint32(hdr[4:])
+	size := p.order.Uint32(hdr[8:])
+	if size > maxBlockSize {
+		err = ErrMalformed
+		return
+	}
+	f.data = make([]byte, size)
+	if err = readFull(p.r, f.data); err != nil {
+		if err == io.EOF {
+			err = ErrMalformed
+		}
+		return
+	}
+	f.time = time.Unix(int64(sec), int64(frac)*int64(p.tsunit)).UTC()
+	f.linkType = p.linkType
+	return
+}
+
+// pcapngInterface holds parameters of the pcapng
+// Interface Description Block.
+type pcapngInterface struct {
+	linkType uint32
+	// tsunits is the number of timestamp units per second.
+	tsunits uint64
+}
+
+// pcapngReader reads pcapng file format.
+type pcapngReader struct {
+	r          io.Reader
+	order      binary.ByteOrder
+	interfaces []pcapngInterface
+}
+
+const (
+	pcapngBlockIDB = 1
+	pcapngBlockOPB = 2
+	pcapngBlockSPB = 3
+	pcapngBlockEPB = 6
+
+	pcapngOptEnd     = 0
+	pcapngOptTsresol = 9
+)
+
+func newPcapngReader(r io.Reader) (*pcapngReader, error) {
+	p := &pcapngReader{r: r}
+	// the magic of the first section header block is already read
+	if err := p.readSectionHeader(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// readSectionHeader reads the rest of Section Header Block
+// following its block type.
+func (p *pcapngReader) readSectionHeader() error {
+	var hdr [8]byte // block total length, byte-order magic
+	if err := readFull(p.r, hdr[:]); err != nil {
+		return ErrMalformed
+	}
+	switch {
+	case binary.BigEndian.Uint32(hdr[4:]) == pcapngMagicBO:
+		p.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(hdr[4:]) == pcapngMagicBO:
+		p.order = binary.LittleEndian
+	default:
+		return ErrMalformed
+	}
+	size := p.order.Uint32(hdr[:])
+	if size < 28 || size > maxBlockSize || size&0x03 != 0 {
+		return ErrMalformed
+	}
+	// skip version, section length, options and trailing total length
+	if err := readFull(p.r, make([]byte, size-12)); err != nil {
+		return ErrMalformed
+	}
+	// interface ids are local to a section
+	p.interfaces = p.interfaces[:0]
+	return nil
+}
+
+func (p *pcapngReader) next() (f frame, err error) {
+	for {
+		var hdr [4]byte
+		if err = readFull(p.r, hdr[:]); err != nil {
+			return
+		}
+		if binary.BigEndian.Uint32(hdr[:]) == pcapngMagicSHB {
+			if err = p.readSectionHeader(); err != nil {
+				return
+			}
+			continue
+		}
+		blockType := p.order.Uint32(hdr[:])
+		if err = readFull(p.r, hdr[:]); err != nil {
+			return f, ErrMalformed
+		}
+		size := p.order.Uint32(hdr[:])
+		if size < 12 || size > maxBlockSize || size&0x03 != 0 {
+			return f, ErrMalformed
+		}
+		body := make([]byte, size-12)
+		if err = readFull(p.r, body); err != nil {
+			return f, ErrMalformed
+		}
+		if err = readFull(p.r, hdr[:]); err != nil {
+			return f, ErrMalformed
+		}
+		var ok bool
+		if f, ok, err = p.parseBlock(blockType, body); err != nil || ok {
+			return
+		}
+	}
+}
+
+// parseBlock parses the block body. It returns ok = true
+// if the block contains a frame.
+func (p *pcapngReader) parseBlock(blockType uint32, body []byte) (f frame, ok bool, err error) {
+	switch blockType {
+	case pcapngBlockIDB:
+		err = p.parseInterface(body)
+
+	case pcapngBlockEPB:
+		if len(body) < 20 {
+			return f, false, ErrMalformed
+		}
+		f, err = p.packet(p.order.Uint32(body[0:]),
+			p.order.Uint32(body[4:]), p.order.Uint32(body[8:]),
+			p.order.Uint32(body[12:]), body[20:])
+		ok = err == nil
+
+	case pcapngBlockOPB:
+		if len(body) < 20 {
+			return f, false, ErrMalformed
+		}
+		f, err = p.packet(uint32(p.order.Uint16(body[0:])),
+			p.order.Uint32(body[4:]), p.order.Uint32(body[8:]),
+			p.order.Uint32(body[12:]), body[20:])
+		ok = err == nil
+
+	case pcapngBlockSPB:
+		// simple packet block has no timestamp and
+		// refers to the first interface
+		if len(body) < 4 || len(p.interfaces) == 0 {
+			return f, false, ErrMalformed
+		}
+		f.linkType = p.interfaces[0].linkType
+		f.data = body[4:]
+		if size := p.order.Uint32(body[0:]); int(size) < len(f.data) {
+			f.data = f.data[:size]
+		}
+		ok = true
+	}
+	return
+}
+
+func (p *pcapngReader) parseInterface(body []byte) error {
+	if len(body) < 8 {
+		return ErrMalformed
+	}
+	ifc := pcapngInterface{
+		linkType: uint32(p.order.Uint16(body[0:])),
+		tsunits:  1000000,
+	}
+	opts := body[8:]
+	for len(opts) >= 4 {
+		code := p.order.Uint16(opts[0:])
+		size := int(p.order.Uint16(opts[2:]))
+		opts = opts[4:]
+		if code == pcapngOptEnd || size > len(opts) {
+			break
+		}
+		if code == pcapngOptTsresol && size == 1 {
+			ifc.tsunits = tsresolUnits(opts[0])
+		}
+		if size = align4(size); size > len(opts) {
+			break
+		}
+		opts = opts[size:]
+	}
+	p.interfaces = append(p.interfaces, ifc)
+	return nil
+}
+
+// tsresolUnits returns the number of timestamp units per
+// second defined by the if_tsresol option value.
+func tsresolUnits(v byte) (units uint64) {
+	units = 1
+	if v&0x80 == 0 {
+		for i := byte(0); i < v && units <= 1e18; i++ {
+			units *= 10
+		}
+	} else if v &= 0x7F; v < 64 {
+		units <<= v
+	}
+	return
+}
+
+func (p *pcapngReader) packet(ifid, tshigh, tslow, caplen uint32, data []byte) (f frame, err error) {
+	if int(ifid) >= len(p.interfaces) || int(caplen) > len(data) {
+		return f, ErrMalformed
+	}
+	ifc := p.interfaces[ifid]
+	ts := uint64(tshigh)<<32 | uint64(tslow)
+	sec, frac := ts/ifc.tsunits, ts%ifc.tsunits
+	hi, lo := bits.Mul64(frac, 1000000000)
+	nsec, _ := bits.Div64(hi, lo, ifc.tsunits)
+	f.time = time.Unix(int64(sec), int64(nsec)).UTC()
+	f.linkType = ifc.linkType
+	f.data = data[:caplen]
+	return
+}
+
+// align4 rounds n up to a multiple of 4.
+func align4(n int) int {
+	return (n + 3) &^ 3
+}
@@ -0,0 +1,214 @@
+/*
+Package pcap provides an offline reader which extracts Agentx PDU
+from capture files (e.g. made by tcpdump or wireshark). Both pcap
+and pcapng file formats are supported.
+
+The reader reassembles TCP streams to/from the Agentx port (705 by
+default) and yields decoded PDU together with the capture timestamp
+and the direction of transfer:
+
+	f, _ := os.Open("agentx.pcap")
+	r, err := pcap.NewReader(f, logger.Console())
+	if err != nil {
+		...
+	}
+	for {
+		p, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		...
+		fmt.Println(p.Time, p.Direction, p.Pdu.String(1))
+	}
+
+PDU which can not be decoded are skipped. The reason is written to
+the log by means of agentx.DecodePduHeaderDbg/DecodePduPayloadDbg.
+Frames which are not recognized (e.g. non-TCP or fragmented IP
+frames) are skipped silently.
+*/
+package pcap
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/alexispb/mygosnmp/agentx"
+	"github.com/alexispb/mygosnmp/logger"
+)
+
+// Port is the well-known Agentx TCP port.
+const Port = 705
+
+var (
+	ErrFormat    = errors.New("pcap: unknown file format")
+	ErrMalformed = errors.New("pcap: malformed file")
+)
+
+type Direction byte
+
+const (
+	// ToMaster means PDU is sent by subagent to master agent.
+	ToMaster Direction = 1
+	// ToSubagent means PDU is sent by master agent to subagent.
+	ToSubagent Direction = 2
+)
+
+// String returns Direction string representation.
+func (d Direction) String() string {
+	switch d {
+	case ToMaster:
+		return "ToMaster"
+	case ToSubagent:
+		return "ToSubagent"
+	default:
+		return "?"
+	}
+}
+
+// Packet is a decoded Agentx PDU extracted from capture.
+type Packet struct {
+	// Time is the timestamp of the frame which completes the PDU.
+	Time      time.Time
+	Direction Direction
+	// Src and Dst are the TCP endpoints in form "ip:port".
+	Src string
+	Dst string
+	Pdu agentx.Pdu
+}
+
+// Reader reads Agentx PDU from a capture file.
+type Reader struct {
+	// Port is the master agent TCP port (705 by default).
+	// It can be changed before the first call to Next.
+	Port uint16
+
+	frames  frameReader
+	log     logger.Log
+	streams map[flowKey]*stream
+	queue   []Packet
+}
+
+// NewReader reads the file header and returns Reader which
+// extracts Agentx PDU from the capture. The file format (pcap
+// or pcapng) is detected automatically. If log is nil, PDU
+// decoding errors are not logged.
+func NewReader(r io.Reader, log logger.Log) (*Reader, error) {
+	frames, err := newFrameReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		Port:    Port,
+		frames:  frames,
+		log:     log,
+		streams: make(map[flowKey]*stream),
+	}, nil
+}
+
+// Next returns the next PDU found in the capture. It returns
+// io.EOF if there are no more PDU.
+func (r *Reader) Next() (Packet, error) {
+	for len(r.queue) == 0 {
+		f, err := r.frames.next()
+		if err != nil {
+			return Packet{}, err
+		}
+		r.handleFrame(f)
+	}
+	p := r.queue[0]
+	r.queue = r.queue[1:]
+	return p, nil
+}
+
+// ReadFile returns all Agentx PDU found in the capture file.
+func ReadFile(name string, log logger.Log) (packets []Packet, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	r, err := NewReader(f, log)
+	if err != nil {
+		return
+	}
+	for {
+		var p Packet
+		if p, err = r.Next(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		packets = append(packets, p)
+	}
+}
+
+func (r *Reader) handleFrame(f frame) {
+	seg, ok := parseLinkLayer(f.linkType, f.data)
+	if !ok {
+		return
+	}
+	var dir Direction
+	switch {
+	case seg.dstPort == r.Port:
+		dir = ToMaster
+	case seg.srcPort == r.Port:
+		dir = ToSubagent
+	default:
+		return
+	}
+
+	key := seg.flowKey()
+	s := r.streams[key]
+	if s == nil {
+		s = &stream{src: key.src(), dst: key.dst(), dir: dir}
+		r.streams[key] = s
+	}
+	s.addSegment(seg)
+	r.extractPdus(s, f.time)
+
+	if seg.flags&(tcpFin|tcpRst) != 0 {
+		delete(r.streams, key)
+	}
+}
+
+// extractPdus decodes all complete PDU which are accumulated
+// in the stream buffer.
+func (r *Reader) extractPdus(s *stream, t time.Time) {
+	for !s.broken && len(s.buf) >= agentx.PduHeaderSize {
+		hdata := s.buf[:agentx.PduHeaderSize]
+		pdu, ok := agentx.DecodePduHeader(hdata)
+		if !ok {
+			// There is no way to find the next PDU boundary,
+			// so the rest of the stream is ignored.
+			if r.log != nil {
+				r.log.Writef("%s -> %s: failed to decode pdu header", s.src, s.dst)
+				agentx.DecodePduHeaderDbg(hdata, r.log)
+			}
+			s.broken, s.buf = true, nil
+			return
+		}
+		size := agentx.PduHeaderSize + int(pdu.PayloadSize)
+		if len(s.buf) < size {
+			return
+		}
+		pdata := s.buf[agentx.PduHeaderSize:size]
+		if agentx.DecodePduPayload(&pdu, pdata) {
+			r.queue = append(r.queue, Packet{
+				Time:      t,
+				Direction: s.dir,
+				Src:       s.src,
+				Dst:       s.dst,
+				Pdu:       pdu,
+			})
+		} else if r.log != nil {
+			r.log.Writef("%s -> %s: failed to decode pdu payload", s.src, s.dst)
+			pdu, _ = agentx.DecodePduHeaderDbg(hdata, r.log)
+			agentx.DecodePduPayloadDbg(&pdu, pdata, r.log)
+		}
+		s.buf = s.buf[size:]
+	}
+}
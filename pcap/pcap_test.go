@@ -0,0 +1,330 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexispb/mygosnmp/agentx"
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/internal"
+)
+
+type tlog struct {
+	sb strings.Builder
+}
+
+func (l *tlog) Write(msg string) {
+	l.sb.WriteString(msg)
+	l.sb.WriteByte('\n')
+}
+func (l *tlog) Writef(format string, args ...interface{}) {
+	l.Write(format)
+}
+
+var (
+	testSubagentIP4 = []byte{10, 0, 0, 2}
+	testMasterIP4   = []byte{10, 0, 0, 1}
+	testSubagentIP6 = []byte{0xFE, 0x80, 15: 2}
+	testMasterIP6   = []byte{0xFE, 0x80, 15: 1}
+	testTime        = time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+)
+
+var testPdus = []agentx.Pdu{
+	{
+		Tag:       agentx.TagOpen,
+		Flags:     agentx.FlagNetworkByteOrder,
+		SessionId: 0,
+		PacketId:  1,
+		Params: agentx.OpenParams{
+			Timeout:     10,
+			Oid:         []uint32{1, 3, 6, 1, 4, 1, 999},
+			Description: "my subagent",
+		},
+	},
+	{
+		Tag:       agentx.TagResponse,
+		Flags:     agentx.FlagNetworkByteOrder,
+		SessionId: 7,
+		PacketId:  1,
+		Params:    agentx.ResponseParams{SysUpTime: 100},
+	},
+	{
+		Tag:       agentx.TagGet,
+		SessionId: 7,
+		PacketId:  2,
+		Params:    agentx.NoParams{},
+		Ranges: []agentx.SearchRange{
+			{StartOid: []uint32{1, 3, 6, 1, 4, 1, 999, 1, 0}},
+		},
+	},
+	{
+		Tag:       agentx.TagResponse,
+		SessionId: 7,
+		PacketId:  2,
+		Params:    agentx.ResponseParams{SysUpTime: 200},
+		Varbinds: []asn.Varbind{
+			{Oid: []uint32{1, 3, 6, 1, 4, 1, 999, 1, 0}, Tag: asn.TagInteger32, Value: int32(123)},
+		},
+	},
+}
+
+func encodeTestPdu(t *testing.T, pdu agentx.Pdu) (data []byte, encoded agentx.Pdu) {
+	data, ok := agentx.EncodePdu(pdu)
+	if !ok {
+		t.Fatalf("failed to encode pdu:\n%s", pdu.String(1))
+	}
+	encoded, _ = agentx.DecodePduHeader(data[:agentx.PduHeaderSize])
+	agentx.DecodePduPayload(&encoded, data[agentx.PduHeaderSize:])
+	return
+}
+
+// tcpSegment returns TCP segment (without IP header).
+func tcpSegment(srcPort, dstPort uint16, seq uint32, flags byte, payload []byte) []byte {
+	data := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(data[0:], srcPort)
+	binary.BigEndian.PutUint16(data[2:], dstPort)
+	binary.BigEndian.PutUint32(data[4:], seq)
+	data[12] = 5 << 4
+	data[13] = flags | 0x10 // ACK
+	return append(data, payload...)
+}
+
+func ethernetIPv4Frame(src, dst []byte, tcp []byte) []byte {
+	data := make([]byte, 14+20, 14+20+len(tcp))
+	binary.BigEndian.PutUint16(data[12:], etherTypeIPv4)
+	ip := data[14:]
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)))
+	ip[6] = 0x40 // don't fragment
+	ip[8] = 64
+	ip[9] = ipProtocolTCP
+	copy(ip[12:], src)
+	copy(ip[16:], dst)
+	return append(data, tcp...)
+}
+
+func rawIPv6Frame(src, dst []byte, tcp []byte) []byte {
+	data := make([]byte, 40, 40+len(tcp))
+	data[0] = 0x60
+	binary.BigEndian.PutUint16(data[4:], uint16(len(tcp)))
+	data[6] = ipProtocolTCP
+	data[7] = 64
+	copy(data[8:], src)
+	copy(data[24:], dst)
+	return append(data, tcp...)
+}
+
+func pcapFile(order binary.ByteOrder, linkType uint32, frames [][]byte) []byte {
+	var buf bytes.Buffer
+	hdr := make([]byte, 24)
+	order.PutUint32(hdr[0:], pcapMagicMicro)
+	order.PutUint16(hdr[4:], 2)
+	order.PutUint16(hdr[6:], 4)
+	order.PutUint32(hdr[16:], 65535)
+	order.PutUint32(hdr[20:], linkType)
+	buf.Write(hdr)
+	for i, f := range frames {
+		rec := make([]byte, 16)
+		ts := testTime.Add(time.Duration(i) * time.Millisecond)
+		order.PutUint32(rec[0:], uint32(ts.Unix()))
+		order.PutUint32(rec[4:], uint32(ts.Nanosecond()/1000))
+		order.PutUint32(rec[8:], uint32(len(f)))
+		order.PutUint32(rec[12:], uint32(len(f)))
+		buf.Write(rec)
+		buf.Write(f)
+	}
+	return buf.Bytes()
+}
+
+func pcapngBlock(order binary.ByteOrder, blockType uint32, body []byte) []byte {
+	body = append(body, make([]byte, align4(len(body))-len(body))...)
+	block := make([]byte, 8, 12+len(body))
+	order.PutUint32(block[0:], blockType)
+	order.PutUint32(block[4:], uint32(12+len(body)))
+	block = append(block, body...)
+	return append(block, block[4:8]...)
+}
+
+// pcapngFile returns pcapng file with nanosecond resolution timestamps.
+func pcapngFile(order binary.ByteOrder, linkType uint16, frames [][]byte) []byte {
+	var buf bytes.Buffer
+	shb := make([]byte, 16)
+	order.PutUint32(shb[0:], pcapngMagicBO)
+	order.PutUint16(shb[4:], 1)
+	binary.BigEndian.PutUint64(shb[8:], 0xFFFFFFFFFFFFFFFF)
+	buf.Write(pcapngBlock(order, pcapngMagicSHB, shb))
+
+	idb := make([]byte, 20)
+	order.PutUint16(idb[0:], linkType)
+	order.PutUint16(idb[8:], pcapngOptTsresol)
+	order.PutUint16(idb[10:], 1)
+	idb[12] = 9 // nanoseconds
+	// idb[16:20] is opt_endofopt
+	buf.Write(pcapngBlock(order, pcapngBlockIDB, idb))
+
+	for i, f := range frames {
+		ts := uint64(testTime.Add(time.Duration(i) * time.Millisecond).UnixNano())
+		epb := make([]byte, 20, 20+len(f))
+		order.PutUint32(epb[4:], uint32(ts>>32))
+		order.PutUint32(epb[8:], uint32(ts))
+		order.PutUint32(epb[12:], uint32(len(f)))
+		order.PutUint32(epb[16:], uint32(len(f)))
+		buf.Write(pcapngBlock(order, pcapngBlockEPB, append(epb, f...)))
+	}
+	return buf.Bytes()
+}
+
+type testPacket struct {
+	pdu   agentx.Pdu
+	dir   Direction
+	frame int
+}
+
+// testStreamFrames returns TCP segments which carry testPdus.
+// The segments include handshake, retransmission, segment split
+// and reordering, and a damaged pdu.
+func testStreamFrames(t *testing.T) (segs [][]byte, toMaster []bool, want []testPacket) {
+	var data [4][]byte
+	var pdus [4]agentx.Pdu
+	for i := range testPdus {
+		data[i], pdus[i] = encodeTestPdu(t, testPdus[i])
+	}
+	damaged, _ := encodeTestPdu(t, testPdus[2])
+	damaged[23] = 0xFF // non-zero reserved byte of the search range
+
+	const sport, seqS, seqM = 40000, 1000, 5000
+	add := func(fromSubagent bool, seg []byte) {
+		segs = append(segs, seg)
+		toMaster = append(toMaster, fromSubagent)
+	}
+
+	sS, sM := uint32(seqS), uint32(seqM)
+	add(true, tcpSegment(sport, Port, sS, tcpSyn, nil))
+	add(false, tcpSegment(Port, sport, sM, tcpSyn, nil))
+	sS++
+	sM++
+
+	// Open: in a single segment and then retransmitted
+	add(true, tcpSegment(sport, Port, sS, 0, data[0]))
+	want = append(want, testPacket{pdus[0], ToMaster, len(segs) - 1})
+	add(true, tcpSegment(sport, Port, sS, 0, data[0]))
+	sS += uint32(len(data[0]))
+
+	// Response: split into two segments
+	add(false, tcpSegment(Port, sport, sM, 0, data[1][:10]))
+	add(false, tcpSegment(Port, sport, sM+10, 0, data[1][10:]))
+	want = append(want, testPacket{pdus[1], ToSubagent, len(segs) - 1})
+	sM += uint32(len(data[1]))
+
+	// damaged Get followed by valid Get in reordered segments
+	add(false, tcpSegment(Port, sport, sM+uint32(len(damaged)), 0, data[2]))
+	add(false, tcpSegment(Port, sport, sM, 0, damaged))
+	want = append(want, testPacket{pdus[2], ToSubagent, len(segs) - 1})
+
+	// Response and FIN
+	add(true, tcpSegment(sport, Port, sS, tcpFin, data[3]))
+	want = append(want, testPacket{pdus[3], ToMaster, len(segs) - 1})
+
+	// not Agentx traffic
+	add(true, tcpSegment(sport, 161, 1, 0, data[3]))
+	return
+}
+
+func testReader(t *testing.T, testid string, file []byte, want []testPacket) {
+	log := &tlog{}
+	r, err := NewReader(bytes.NewReader(file), log)
+	if err != nil {
+		t.Fatalf("%s: NewReader: %v", testid, err)
+	}
+	for i, w := range want {
+		p, err := r.Next()
+		if err != nil {
+			t.Fatalf("%s: packet[%d]: %v", testid, i, err)
+		}
+		if p.Direction != w.dir {
+			t.Errorf("%s: packet[%d]: direction %s != %s",
+				testid, i, p.Direction, w.dir)
+		}
+		wantTime := testTime.Add(time.Duration(w.frame) * time.Millisecond)
+		if !p.Time.Equal(wantTime) {
+			t.Errorf("%s: packet[%d]: time %v != %v",
+				testid, i, p.Time, wantTime)
+		}
+		if diff := internal.StructsDiff(w.pdu, p.Pdu); len(diff) != 0 {
+			t.Errorf("%s: packet[%d]: invalid pdu:\n%s", testid, i, diff)
+		}
+	}
+	if _, err = r.Next(); err != io.EOF {
+		t.Errorf("%s: expected EOF, got %v", testid, err)
+	}
+	if !strings.Contains(log.sb.String(), "Failed to decode pdu payload") {
+		t.Errorf("%s: damaged pdu is not logged:\n%s", testid, log.sb.String())
+	}
+}
+
+func TestPcapReader(t *testing.T) {
+	segs, toMaster, want := testStreamFrames(t)
+	var frames [][]byte
+	for i, seg := range segs {
+		if toMaster[i] {
+			frames = append(frames, ethernetIPv4Frame(testSubagentIP4, testMasterIP4, seg))
+		} else {
+			frames = append(frames, ethernetIPv4Frame(testMasterIP4, testSubagentIP4, seg))
+		}
+	}
+	testReader(t, "pcap little endian",
+		pcapFile(binary.LittleEndian, linkTypeEthernet, frames), want)
+	testReader(t, "pcap big endian",
+		pcapFile(binary.BigEndian, linkTypeEthernet, frames), want)
+}
+
+func TestPcapngReader(t *testing.T) {
+	segs, toMaster, want := testStreamFrames(t)
+	var frames [][]byte
+	for i, seg := range segs {
+		if toMaster[i] {
+			frames = append(frames, rawIPv6Frame(testSubagentIP6, testMasterIP6, seg))
+		} else {
+			frames = append(frames, rawIPv6Frame(testMasterIP6, testSubagentIP6, seg))
+		}
+	}
+	testReader(t, "pcapng little endian",
+		pcapngFile(binary.LittleEndian, linkTypeRaw, frames), want)
+	testReader(t, "pcapng big endian",
+		pcapngFile(binary.BigEndian, linkTypeRaw, frames), want)
+}
+
+var testDataBadFormat = []struct {
+	data []byte
+	err  error
+}{
+	{data: nil, err: ErrFormat},
+	{data: []byte{1, 2, 3, 4, 5, 6, 7, 8}, err: ErrFormat},
+	{data: []byte{0xD4, 0xC3, 0xB2, 0xA1, 0, 0}, err: ErrMalformed},
+	{data: []byte{0x0A, 0x0D, 0x0D, 0x0A, 0, 0, 0, 28, 1, 2, 3, 4}, err: ErrMalformed},
+}
+
+func TestBadFormat(t *testing.T) {
+	for i, test := range testDataBadFormat {
+		if _, err := NewReader(bytes.NewReader(test.data), nil); err != test.err {
+			t.Errorf("TestBadFormat[%d]: %v != %v", i, err, test.err)
+		}
+	}
+}
+
+func TestStreamPendingSize(t *testing.T) {
+	s := stream{synced: true, nextSeq: 100}
+	s.addSegment(segment{seq: 110, payload: []byte{1, 2}})
+	s.addSegment(segment{seq: 110, payload: []byte{1, 2, 3, 4}})
+	if s.pendingSize != 4 {
+		t.Errorf("TestStreamPendingSize: pendingSize %d after replace, want 4", s.pendingSize)
+	}
+	s.addSegment(segment{seq: 100, payload: make([]byte, 10)})
+	if s.pendingSize != 0 || len(s.buf) != 14 {
+		t.Errorf("TestStreamPendingSize: pendingSize %d, buf %d after drain, want 0, 14", s.pendingSize, len(s.buf))
+	}
+}
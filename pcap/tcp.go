@@ -0,0 +1,313 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+)
+
+// Link layer types (see https://www.tcpdump.org/linktypes.html).
+const (
+	linkTypeNull     = 0
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLoop     = 108
+	linkTypeLinuxSLL = 113
+	linkTypeIPv4     = 228
+	linkTypeIPv6     = 229
+	linkTypeSLL2     = 276
+)
+
+const (
+	etherTypeIPv4  = 0x0800
+	etherTypeIPv6  = 0x86DD
+	etherTypeVLAN  = 0x8100
+	etherTypeQinQ  = 0x88A8
+	ipProtocolTCP  = 6
+	ipv6HopByHop   = 0
+	ipv6Routing    = 43
+	ipv6Fragment   = 44
+	ipv6DestOpts   = 60
+	tcpFin         = 0x01
+	tcpSyn         = 0x02
+	tcpRst         = 0x04
+	maxPendingSize = 1 << 20
+)
+
+// segment is a parsed TCP segment.
+type segment struct {
+	srcIP   net.IP
+	dstIP   net.IP
+	srcPort uint16
+	dstPort uint16
+	seq     uint32
+	flags   byte
+	payload []byte
+}
+
+// flowKey identifies a unidirectional TCP flow.
+type flowKey struct {
+	srcIP   string
+	dstIP   string
+	srcPort uint16
+	dstPort uint16
+}
+
+func (s segment) flowKey() flowKey {
+	return flowKey{
+		srcIP:   string(s.srcIP),
+		dstIP:   string(s.dstIP),
+		srcPort: s.srcPort,
+		dstPort: s.dstPort,
+	}
+}
+
+func (k flowKey) src() string {
+	return net.JoinHostPort(net.IP(k.srcIP).String(), strconv.Itoa(int(k.srcPort)))
+}
+
+func (k flowKey) dst() string {
+	return net.JoinHostPort(net.IP(k.dstIP).String(), strconv.Itoa(int(k.dstPort)))
+}
+
+// parseLinkLayer extracts TCP segment from the link layer frame.
+// It returns ok = false if frame does not contain TCP segment.
+func parseLinkLayer(linkType uint32, data []byte) (seg segment, ok bool) {
+	switch linkType {
+	case linkTypeEthernet:
+		if len(data) < 14 {
+			return
+		}
+		etherType := binary.BigEndian.Uint16(data[12:])
+		data = data[14:]
+		for (etherType == etherTypeVLAN || etherType == etherTypeQinQ) && len(data) >= 4 {
+			etherType = binary.BigEndian.Uint16(data[2:])
+			data = data[4:]
+		}
+		return parseEtherType(etherType, data)
+
+	case linkTypeNull, linkTypeLoop:
+		if len(data) < 4 {
+			return
+		}
+		// family is in host byte order of the capturing
+		// machine for Null and in network order for Loop
+		family := binary.BigEndian.Uint32(data)
+		if linkType == linkTypeNull && family > 0xFFFF {
+			family = binary.LittleEndian.Uint32(data)
+		}
+		switch family {
+		case 2:
+			return parseIPv4(data[4:])
+		case 10, 24, 28, 30:
+			return parseIPv6(data[4:])
+		}
+
+	case linkTypeRaw:
+		return parseIP(data)
+
+	case linkTypeIPv4:
+		return parseIPv4(data)
+
+	case linkTypeIPv6:
+		return parseIPv6(data)
+
+	case linkTypeLinuxSLL:
+		if len(data) < 16 {
+			return
+		}
+		return parseEtherType(binary.BigEndian.Uint16(data[14:]), data[16:])
+
+	case linkTypeSLL2:
+		if len(data) < 20 {
+			return
+		}
+		return parseEtherType(binary.BigEndian.Uint16(data[0:]), data[20:])
+	}
+	return
+}
+
+func parseEtherType(etherType uint16, data []byte) (seg segment, ok bool) {
+	switch etherType {
+	case etherTypeIPv4:
+		return parseIPv4(data)
+	case etherTypeIPv6:
+		return parseIPv6(data)
+	}
+	return
+}
+
+// parseIP parses IP packet of either version.
+func parseIP(data []byte) (seg segment, ok bool) {
+	if len(data) == 0 {
+		return
+	}
+	switch data[0] >> 4 {
+	case 4:
+		return parseIPv4(data)
+	case 6:
+		return parseIPv6(data)
+	}
+	return
+}
+
+func parseIPv4(data []byte) (seg segment, ok bool) {
+	if len(data) < 20 || data[0]>>4 != 4 {
+		return
+	}
+	hlen := int(data[0]&0x0F) * 4
+	tlen := int(binary.BigEndian.Uint16(data[2:]))
+	if hlen < 20 || tlen < hlen || tlen > len(data) {
+		return
+	}
+	// fragmented datagrams are not reassembled
+	if binary.BigEndian.Uint16(data[6:])&0x3FFF != 0 {
+		return
+	}
+	if data[9] != ipProtocolTCP {
+		return
+	}
+	seg, ok = parseTCP(data[hlen:tlen])
+	seg.srcIP = net.IP(data[12:16])
+	seg.dstIP = net.IP(data[16:20])
+	return
+}
+
+func parseIPv6(data []byte) (seg segment, ok bool) {
+	if len(data) < 40 || data[0]>>4 != 6 {
+		return
+	}
+	plen := int(binary.BigEndian.Uint16(data[4:]))
+	if 40+plen > len(data) {
+		return
+	}
+	next, payload := data[6], data[40:40+plen]
+	for next != ipProtocolTCP {
+		switch next {
+		case ipv6HopByHop, ipv6Routing, ipv6DestOpts:
+			if len(payload) < 8 {
+				return
+			}
+			size := 8 + int(payload[1])*8
+			if size > len(payload) {
+				return
+			}
+			next, payload = payload[0], payload[size:]
+		default:
+			// fragments and other protocols are ignored
+			return
+		}
+	}
+	seg, ok = parseTCP(payload)
+	seg.srcIP = net.IP(data[8:24])
+	seg.dstIP = net.IP(data[24:40])
+	return
+}
+
+func parseTCP(data []byte) (seg segment, ok bool) {
+	if len(data) < 20 {
+		return
+	}
+	hlen := int(data[12]>>4) * 4
+	if hlen < 20 || hlen > len(data) {
+		return
+	}
+	seg.srcPort = binary.BigEndian.Uint16(data[0:])
+	seg.dstPort = binary.BigEndian.Uint16(data[2:])
+	seg.seq = binary.BigEndian.Uint32(data[4:])
+	seg.flags = data[13]
+	seg.payload = data[hlen:]
+	ok = true
+	return
+}
+
+// stream reassembles data of a unidirectional TCP flow.
+type stream struct {
+	src string
+	dst string
+	dir Direction
+	// synced is true when nextSeq is known.
+	synced  bool
+	nextSeq uint32
+	// buf contains in-order data which are not decoded yet.
+	buf []byte
+	// pending contains out-of-order segments by sequence number.
+	pending     map[uint32][]byte
+	pendingSize int
+	// broken is set if the stream data can not be decoded.
+	broken bool
+}
+
+// addSegment appends segment data to the stream buffer
+// taking into account retransmitted and out-of-order
+// segments.
+func (s *stream) addSegment(seg segment) {
+	if seg.flags&tcpSyn != 0 {
+		// a new connection reuses the flow
+		*s = stream{src: s.src, dst: s.dst, dir: s.dir}
+		s.synced, s.nextSeq = true, seg.seq+1
+		return
+	}
+	if len(seg.payload) == 0 || s.broken {
+		return
+	}
+	if !s.synced {
+		// capture started in the middle of connection
+		s.synced, s.nextSeq = true, seg.seq
+	}
+
+	seq, data := seg.seq, seg.payload
+	if diff := int32(s.nextSeq - seq); diff > 0 {
+		// retransmission (possibly partial)
+		if int(diff) >= len(data) {
+			return
+		}
+		seq, data = s.nextSeq, data[diff:]
+	} else if diff < 0 {
+		s.addPending(seq, data)
+		return
+	}
+
+	s.buf = append(s.buf, data...)
+	s.nextSeq = seq + uint32(len(data))
+	s.drainPending()
+}
+
+func (s *stream) addPending(seq uint32, data []byte) {
+	if s.pending == nil {
+		s.pending = make(map[uint32][]byte)
+	}
+	old, ok := s.pending[seq]
+	if ok && len(old) >= len(data) {
+		return
+	}
+	// the longer segment replaces the pending one
+	if s.pendingSize += len(data) - len(old); s.pendingSize > maxPendingSize {
+		// the gap is never filled (e.g. capture lost
+		// a segment), so the stream can not be decoded
+		s.broken, s.buf, s.pending = true, nil, nil
+		return
+	}
+	s.pending[seq] = append([]byte(nil), data...)
+}
+
+// drainPending moves the pending segments which became
+// in-order to the stream buffer.
+func (s *stream) drainPending() {
+	for found := true; found && len(s.pending) > 0; {
+		found = false
+		for seq, data := range s.pending {
+			diff := int32(s.nextSeq - seq)
+			if diff < 0 {
+				continue
+			}
+			delete(s.pending, seq)
+			s.pendingSize -= len(data)
+			if int(diff) < len(data) {
+				s.buf = append(s.buf, data[diff:]...)
+				s.nextSeq = seq + uint32(len(data))
+			}
+			found = true
+		}
+	}
+}
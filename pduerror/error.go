@@ -38,3 +38,9 @@ const (
 	RequestDenied         Error = 267
 	ProcessingError       Error = 268
 )
+
+// Error implements the error interface, so the pdu error can be
+// wrapped (e.g. by the snmp.StatusError) and tested with errors.Is.
+func (e Error) Error() string {
+	return e.String()
+}
@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Recorder is io.ReadWriter which passes data to/from the wrapped
+// connection and records every PDU read (received) and written
+// (sent).
+type Recorder struct {
+	conn io.ReadWriter
+
+	lock sync.Mutex
+	w    io.Writer
+	err  error
+	// sent and received accumulate incomplete PDU data.
+	sent     []byte
+	received []byte
+	// now is used to get record timestamps (replaced in tests).
+	now func() time.Time
+}
+
+// NewRecorder writes the recording header to w and returns
+// Recorder which wraps conn. The role is the role of the
+// side which uses the recorder.
+func NewRecorder(conn io.ReadWriter, w io.Writer, role Role) (*Recorder, error) {
+	if err := writeHeader(w, role); err != nil {
+		return nil, err
+	}
+	return &Recorder{conn: conn, w: w, now: time.Now}, nil
+}
+
+// Read reads data from the wrapped connection and records
+// completely received PDU.
+func (r *Recorder) Read(p []byte) (n int, err error) {
+	n, err = r.conn.Read(p)
+	if n > 0 {
+		r.record(Received, p[:n])
+	}
+	return
+}
+
+// Write writes data to the wrapped connection and records
+// completely sent PDU.
+func (r *Recorder) Write(p []byte) (n int, err error) {
+	n, err = r.conn.Write(p)
+	if n > 0 {
+		r.record(Sent, p[:n])
+	}
+	return
+}
+
+// Err returns the first error encountered while writing
+// the recording. Recording errors do not affect passing
+// data to/from the wrapped connection.
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+func (r *Recorder) record(dir Direction, p []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	buf := &r.sent
+	if dir == Received {
+		buf = &r.received
+	}
+	*buf = append(*buf, p...)
+
+	for {
+		size, ok := pduSize(*buf)
+		if !ok || len(*buf) < size {
+			return
+		}
+		rec := Record{
+			Time:      r.now(),
+			Direction: dir,
+			Data:      append([]byte(nil), (*buf)[:size]...),
+		}
+		*buf = (*buf)[size:]
+		if r.err == nil {
+			r.err = writeRecord(r.w, rec)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Package replay provides recording of Agentx sessions and their
+deterministic replay in tests.
+
+Recorder wraps the connection (io.ReadWriter) of a master agent or
+subagent and writes every sent and received PDU to a recording:
+
+	f, _ := os.Create("session.agx")
+	conn, _ := net.Dial("tcp", "localhost:705")
+	rec, _ := replay.NewRecorder(conn, f, replay.RoleSubagent)
+	runSubagent(rec) // reads/writes rec instead of conn
+
+Replayer plays the role of the peer (i.e. fake master for recorded
+subagent and vice versa). It sends the recorded received PDU and
+checks that the responses of the code under test match the recorded
+sent PDU:
+
+	_, records, _ := replay.ReadFile("session.agx")
+	master, subagent := net.Pipe()
+	go runSubagent(subagent)
+	p := replay.Replayer{Records: records}
+	if err := p.Run(master); err != nil {
+		t.Error(err)
+	}
+
+Replay is deterministic: the recorded timestamps are informational
+and do not affect the replay timing.
+*/
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/alexispb/mygosnmp/agentx"
+)
+
+var ErrFormat = errors.New("replay: invalid recording format")
+
+// fileMagic starts each recording.
+const fileMagic = "AGXREC01"
+
+// recordHeaderSize is the size of timestamp (8 bytes), direction
+// (1 byte), reserved (3 bytes), and data size (4 bytes).
+const recordHeaderSize = 16
+
+// maxRecordSize limits the size of recorded PDU.
+const maxRecordSize = 1 << 24
+
+// Role is the role of the recorded side of the session.
+type Role byte
+
+const (
+	RoleSubagent Role = 1
+	RoleMaster   Role = 2
+)
+
+// String returns Role string representation.
+func (r Role) String() string {
+	switch r {
+	case RoleSubagent:
+		return "Subagent"
+	case RoleMaster:
+		return "Master"
+	default:
+		return "?"
+	}
+}
+
+// Direction is the direction of the recorded PDU with respect
+// to the recorded side.
+type Direction byte
+
+const (
+	Sent     Direction = 1
+	Received Direction = 2
+)
+
+// String returns Direction string representation.
+func (d Direction) String() string {
+	switch d {
+	case Sent:
+		return "Sent"
+	case Received:
+		return "Received"
+	default:
+		return "?"
+	}
+}
+
+// Record is a recorded PDU.
+type Record struct {
+	Time      time.Time
+	Direction Direction
+	// Data is the encoded PDU (header and payload).
+	Data []byte
+}
+
+// Pdu returns decoded record data. If ok = false, the
+// recorded data is not a valid PDU.
+func (r Record) Pdu() (pdu agentx.Pdu, ok bool) {
+	if len(r.Data) < agentx.PduHeaderSize {
+		return
+	}
+	if pdu, ok = agentx.DecodePduHeader(r.Data[:agentx.PduHeaderSize]); !ok {
+		return
+	}
+	ok = agentx.DecodePduPayload(&pdu, r.Data[agentx.PduHeaderSize:])
+	return
+}
+
+// writeHeader writes the recording header.
+func writeHeader(w io.Writer, role Role) error {
+	hdr := make([]byte, 0, len(fileMagic)+4)
+	hdr = append(hdr, fileMagic...)
+	hdr = append(hdr, byte(role), 0, 0, 0)
+	_, err := w.Write(hdr)
+	return err
+}
+
+// writeRecord writes the record.
+func writeRecord(w io.Writer, r Record) error {
+	buf := make([]byte, recordHeaderSize, recordHeaderSize+len(r.Data))
+	binary.BigEndian.PutUint64(buf[0:], uint64(r.Time.UnixNano()))
+	buf[8] = byte(r.Direction)
+	binary.BigEndian.PutUint32(buf[12:], uint32(len(r.Data)))
+	_, err := w.Write(append(buf, r.Data...))
+	return err
+}
+
+// ReadRecords reads the recording.
+func ReadRecords(r io.Reader) (role Role, records []Record, err error) {
+	br := bufio.NewReader(r)
+
+	hdr := make([]byte, len(fileMagic)+4)
+	if _, err = io.ReadFull(br, hdr); err != nil || string(hdr[:len(fileMagic)]) != fileMagic {
+		return 0, nil, ErrFormat
+	}
+	if role = Role(hdr[len(fileMagic)]); role != RoleSubagent && role != RoleMaster {
+		return 0, nil, ErrFormat
+	}
+
+	var rhdr [recordHeaderSize]byte
+	for {
+		if _, err = io.ReadFull(br, rhdr[:]); err != nil {
+			if err == io.EOF {
+				err = nil
+			} else {
+				err = ErrFormat
+			}
+			return
+		}
+		rec := Record{
+			Time:      time.Unix(0, int64(binary.BigEndian.Uint64(rhdr[0:]))),
+			Direction: Direction(rhdr[8]),
+		}
+		size := binary.BigEndian.Uint32(rhdr[12:])
+		if (rec.Direction != Sent && rec.Direction != Received) || size > maxRecordSize {
+			return role, records, ErrFormat
+		}
+		rec.Data = make([]byte, size)
+		if _, err = io.ReadFull(br, rec.Data); err != nil {
+			return role, records, ErrFormat
+		}
+		records = append(records, rec)
+	}
+}
+
+// ReadFile reads the recording file.
+func ReadFile(name string) (role Role, records []Record, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	return ReadRecords(f)
+}
+
+// pduSize returns the size of PDU (header and payload) which
+// starts the data. If data is shorter than the PDU header, it
+// returns ok = false.
+func pduSize(data []byte) (size int, ok bool) {
+	if len(data) < agentx.PduHeaderSize {
+		return
+	}
+	var payloadSize uint32
+	if agentx.Flags(data[2])&agentx.FlagNetworkByteOrder != 0 {
+		payloadSize = binary.BigEndian.Uint32(data[16:])
+	} else {
+		payloadSize = binary.LittleEndian.Uint32(data[16:])
+	}
+	if payloadSize > maxRecordSize {
+		// garbage is recorded as a whole
+		return len(data), true
+	}
+	return agentx.PduHeaderSize + int(payloadSize), true
+}
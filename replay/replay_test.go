@@ -0,0 +1,186 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexispb/mygosnmp/agentx"
+	"github.com/alexispb/mygosnmp/asn"
+)
+
+var testVarOid = []uint32{1, 3, 6, 1, 4, 1, 999, 1, 0}
+
+// runTestSubagent answers Get requests with value until
+// Close request is received.
+func runTestSubagent(conn io.ReadWriter, value int32) {
+	for {
+		pdu, err := readPdu(conn)
+		if err != nil || pdu.Tag == agentx.TagClose {
+			return
+		}
+		pdu.Tag = agentx.TagResponse
+		pdu.Params = agentx.ResponseParams{SysUpTime: 100}
+		pdu.Varbinds = []asn.Varbind{
+			{Oid: testVarOid, Tag: asn.TagInteger32, Value: value},
+		}
+		pdu.Ranges = nil
+		data, _ := agentx.EncodePdu(pdu)
+		// the response is written in two chunks in order
+		// to test recording of incomplete pdu
+		conn.Write(data[:7])
+		conn.Write(data[7:])
+	}
+}
+
+// runTestMaster sends two Get requests and Close request.
+func runTestMaster(conn io.ReadWriter) {
+	pdu := agentx.Pdu{
+		Tag:       agentx.TagGet,
+		Flags:     agentx.FlagNetworkByteOrder,
+		SessionId: 7,
+		Params:    agentx.NoParams{},
+		Ranges:    []agentx.SearchRange{{StartOid: testVarOid}},
+	}
+	for i := 0; i < 2; i++ {
+		pdu.PacketId = uint32(i + 1)
+		data, _ := agentx.EncodePdu(pdu)
+		conn.Write(data)
+		readPdu(conn)
+	}
+	pdu.Tag = agentx.TagClose
+	pdu.Params = agentx.CloseParams{Reason: agentx.CloseReasonShutdown}
+	pdu.Ranges = nil
+	data, _ := agentx.EncodePdu(pdu)
+	conn.Write(data)
+}
+
+func recordTestSession(t *testing.T) []byte {
+	var file bytes.Buffer
+	master, subagent := net.Pipe()
+	defer master.Close()
+	defer subagent.Close()
+
+	rec, err := NewRecorder(subagent, &file, RoleSubagent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticks := int64(0)
+	rec.now = func() time.Time {
+		ticks++
+		return time.Unix(ticks, 0)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		runTestSubagent(rec, 123)
+		close(done)
+	}()
+	runTestMaster(master)
+	<-done
+
+	if err = rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return file.Bytes()
+}
+
+func TestRecorder(t *testing.T) {
+	role, records, err := ReadRecords(bytes.NewReader(recordTestSession(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role != RoleSubagent {
+		t.Errorf("invalid role: %s", role)
+	}
+
+	want := []struct {
+		dir Direction
+		tag agentx.PduTag
+	}{
+		{Received, agentx.TagGet},
+		{Sent, agentx.TagResponse},
+		{Received, agentx.TagGet},
+		{Sent, agentx.TagResponse},
+		{Received, agentx.TagClose},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("invalid number of records: %d != %d", len(records), len(want))
+	}
+	for i, rec := range records {
+		pdu, ok := rec.Pdu()
+		if !ok {
+			t.Errorf("record[%d]: invalid pdu", i)
+			continue
+		}
+		if rec.Direction != want[i].dir || pdu.Tag != want[i].tag {
+			t.Errorf("record[%d]: %s %s != %s %s", i,
+				rec.Direction, pdu.Tag, want[i].dir, want[i].tag)
+		}
+		if rec.Time.Unix() != int64(i+1) {
+			t.Errorf("record[%d]: invalid time %v", i, rec.Time)
+		}
+	}
+}
+
+func replayTestSession(t *testing.T, records []Record, value int32) error {
+	master, subagent := net.Pipe()
+	defer master.Close()
+	defer subagent.Close()
+
+	done := make(chan struct{})
+	go func() {
+		runTestSubagent(subagent, value)
+		close(done)
+	}()
+	p := Replayer{Records: records}
+	err := p.Run(master)
+	<-done
+	return err
+}
+
+func TestReplayer(t *testing.T) {
+	_, records, err := ReadRecords(bytes.NewReader(recordTestSession(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = replayTestSession(t, records, 123); err != nil {
+		t.Errorf("unexpected replay error: %v", err)
+	}
+
+	err = replayTestSession(t, records, 456)
+	var merr *MismatchError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected MismatchError, got %v", err)
+	}
+	if len(merr.Mismatches) != 2 || merr.Mismatches[0].Index != 1 || merr.Mismatches[1].Index != 3 {
+		t.Errorf("invalid mismatches:\n%s", merr.Error())
+	}
+	if !strings.Contains(merr.Error(), "456") {
+		t.Errorf("mismatch does not show actual value:\n%s", merr.Error())
+	}
+}
+
+var testDataBadFormat = [][]byte{
+	nil,
+	[]byte("AGXREC00\x01\x00\x00\x00"),
+	[]byte("AGXREC01\x03\x00\x00\x00"),
+	[]byte("AGXREC01\x01\x00\x00\x00\x00\x00\x00"),
+	[]byte("AGXREC01\x01\x00\x00\x00" +
+		"\x00\x00\x00\x00\x00\x00\x00\x01\x03\x00\x00\x00\x00\x00\x00\x00"),
+	[]byte("AGXREC01\x01\x00\x00\x00" +
+		"\x00\x00\x00\x00\x00\x00\x00\x01\x01\x00\x00\x00\x00\x00\x00\x14"),
+}
+
+func TestBadFormat(t *testing.T) {
+	for i, data := range testDataBadFormat {
+		if _, _, err := ReadRecords(bytes.NewReader(data)); err != ErrFormat {
+			t.Errorf("TestBadFormat[%d]: %v", i, err)
+		}
+	}
+}
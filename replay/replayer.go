@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alexispb/mygosnmp/agentx"
+)
+
+// Replayer replays a recording playing the role of the peer
+// of the recorded side, i.e. it acts as fake master if the
+// recording is made by subagent and as fake subagent if the
+// recording is made by master.
+type Replayer struct {
+	Records []Record
+	// Compare returns the description of difference between
+	// the recorded (want) and the actual (got) PDU, or an empty
+	// string if they match. If nil, PDU are compared with
+	// agentx.Diff ignoring the fields defined by Ignore.
+	Compare func(want, got agentx.Pdu) string
+	Ignore  agentx.DiffFlags
+}
+
+// Mismatch describes the difference between recorded and
+// actual PDU.
+type Mismatch struct {
+	// Index is the index of the record.
+	Index int
+	Diff  string
+}
+
+// MismatchError is returned by Replayer.Run if some actual
+// PDU do not match the recorded ones.
+type MismatchError struct {
+	Mismatches []Mismatch
+}
+
+func (e *MismatchError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("replay: %d mismatched pdu", len(e.Mismatches)))
+	for _, m := range e.Mismatches {
+		sb.WriteString(fmt.Sprintf("\nrecord %d:\n", m.Index))
+		sb.WriteString(strings.TrimSuffix(m.Diff, "\n"))
+	}
+	return sb.String()
+}
+
+// Run replays the records over conn which is connected to the
+// code under test. Received records are written to conn, and
+// for each sent record a PDU is read from conn and compared
+// with the recorded one. Run returns *MismatchError if some
+// PDU do not match, or another error if replay fails.
+func (p *Replayer) Run(conn io.ReadWriter) error {
+	compare := p.Compare
+	if compare == nil {
+		compare = func(want, got agentx.Pdu) string {
+			return strings.Join(agentx.Diff(want, got, p.Ignore), "\n")
+		}
+	}
+
+	var mismatches []Mismatch
+	for i, rec := range p.Records {
+		switch rec.Direction {
+		case Received:
+			if _, err := conn.Write(rec.Data); err != nil {
+				return fmt.Errorf("replay: record %d: %w", i, err)
+			}
+
+		case Sent:
+			want, ok := rec.Pdu()
+			if !ok {
+				return fmt.Errorf("replay: record %d: invalid recorded pdu", i)
+			}
+			got, err := readPdu(conn)
+			if err != nil {
+				return fmt.Errorf("replay: record %d: %w", i, err)
+			}
+			if diff := compare(want, got); len(diff) != 0 {
+				mismatches = append(mismatches, Mismatch{Index: i, Diff: diff})
+			}
+		}
+	}
+
+	if len(mismatches) != 0 {
+		return &MismatchError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// readPdu reads and decodes PDU.
+func readPdu(r io.Reader) (pdu agentx.Pdu, err error) {
+	hdata := make([]byte, agentx.PduHeaderSize)
+	if _, err = io.ReadFull(r, hdata); err != nil {
+		return
+	}
+	pdu, ok := agentx.DecodePduHeader(hdata)
+	if !ok {
+		err = fmt.Errorf("failed to decode pdu header")
+		return
+	}
+	pdata := make([]byte, pdu.PayloadSize)
+	if _, err = io.ReadFull(r, pdata); err != nil {
+		return
+	}
+	if !agentx.DecodePduPayload(&pdu, pdata) {
+		err = fmt.Errorf("failed to decode %s pdu payload", pdu.Tag.String())
+	}
+	return
+}
@@ -0,0 +1,293 @@
+package smi
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/generics"
+)
+
+// RegisterFormats sets the formats of the values of the resolved
+// object types (see Syntax.FprintValue). If several modules define
+// the same oid, the syntax of the module added first is used.
+func (m *MIB) RegisterFormats(f *asn.Formats) {
+	m.Walk(func(obj *Object) bool {
+		if obj.Kind == KindObjectType && obj.Syntax != nil && !obj.IsTable() && !obj.IsRow() {
+			f.Register(obj.Oid, obj.Syntax)
+		}
+		return true
+	})
+}
+
+// FprintValue appends the value of the syntax type formatted as
+// follows (it implements asn.Format):
+//
+//   - the INTEGER enumerations as name(number), e.g. up(1) or
+//     true(1) for TruthValue;
+//   - the BITS as the names of the set bits, e.g. {alpha(0) gamma(7)};
+//   - the integer and the octet string values with DISPLAY-HINT
+//     (RFC 2579, 3.1), e.g. 00:1a:2b:3c:4d:5e for MacAddress or
+//     2026-1-2,13:30:15.0 for DateAndTime;
+//   - InetAddress as IPv4 or IPv6 address, depending on its length;
+//   - the octet strings which are not text as hex octets.
+//
+// It returns false if the value is not formatted.
+func (s *Syntax) FprintValue(sb *strings.Builder, tag asn.Tag, v interface{}) bool {
+	if tag != s.Tag() {
+		return false
+	}
+	switch v := v.(type) {
+	case int32:
+		return s.fprintInteger(sb, int64(v))
+	case uint32:
+		return s.fprintInteger(sb, int64(v))
+	case string:
+		return s.fprintOctets(sb, v)
+	}
+	return false
+}
+
+func (s *Syntax) fprintInteger(sb *strings.Builder, n int64) bool {
+	for _, e := range s.Enums {
+		if e.Value == n {
+			sb.WriteString(e.Name)
+			sb.WriteByte('(')
+			sb.WriteString(strconv.FormatInt(n, 10))
+			sb.WriteByte(')')
+			return true
+		}
+	}
+	return fprintIntegerHint(sb, s.DisplayHint, n)
+}
+
+// fprintIntegerHint formats the integer with the hint "d[-N]", "x",
+// "o", or "b".
+func fprintIntegerHint(sb *strings.Builder, hint string, n int64) bool {
+	if hint == "" {
+		return false
+	}
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	var digits string
+	switch {
+	case hint == "x":
+		digits = strconv.FormatInt(n, 16)
+	case hint == "o":
+		digits = strconv.FormatInt(n, 8)
+	case hint == "b":
+		digits = strconv.FormatInt(n, 2)
+	case hint == "d":
+		digits = strconv.FormatInt(n, 10)
+	case strings.HasPrefix(hint, "d-"):
+		point, err := strconv.Atoi(hint[2:])
+		if err != nil || point < 0 || point > 18 {
+			return false
+		}
+		digits = strconv.FormatInt(n, 10)
+		if point > 0 {
+			if len(digits) <= point {
+				digits = strings.Repeat("0", point-len(digits)+1) + digits
+			}
+			digits = digits[:len(digits)-point] + "." + digits[len(digits)-point:]
+		}
+	default:
+		return false
+	}
+	sb.WriteString(sign)
+	sb.WriteString(digits)
+	return true
+}
+
+func (s *Syntax) fprintOctets(sb *strings.Builder, v string) bool {
+	switch {
+	case s.Base == "BITS":
+		fprintBits(sb, s.Enums, v)
+		return true
+	case s.TC != nil && s.TC.Name == "InetAddress" && fprintInetAddress(sb, v):
+		return true
+	case s.DisplayHint != "":
+		specs, ok := parseOctetHint(s.DisplayHint)
+		if ok {
+			fprintOctetHint(sb, specs, v)
+		}
+		return ok
+	case isText(v):
+		return false
+	}
+	fprintHex(sb, v)
+	return true
+}
+
+// fprintBits appends the names (or the numbers) of the set bits.
+func fprintBits(sb *strings.Builder, names []NamedNumber, v string) {
+	sb.WriteByte('{')
+	first := true
+	for i := 0; i < 8*len(v); i++ {
+		if v[i/8]&(0x80>>(i%8)) == 0 {
+			continue
+		}
+		if !first {
+			sb.WriteByte(' ')
+		}
+		first = false
+		name := ""
+		for _, n := range names {
+			if n.Value == int64(i) {
+				name = n.Name
+			}
+		}
+		if name == "" {
+			sb.WriteString(strconv.Itoa(i))
+			continue
+		}
+		sb.WriteString(name)
+		sb.WriteByte('(')
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteByte(')')
+	}
+	sb.WriteByte('}')
+}
+
+// fprintInetAddress appends the address of InetAddress (RFC 4001)
+// of the ipv4, ipv6, ipv4z, or ipv6z type derived from the length.
+func fprintInetAddress(sb *strings.Builder, v string) bool {
+	switch len(v) {
+	case net.IPv4len, net.IPv6len:
+		sb.WriteString(net.IP(v).String())
+	case net.IPv4len + 4, net.IPv6len + 4:
+		sb.WriteString(net.IP(v[:len(v)-4]).String())
+		sb.WriteByte('%')
+		sb.WriteString(strconv.FormatUint(uint64(binary.BigEndian.Uint32([]byte(v[len(v)-4:]))), 10))
+	default:
+		return false
+	}
+	return true
+}
+
+// isText tests whether the string is printable UTF-8 text.
+func isText(v string) bool {
+	if !utf8.ValidString(v) {
+		return false
+	}
+	for _, r := range v {
+		if !unicode.IsPrint(r) && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// fprintHex appends the octets as space separated hex numbers.
+func fprintHex(sb *strings.Builder, v string) {
+	const digits = "0123456789abcdef"
+	for i := 0; i < len(v); i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteByte(digits[v[i]>>4])
+		sb.WriteByte(digits[v[i]&0xf])
+	}
+}
+
+// octetSpec is the octet-format specification of DISPLAY-HINT,
+// e.g. "1x:" or "*1d.".
+type octetSpec struct {
+	// repeat tells that the first octet is the number of times
+	// the specification is applied.
+	repeat bool
+	length int
+	// format is one of x, d, o, a, t.
+	format     byte
+	separator  byte
+	terminator byte
+}
+
+// parseOctetHint parses the octet-format DISPLAY-HINT.
+func parseOctetHint(hint string) (specs []octetSpec, ok bool) {
+	isDigit := func(i int) bool { return i < len(hint) && hint[i] >= '0' && hint[i] <= '9' }
+	isSeparator := func(i int) bool { return i < len(hint) && !isDigit(i) && hint[i] != '*' }
+	for i := 0; i < len(hint); {
+		var spec octetSpec
+		if hint[i] == '*' {
+			spec.repeat = true
+			i++
+		}
+		start := i
+		for isDigit(i) {
+			i++
+		}
+		length, err := strconv.Atoi(hint[start:i])
+		if err != nil || length == 0 || i == len(hint) || !strings.ContainsRune("xdoat", rune(hint[i])) {
+			return nil, false
+		}
+		spec.length, spec.format = length, hint[i]
+		i++
+		if isSeparator(i) {
+			spec.separator = hint[i]
+			i++
+			if spec.repeat && isSeparator(i) {
+				spec.terminator = hint[i]
+				i++
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, len(specs) > 0
+}
+
+// fprintOctetHint appends the octets formatted with the
+// specifications, the last one is applied until the octets are
+// exhausted.
+func fprintOctetHint(sb *strings.Builder, specs []octetSpec, v string) {
+	for i := 0; len(v) > 0; {
+		spec := specs[i]
+		if i < len(specs)-1 {
+			i++
+		}
+		count := 1
+		if spec.repeat {
+			count, v = int(v[0]), v[1:]
+		}
+		for j := 0; j < count && len(v) > 0; j++ {
+			n := generics.Min(spec.length, len(v))
+			fprintOctetValue(sb, spec, v[:n])
+			v = v[n:]
+			switch {
+			case spec.terminator != 0 && j == count-1:
+				sb.WriteByte(spec.terminator)
+			case spec.separator != 0 && len(v) > 0:
+				sb.WriteByte(spec.separator)
+			}
+		}
+	}
+}
+
+func fprintOctetValue(sb *strings.Builder, spec octetSpec, v string) {
+	if spec.format == 'a' || spec.format == 't' {
+		sb.WriteString(v)
+		return
+	}
+	var n uint64
+	for i := 0; i < len(v); i++ {
+		n = n<<8 | uint64(v[i])
+	}
+	switch spec.format {
+	case 'x':
+		digits := strconv.FormatUint(n, 16)
+		if pad := 2*len(v) - len(digits); pad > 0 {
+			sb.WriteString(strings.Repeat("0", pad))
+		}
+		sb.WriteString(digits)
+	case 'o':
+		sb.WriteString(strconv.FormatUint(n, 8))
+	case 'd':
+		sb.WriteString(strconv.FormatUint(n, 10))
+	}
+}
@@ -0,0 +1,122 @@
+package smi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+const testFormatModule = `FORMAT-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, Integer32, enterprises FROM SNMPv2-SMI
+    TEXTUAL-CONVENTION FROM SNMPv2-TC
+    InetAddress, InetAddressIPv6, InetPortNumber FROM INET-ADDRESS-MIB;
+
+FmtHex ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "x"
+    STATUS current
+    DESCRIPTION ""
+    SYNTAX Integer32
+
+FmtPrefix ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "1d.1d.1d.1d/1d"
+    STATUS current
+    DESCRIPTION ""
+    SYNTAX OCTET STRING (SIZE (5))
+
+FmtRepeat ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "*1x:;"
+    STATUS current
+    DESCRIPTION ""
+    SYNTAX OCTET STRING
+
+FmtBad ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "1q"
+    STATUS current
+    DESCRIPTION ""
+    SYNTAX OCTET STRING
+
+fmtAddr OBJECT-TYPE SYNTAX InetAddress MAX-ACCESS read-only STATUS current ::= { enterprises 7 1 }
+fmtAddr6 OBJECT-TYPE SYNTAX InetAddressIPv6 MAX-ACCESS read-only STATUS current ::= { enterprises 7 2 }
+fmtPort OBJECT-TYPE SYNTAX InetPortNumber MAX-ACCESS read-only STATUS current ::= { enterprises 7 3 }
+fmtHex OBJECT-TYPE SYNTAX FmtHex MAX-ACCESS read-only STATUS current ::= { enterprises 7 4 }
+fmtPrefix OBJECT-TYPE SYNTAX FmtPrefix MAX-ACCESS read-only STATUS current ::= { enterprises 7 5 }
+fmtRepeat OBJECT-TYPE SYNTAX FmtRepeat MAX-ACCESS read-only STATUS current ::= { enterprises 7 6 }
+fmtBad OBJECT-TYPE SYNTAX FmtBad MAX-ACCESS read-only STATUS current ::= { enterprises 7 7 }
+fmtOctets OBJECT-TYPE SYNTAX OCTET STRING MAX-ACCESS read-only STATUS current ::= { enterprises 7 8 }
+END`
+
+var testDataFormat = []struct {
+	name  string
+	tag   asn.Tag
+	value interface{}
+	res   string
+}{
+	{name: "ifAdminStatus.2", tag: asn.TagInteger32, value: int32(1), res: "up(1)"},
+	{name: "ifAdminStatus.2", tag: asn.TagInteger32, value: int32(9), res: "9"},
+	{name: "ifType.1", tag: asn.TagInteger32, value: int32(6), res: "ethernetCsmacd(6)"},
+	{name: "ifIndex.1", tag: asn.TagInteger32, value: int32(1), res: "1"},
+	{name: "ifPhysAddress.1", tag: asn.TagOctetString, value: "\x00\x1a\x2b\x3c\x4d\x5e", res: "00:1a:2b:3c:4d:5e"},
+	{name: "ifPhysAddress.1", tag: asn.TagOctetString, value: "", res: ""},
+	{name: "sysDescr.0", tag: asn.TagOctetString, value: "Linux box", res: "Linux box"},
+	{name: "sysDescr.0", tag: asn.TagInteger32, value: int32(1), res: "1"},
+	{name: "sysUpTime.0", tag: asn.TagTimeTicks, value: uint32(100), res: "100"},
+	{name: "acmeUserMac.1.97", tag: asn.TagOctetString, value: "\x00\x01\x02\xfd\xfe\xff", res: "00:01:02:fd:fe:ff"},
+	{name: "acmeUserAdmin.1.97", tag: asn.TagInteger32, value: int32(2), res: "false(2)"},
+	{name: "acmeUserStatus.1.97", tag: asn.TagInteger32, value: int32(4), res: "createAndGo(4)"},
+	{name: "acmeUserCreated.1.97", tag: asn.TagOctetString, value: "\x07\xea\x01\x02\x0d\x1e\x0f\x00", res: "2026-1-2,13:30:15.0"},
+	{name: "acmeUserCreated.1.97", tag: asn.TagOctetString, value: "\x07\xea\x01\x02\x0d\x1e\x0f\x00+\x02\x00", res: "2026-1-2,13:30:15.0,+2:0"},
+	{name: "acmeFeatures.0", tag: asn.TagOctetString, value: "\x81\x40", res: "{alpha(0) gamma(7) 9}"},
+	{name: "acmeFeatures.0", tag: asn.TagOctetString, value: "", res: "{}"},
+	{name: "acmeLevel.0", tag: asn.TagGauge32, value: uint32(4250), res: "42.50"},
+	{name: "acmeLevel.0", tag: asn.TagGauge32, value: uint32(5), res: "0.05"},
+	{name: "acmeTemperature.0", tag: asn.TagInteger32, value: int32(-40), res: "-40"},
+	{name: "fmtAddr.0", tag: asn.TagOctetString, value: "\x0a\x00\x00\x01", res: "10.0.0.1"},
+	{name: "fmtAddr.0", tag: asn.TagOctetString, value: "\xfe\x80" + strings.Repeat("\x00", 13) + "\x01\x00\x00\x00\x03", res: "fe80::1%3"},
+	{name: "fmtAddr.0", tag: asn.TagOctetString, value: "host.example", res: "host.example"},
+	{name: "fmtAddr6.0", tag: asn.TagOctetString, value: "\x20\x01\x0d\xb8" + strings.Repeat("\x00", 11) + "\x01", res: "2001:0db8:0000:0000:0000:0000:0000:0001"},
+	{name: "fmtPort.0", tag: asn.TagGauge32, value: uint32(161), res: "161"},
+	{name: "fmtHex.0", tag: asn.TagInteger32, value: int32(-255), res: "-ff"},
+	{name: "fmtPrefix.0", tag: asn.TagOctetString, value: "\xc0\xa8\x01\x00\x18", res: "192.168.1.0/24"},
+	{name: "fmtRepeat.0", tag: asn.TagOctetString, value: "\x02\xaa\xbb\x01\xcc", res: "aa:bb;cc;"},
+	{name: "fmtBad.0", tag: asn.TagOctetString, value: "abc", res: "abc"},
+	{name: "fmtOctets.0", tag: asn.TagOctetString, value: "\x00\xff", res: "00 ff"},
+	{name: "fmtOctets.0", tag: asn.TagNoSuchInstance, res: ""},
+}
+
+func TestFormat(t *testing.T) {
+	m := New()
+	if err := m.LoadDir("testdata"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Parse("test", strings.NewReader(testFormatModule)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	r := oid.NewRegistry()
+	if err := m.Register(r); err != nil {
+		t.Fatal(err)
+	}
+	f := asn.NewFormats()
+	m.RegisterFormats(f)
+	for i, test := range testDataFormat {
+		id, err := r.Resolve(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		f.FprintValue(&sb, asn.Varbind{Oid: id, Tag: test.tag, Value: test.value})
+		if sb.String() != test.res {
+			t.Errorf("TestFormat[%d]: %q", i, sb.String())
+		}
+	}
+	var sb strings.Builder
+	f.Fprint(&sb, asn.Varbind{Oid: oid.MustParse("1.3.6.1.2.1.2.2.1.7.2"), Tag: asn.TagInteger32, Value: int32(2)})
+	if sb.String() != "{1.3.6.1.2.1.2.2.1.7.2 Integer32: down(2)}" {
+		t.Errorf("TestFormat: %s", sb.String())
+	}
+}
@@ -0,0 +1,87 @@
+package smi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid/index"
+)
+
+// ErrIndex is returned if the object is not a row, or its index
+// objects can not be encoded.
+var ErrIndex = errors.New("smi: invalid index")
+
+// indexRow returns the row which defines the INDEX clause of the
+// row, i.e. the augmented row of AUGMENTS clause.
+func (m *MIB) indexRow(row *Object) (*Object, error) {
+	r := &resolver{mib: m}
+	for i := 0; row.Augments != "" && i < maxTypeChain; i++ {
+		mod := m.modules[row.Module]
+		augmented := r.findObject(mod, row.Augments)
+		if augmented == nil {
+			return nil, unresolved(mod, row.line, row.Augments)
+		}
+		row = augmented
+	}
+	if len(row.Index) == 0 {
+		return nil, fmt.Errorf("%w: %s is not row", ErrIndex, row.Name)
+	}
+	return row, nil
+}
+
+// IndexObjects returns the objects of the INDEX clause of the row.
+// The index of the row with AUGMENTS clause is the index of the
+// augmented row. IndexObjects requires Resolve.
+func (m *MIB) IndexObjects(row *Object) ([]*Object, error) {
+	row, err := m.indexRow(row)
+	if err != nil {
+		return nil, err
+	}
+	r := &resolver{mib: m}
+	mod := m.modules[row.Module]
+	objs := make([]*Object, len(row.Index))
+	for i, ind := range row.Index {
+		if objs[i] = r.findObject(mod, ind.Name); objs[i] == nil {
+			return nil, unresolved(mod, row.line, ind.Name)
+		}
+		if objs[i].Syntax == nil {
+			return nil, fmt.Errorf("%w: %s: %s has no syntax", ErrIndex, row.Name, ind.Name)
+		}
+	}
+	return objs, nil
+}
+
+// IndexSchema returns the encoding of the index of the row (see
+// IndexObjects). The OctetString objects of fixed size (e.g.
+// MacAddress) are encoded without length.
+func (m *MIB) IndexSchema(row *Object) (index.Schema, error) {
+	objs, err := m.IndexObjects(row)
+	if err != nil {
+		return nil, err
+	}
+	if row, err = m.indexRow(row); err != nil {
+		return nil, err
+	}
+	s := make(index.Schema, len(objs))
+	for i, obj := range objs {
+		s[i].Implied = row.Index[i].Implied
+		switch obj.Syntax.Tag() {
+		case asn.TagOctetString, asn.TagOpaque:
+			s[i].Kind = index.OctetString
+			if sizes := obj.Syntax.Sizes; len(sizes) == 1 && sizes[0].Min == sizes[0].Max {
+				s[i].Size = int(sizes[0].Min)
+			}
+		case asn.TagIpAddress:
+			s[i].Kind = index.IpAddress
+		case asn.TagObjectId:
+			s[i].Kind = index.ObjectId
+		default:
+			s[i].Kind = index.Integer
+		}
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrIndex, row.Name, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,126 @@
+package smi
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	// tokBinary is binary or hex string (e.g. '0A'H), the text
+	// includes the quotes and the suffix.
+	tokBinary
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of file"
+	case tokString:
+		return "string"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits the source into tokens. The comments (from -- to the
+// next -- or to the end of line) are skipped.
+func lex(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			i += 2
+			for i < len(src) && src[i] != '\n' {
+				if src[i] == '-' && i+1 < len(src) && src[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start, startLine := i+1, line
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i == len(src) {
+					return nil, fmt.Errorf("line %d: unterminated string", startLine)
+				}
+				if src[i] == '"' {
+					if i+1 < len(src) && src[i+1] == '"' {
+						// escaped quote
+						sb.WriteString(src[start : i+1])
+						i++
+						start = i + 1
+						continue
+					}
+					break
+				}
+				if src[i] == '\n' {
+					line++
+				}
+			}
+			sb.WriteString(src[start:i])
+			toks = append(toks, token{kind: tokString, text: sb.String(), line: startLine})
+			i++
+		case c == '\'':
+			j := strings.IndexByte(src[i+1:], '\'')
+			if j < 0 || i+j+2 >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated binary string", line)
+			}
+			end := i + j + 3
+			toks = append(toks, token{kind: tokBinary, text: src[i:end], line: line})
+			i = end
+		case isDigit(c) || c == '-' && i+1 < len(src) && isDigit(src[i+1]):
+			j := i + 1
+			for j < len(src) && isDigit(src[j]) {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], line: line})
+			i = j
+		case isLetter(c):
+			j := i + 1
+			for j < len(src) && (isLetter(src[j]) || isDigit(src[j]) || src[j] == '_' ||
+				src[j] == '-' && j+1 < len(src) && (isLetter(src[j+1]) || isDigit(src[j+1]))) {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], line: line})
+			i = j
+		case strings.HasPrefix(src[i:], "::="):
+			toks = append(toks, token{kind: tokPunct, text: "::=", line: line})
+			i += 3
+		case strings.HasPrefix(src[i:], ".."):
+			toks = append(toks, token{kind: tokPunct, text: "..", line: line})
+			i += 2
+		default:
+			toks = append(toks, token{kind: tokPunct, text: src[i : i+1], line: line})
+			i++
+		}
+	}
+	return append(toks, token{kind: tokEOF, line: line}), nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
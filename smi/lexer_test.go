@@ -0,0 +1,75 @@
+package smi
+
+import (
+	"strings"
+	"testing"
+)
+
+var testDataLex = []struct {
+	src  string
+	toks []string
+	err  bool
+}{
+	{
+		src:  `ifIndex OBJECT-TYPE ::= { ifEntry 1 }`,
+		toks: []string{"ifIndex", "OBJECT-TYPE", "::=", "{", "ifEntry", "1", "}"},
+	},
+	{
+		src:  "a -- comment\n b -- inline -- c",
+		toks: []string{"a", "b", "c"},
+	},
+	{
+		src:  "mib-2 -- trailing --\n x--y",
+		toks: []string{"mib-2", "x"},
+	},
+	{
+		src:  `(-2147483648..2147483647 | 'FF'H | '0101'B)`,
+		toks: []string{"(", "-2147483648", "..", "2147483647", "|", "'FF'H", "|", "'0101'B", ")"},
+	},
+	{
+		src:  `DESCRIPTION "a ""quoted"" -- word"`,
+		toks: []string{"DESCRIPTION", `a "quoted" -- word`},
+	},
+	{
+		src:  "name-",
+		toks: []string{"name", "-"},
+	},
+	{src: `"unterminated`, err: true},
+	{src: `'FF`, err: true},
+}
+
+func TestLex(t *testing.T) {
+	for i, test := range testDataLex {
+		toks, err := lex(test.src)
+		if test.err {
+			if err == nil {
+				t.Errorf("TestLex[%d]: no error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestLex[%d]: %v", i, err)
+			continue
+		}
+		var res []string
+		for _, tok := range toks[:len(toks)-1] {
+			res = append(res, tok.text)
+		}
+		if strings.Join(res, " ") != strings.Join(test.toks, " ") {
+			t.Errorf("TestLex[%d]: %q", i, res)
+		}
+	}
+}
+
+func TestLexLines(t *testing.T) {
+	toks, err := lex("a\n\"b\nc\"\n-- d\ne")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := []int{1, 2, 5}
+	for i, line := range lines {
+		if toks[i].line != line {
+			t.Errorf("TestLexLines[%d]: line %d", i, toks[i].line)
+		}
+	}
+}
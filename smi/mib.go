@@ -0,0 +1,349 @@
+package smi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// ErrDuplicateModule is returned if the module is already added
+// to the MIB.
+var ErrDuplicateModule = errors.New("smi: duplicate module")
+
+// roots are the top-level arcs which are not defined by modules.
+var roots = map[string]uint32{
+	"ccitt":           0,
+	"iso":             1,
+	"joint-iso-ccitt": 2,
+}
+
+// MIB is the collection of modules. The modules are added with
+// Parse, ParseFile or LoadDir, then Resolve computes the oids and
+// the types. MIB is not safe for concurrent updates.
+type MIB struct {
+	modules map[string]*Module
+	order   []*Module
+	objects *oid.Tree[*Object]
+}
+
+func New() *MIB {
+	return &MIB{
+		modules: make(map[string]*Module),
+		objects: oid.NewTree[*Object](),
+	}
+}
+
+// Parse parses the modules read from r and adds them to the MIB.
+// The name (e.g. the file name) is used in errors.
+func (m *MIB) Parse(name string, r io.Reader) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	mods, err := parseModules(name, string(src))
+	if err != nil {
+		return err
+	}
+	for _, mod := range mods {
+		if m.modules[mod.Name] != nil {
+			return fmt.Errorf("%w: %s in %s", ErrDuplicateModule, mod.Name, name)
+		}
+	}
+	for _, mod := range mods {
+		m.modules[mod.Name] = mod
+		m.order = append(m.order, mod)
+	}
+	return nil
+}
+
+// ParseFile parses the modules of the file and adds them to the
+// MIB.
+func (m *MIB) ParseFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.Parse(path, f)
+}
+
+// LoadDir parses the files of the directory (except hidden files
+// and subdirectories) in the order of names.
+func (m *MIB) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if err := m.ParseFile(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Module returns the module, or nil.
+func (m *MIB) Module(name string) *Module {
+	return m.modules[name]
+}
+
+// Modules returns the modules in the order they were added.
+func (m *MIB) Modules() []*Module {
+	return m.order
+}
+
+// splitName splits the name qualified with the module (e.g.
+// IF-MIB::ifDescr).
+func splitName(name string) (module, local string) {
+	if ind := strings.Index(name, "::"); ind >= 0 {
+		return name[:ind], name[ind+2:]
+	}
+	return "", name
+}
+
+// Object returns the object with the name, which may be qualified
+// with the module (e.g. IF-MIB::ifDescr). The unqualified name is
+// searched in the modules in the order they were added. It returns
+// nil if there is no such object.
+func (m *MIB) Object(name string) *Object {
+	module, name := splitName(name)
+	if module != "" {
+		if mod := m.modules[module]; mod != nil {
+			return mod.objects[name]
+		}
+		return nil
+	}
+	for _, mod := range m.order {
+		if obj := mod.objects[name]; obj != nil {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Type returns the type with the name (see Object).
+func (m *MIB) Type(name string) *Type {
+	module, name := splitName(name)
+	if module != "" {
+		if mod := m.modules[module]; mod != nil {
+			return mod.types[name]
+		}
+		return nil
+	}
+	for _, mod := range m.order {
+		if t := mod.types[name]; t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// Lookup returns the object of the longest oid prefix and the
+// remaining subids (e.g. the instance index of column). It returns
+// nil if there is no such object. Lookup requires Resolve.
+func (m *MIB) Lookup(id []uint32) (obj *Object, suffix []uint32) {
+	n, obj, ok := m.objects.LongestPrefix(id)
+	if !ok {
+		return nil, nil
+	}
+	return obj, id[n:]
+}
+
+// Walk calls fn for the resolved objects in the order of oids until
+// fn returns false. If several modules define the same oid, the
+// object of the module added first is visited.
+func (m *MIB) Walk(fn func(obj *Object) bool) {
+	m.objects.Walk(func(id []uint32, obj *Object) bool {
+		return fn(obj)
+	})
+}
+
+// Register adds the names of the objects to the registry.
+func (m *MIB) Register(r *oid.Registry) error {
+	for _, mod := range m.order {
+		for _, obj := range mod.Objects {
+			if obj.Oid == nil {
+				continue
+			}
+			if err := r.Register(mod.Name, obj.Name, obj.Oid); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve computes the oids of the objects and resolves the types
+// of the modules. It returns ErrUnresolved if the name is neither
+// defined by the module nor imported from the module of the MIB.
+// Resolve may be called again after more modules are added.
+func (m *MIB) Resolve() error {
+	r := &resolver{mib: m, visiting: make(map[*Object]bool)}
+	m.objects.Clear()
+	for _, mod := range m.order {
+		for _, obj := range mod.Objects {
+			obj.Oid, obj.Parent, obj.Children = nil, nil, nil
+		}
+	}
+	for _, mod := range m.order {
+		for _, obj := range mod.Objects {
+			if err := r.resolveOid(obj); err != nil {
+				return err
+			}
+			if _, ok := m.objects.Get(obj.Oid); !ok {
+				m.objects.Insert(obj.Oid, obj)
+			}
+		}
+		for _, t := range mod.Types {
+			if err := r.resolveSyntax(mod, t.Syntax, t.line); err != nil {
+				return err
+			}
+		}
+		for _, obj := range mod.Objects {
+			if obj.Syntax == nil {
+				continue
+			}
+			if err := r.resolveSyntax(mod, obj.Syntax, obj.line); err != nil {
+				return err
+			}
+		}
+	}
+	for _, mod := range m.order {
+		for _, obj := range mod.Objects {
+			if _, parent, ok := m.objects.LongestPrefix(obj.Oid.Parent()); ok {
+				obj.Parent = parent
+			}
+		}
+	}
+	m.objects.Walk(func(id []uint32, obj *Object) bool {
+		if obj.Parent != nil {
+			obj.Parent.Children = append(obj.Parent.Children, obj)
+		}
+		return true
+	})
+	return nil
+}
+
+type resolver struct {
+	mib *MIB
+	// visiting detects the loops of oid definitions.
+	visiting map[*Object]bool
+}
+
+func unresolved(mod *Module, line int, name string) error {
+	return fmt.Errorf("%w: %s:%d: %s", ErrUnresolved, mod.Name, line, name)
+}
+
+// findObject returns the object defined by or imported to the
+// module.
+func (r *resolver) findObject(mod *Module, name string) *Object {
+	for i := 0; mod != nil && i < len(r.mib.order); i++ {
+		if obj := mod.objects[name]; obj != nil {
+			return obj
+		}
+		mod = r.mib.modules[mod.Imports[name]]
+	}
+	return nil
+}
+
+// findType returns the type defined by or imported to the module.
+func (r *resolver) findType(mod *Module, name string) *Type {
+	for i := 0; mod != nil && i < len(r.mib.order); i++ {
+		if t := mod.types[name]; t != nil {
+			return t
+		}
+		mod = r.mib.modules[mod.Imports[name]]
+	}
+	return nil
+}
+
+func (r *resolver) resolveOid(obj *Object) error {
+	if obj.Oid != nil {
+		return nil
+	}
+	mod := r.mib.modules[obj.Module]
+	var id oid.OID
+	switch subid, ok := roots[obj.parent]; {
+	case obj.parent == "":
+	case ok && r.findObject(mod, obj.parent) == nil:
+		id = oid.OID{subid}
+	default:
+		parent := r.findObject(mod, obj.parent)
+		if parent == nil {
+			return unresolved(mod, obj.line, obj.parent)
+		}
+		if r.visiting[parent] {
+			return fmt.Errorf("%w: %s:%d: loop of %s", ErrUnresolved, mod.Name, obj.line, obj.Name)
+		}
+		r.visiting[obj] = true
+		err := r.resolveOid(parent)
+		delete(r.visiting, obj)
+		if err != nil {
+			return err
+		}
+		id = parent.Oid
+	}
+	if len(id)+len(obj.subids) > oid.MaxLength {
+		return fmt.Errorf("%w: %s:%d: oid of %s is too long", ErrUnresolved, mod.Name, obj.line, obj.Name)
+	}
+	obj.Oid = id.Append(obj.subids...)
+	return nil
+}
+
+// maxTypeChain limits the chain of types to detect loops.
+const maxTypeChain = 32
+
+// resolveSyntax sets the base type of the syntax. The display hint
+// and the restrictions which are not set are inherited from the
+// type chain.
+func (r *resolver) resolveSyntax(mod *Module, s *Syntax, line int) error {
+	return r.resolveChain(mod, s, line, 0)
+}
+
+func (r *resolver) resolveChain(mod *Module, s *Syntax, line, depth int) error {
+	if s.SequenceOf != "" || s.Base != "" {
+		return nil
+	}
+	if _, ok := baseTypes[s.Type]; ok {
+		s.Base = s.Type
+		return nil
+	}
+	if depth == 0 && mod.rows[s.Type] {
+		// the syntax of table row
+		return nil
+	}
+	if depth == maxTypeChain {
+		return fmt.Errorf("%w: %s:%d: loop of %s", ErrUnresolved, mod.Name, line, s.Type)
+	}
+	t := r.findType(mod, s.Type)
+	if t == nil {
+		return unresolved(mod, line, s.Type)
+	}
+	if err := r.resolveChain(r.mib.modules[t.Module], t.Syntax, t.line, depth+1); err != nil {
+		return err
+	}
+	s.TC = t
+	s.Base = t.Syntax.Base
+	s.DisplayHint = t.DisplayHint
+	if s.DisplayHint == "" {
+		s.DisplayHint = t.Syntax.DisplayHint
+	}
+	if s.Enums == nil {
+		s.Enums = t.Syntax.Enums
+	}
+	if s.Ranges == nil {
+		s.Ranges = t.Syntax.Ranges
+	}
+	if s.Sizes == nil {
+		s.Sizes = t.Syntax.Sizes
+	}
+	return nil
+}
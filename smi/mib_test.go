@@ -0,0 +1,272 @@
+package smi
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/oid/index"
+)
+
+func loadTestMIB(t *testing.T) *MIB {
+	m := New()
+	if err := m.LoadDir("testdata"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+var testDataResolveOid = []struct {
+	name string
+	id   string
+}{
+	{name: "internet", id: "1.3.6.1"},
+	{name: "zeroDotZero", id: "0.0"},
+	{name: "sysUpTime", id: "1.3.6.1.2.1.1.3"},
+	{name: "SNMPv2-MIB::sysORDescr", id: "1.3.6.1.2.1.1.9.1.3"},
+	{name: "ifDescr", id: "1.3.6.1.2.1.2.2.1.2"},
+	{name: "ifAlias", id: "1.3.6.1.2.1.31.1.1.1.18"},
+	{name: "snmpTrapOID", id: "1.3.6.1.6.3.1.1.4.1"},
+	{name: "linkDown", id: "1.3.6.1.6.3.1.1.5.3"},
+	{name: "systemGroup", id: "1.3.6.1.6.3.1.2.2.6"},
+	{name: "snmpBasicComplianceRev2", id: "1.3.6.1.6.3.1.2.1.3"},
+	{name: "acmePeerLoad", id: "1.3.6.1.4.1.9999.1.3.1.2"},
+	{name: "acmeOverload", id: "1.3.6.1.4.1.9999.0.3"},
+	{name: "ACME-TEST-MIB::acmeTestMIB", id: "1.3.6.1.4.1.99999"},
+	{name: "acmeLevelHigh", id: "1.3.6.1.4.1.99999.2.0.1"},
+}
+
+func TestResolveOid(t *testing.T) {
+	m := loadTestMIB(t)
+	for i, test := range testDataResolveOid {
+		obj := m.Object(test.name)
+		if obj == nil || obj.Oid.String() != test.id {
+			t.Errorf("TestResolveOid[%d]: %+v", i, obj)
+		}
+	}
+}
+
+var testDataResolveSyntax = []struct {
+	name  string
+	base  string
+	tag   asn.Tag
+	tc    string
+	hint  string
+	enums int
+	sizes []Range
+}{
+	{name: "sysDescr", base: "OCTET STRING", tag: asn.TagOctetString, tc: "DisplayString", hint: "255a", sizes: []Range{{0, 255}}},
+	{name: "sysObjectID", base: "OBJECT IDENTIFIER", tag: asn.TagObjectId},
+	{name: "sysORLastChange", base: "TimeTicks", tag: asn.TagTimeTicks, tc: "TimeStamp"},
+	{name: "ifIndex", base: "Integer32", tag: asn.TagInteger32, tc: "InterfaceIndex", hint: "d"},
+	{name: "ifType", base: "INTEGER", tag: asn.TagInteger32, tc: "IANAifType", enums: 10},
+	{name: "ifPhysAddress", base: "OCTET STRING", tag: asn.TagOctetString, tc: "PhysAddress", hint: "1x:"},
+	{name: "ifOperStatus", base: "INTEGER", tag: asn.TagInteger32, enums: 7},
+	{name: "ifHCInOctets", base: "Counter64", tag: asn.TagCounter64},
+	{name: "ifAlias", base: "OCTET STRING", tag: asn.TagOctetString, tc: "DisplayString", hint: "255a", sizes: []Range{{0, 64}}},
+	{name: "snmpSetSerialNo", base: "INTEGER", tag: asn.TagInteger32, tc: "TestAndIncr"},
+	{name: "acmeUptime", base: "Counter", tag: asn.TagCounter32},
+	{name: "acmePeerAddress", base: "IpAddress", tag: asn.TagIpAddress},
+	{name: "acmeFeatures", base: "BITS", tag: asn.TagOctetString, enums: 3},
+	{name: "acmeLevel", base: "Unsigned32", tag: asn.TagGauge32, tc: "AcmeLevel", hint: "d-2"},
+	{name: "acmeUserName", base: "OCTET STRING", tag: asn.TagOctetString, tc: "AcmeShortName", hint: "255a", sizes: []Range{{1, 8}}},
+	{name: "acmeUserMac", base: "OCTET STRING", tag: asn.TagOctetString, tc: "MacAddress", hint: "1x:", sizes: []Range{{6, 6}}},
+	{name: "acmeUserAdmin", base: "INTEGER", tag: asn.TagInteger32, tc: "TruthValue", enums: 2},
+	{name: "acmeUserCreated", base: "OCTET STRING", tag: asn.TagOctetString, tc: "DateAndTime", hint: "2d-1d-1d,1d:1d:1d.1d,1a1d:1d", sizes: []Range{{8, 8}, {11, 11}}},
+	{name: "acmeUserStatus", base: "INTEGER", tag: asn.TagInteger32, tc: "RowStatus", enums: 6},
+}
+
+func TestResolveSyntax(t *testing.T) {
+	m := loadTestMIB(t)
+	for i, test := range testDataResolveSyntax {
+		obj := m.Object(test.name)
+		if obj == nil || obj.Syntax == nil {
+			t.Errorf("TestResolveSyntax[%d]: %+v", i, obj)
+			continue
+		}
+		s := obj.Syntax
+		tc := ""
+		if s.TC != nil {
+			tc = s.TC.Name
+		}
+		if s.Base != test.base || s.Tag() != test.tag || tc != test.tc ||
+			s.DisplayHint != test.hint || len(s.Enums) != test.enums {
+			t.Errorf("TestResolveSyntax[%d]: %s %v %s %q %d", i, s.Base, s.Tag(), tc, s.DisplayHint, len(s.Enums))
+		}
+		if test.sizes != nil && !equalRanges(s.Sizes, test.sizes) {
+			t.Errorf("TestResolveSyntax[%d]: sizes %v", i, s.Sizes)
+		}
+	}
+}
+
+func equalRanges(r1, r2 []Range) bool {
+	if len(r1) != len(r2) {
+		return false
+	}
+	for i := range r1 {
+		if r1[i] != r2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestResolveTable(t *testing.T) {
+	m := loadTestMIB(t)
+	table, row := m.Object("ifXTable"), m.Object("ifXEntry")
+	if !table.IsTable() || table.IsRow() || !row.IsRow() || row.Augments != "ifEntry" ||
+		row.Parent != table || len(table.Children) != 1 || table.Children[0] != row {
+		t.Errorf("TestResolveTable: ifXTable %+v", table)
+	}
+	var names []string
+	for _, col := range row.Children {
+		if !col.IsColumn() || col.IsScalar() {
+			t.Errorf("TestResolveTable: column %s", col.Name)
+		}
+		names = append(names, col.Name)
+	}
+	if strings.Join(names, " ") != "ifName ifHCInOctets ifLinkUpDownTrapEnable ifHighSpeed ifAlias" {
+		t.Errorf("TestResolveTable: columns %v", names)
+	}
+	if obj := m.Object("sysUpTime"); !obj.IsScalar() || obj.Parent != m.Object("system") {
+		t.Errorf("TestResolveTable: sysUpTime %+v", obj)
+	}
+	if obj := m.Object("linkDown"); obj.IsScalar() || obj.Parent != m.Object("snmpTraps") {
+		t.Errorf("TestResolveTable: linkDown %+v", obj)
+	}
+}
+
+var testDataLookup = []struct {
+	id     string
+	name   string
+	suffix string
+}{
+	{id: "1.3.6.1.2.1.2.2.1.2.3", name: "ifDescr", suffix: "3"},
+	{id: "1.3.6.1.2.1.1.3.0", name: "sysUpTime", suffix: "0"},
+	{id: "1.3.6.1.2.1.31.1.1.1.18", name: "ifAlias", suffix: ""},
+	{id: "1.3.6.1.4.1.99999.1.5.1.2.7.3.97.98.99", name: "acmeUserName", suffix: "7.3.97.98.99"},
+	{id: "1.3.6.1.4.1.12345", name: "enterprises", suffix: "12345"},
+	{id: "1.2", name: "", suffix: ""},
+}
+
+func TestLookup(t *testing.T) {
+	m := loadTestMIB(t)
+	for i, test := range testDataLookup {
+		obj, suffix := m.Lookup(oid.MustParse(test.id))
+		name := ""
+		if obj != nil {
+			name = obj.Name
+		}
+		if name != test.name || oid.String(suffix) != test.suffix {
+			t.Errorf("TestLookup[%d]: %s %v", i, name, suffix)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	m := loadTestMIB(t)
+	r := oid.NewRegistry()
+	if err := m.Register(r); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := r.Resolve("ifAdminStatus.2"); err != nil || id.String() != "1.3.6.1.2.1.2.2.1.7.2" {
+		t.Errorf("TestRegister: %s, %v", id, err)
+	}
+	if id, err := r.Resolve("RFC1155-SMI::enterprises"); err != nil || id.String() != "1.3.6.1.4.1" {
+		t.Errorf("TestRegister: %s, %v", id, err)
+	}
+	if s := r.Describe(oid.MustParse("1.3.6.1.4.1.99999.1.5.1.7.1")); s != "acmeUserStatus.1" {
+		t.Errorf("TestRegister: %s", s)
+	}
+}
+
+var testDataResolveError = []struct {
+	src []string
+	err error
+}{
+	{
+		src: []string{"M DEFINITIONS ::= BEGIN\nx OBJECT IDENTIFIER ::= { y 1 }\nEND"},
+		err: ErrUnresolved,
+	},
+	{
+		src: []string{"M DEFINITIONS ::= BEGIN\nIMPORTS y FROM N;\nx OBJECT IDENTIFIER ::= { y 1 }\nEND"},
+		err: ErrUnresolved,
+	},
+	{
+		src: []string{
+			"M DEFINITIONS ::= BEGIN\nIMPORTS y FROM N;\nx OBJECT IDENTIFIER ::= { y 1 }\nEND",
+			"N DEFINITIONS ::= BEGIN\nIMPORTS x FROM M;\ny OBJECT IDENTIFIER ::= { x 1 }\nEND",
+		},
+		err: ErrUnresolved,
+	},
+	{
+		src: []string{"M DEFINITIONS ::= BEGIN\nx OBJECT IDENTIFIER ::= { x 1 }\nEND"},
+		err: ErrUnresolved,
+	},
+	{
+		src: []string{"M DEFINITIONS ::= BEGIN\nx OBJECT-TYPE\n SYNTAX T\n ::= { iso 1 }\nEND"},
+		err: ErrUnresolved,
+	},
+	{
+		src: []string{"M DEFINITIONS ::= BEGIN\nT ::= U\nU ::= T\nEND"},
+		err: ErrUnresolved,
+	},
+	{
+		src: []string{
+			"M DEFINITIONS ::= BEGIN\nEND",
+			"M DEFINITIONS ::= BEGIN\nEND",
+		},
+		err: ErrDuplicateModule,
+	},
+}
+
+func TestResolveError(t *testing.T) {
+	for i, test := range testDataResolveError {
+		m := New()
+		var err error
+		for _, src := range test.src {
+			if err = m.Parse("test", strings.NewReader(src)); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = m.Resolve()
+		}
+		if !errors.Is(err, test.err) {
+			t.Errorf("TestResolveError[%d]: error %v", i, err)
+		}
+	}
+}
+
+var testDataIndexSchema = []struct {
+	row    string
+	schema index.Schema
+}{
+	{row: "ifEntry", schema: index.Schema{{Kind: index.Integer}}},
+	{row: "ifXEntry", schema: index.Schema{{Kind: index.Integer}}},
+	{row: "acmePeerEntry", schema: index.Schema{{Kind: index.IpAddress}}},
+	{row: "acmeUserEntry", schema: index.Schema{{Kind: index.Integer}, {Kind: index.OctetString, Implied: true}}},
+	{row: "acmePortEntry", schema: index.Schema{{Kind: index.Integer}}},
+}
+
+func TestIndexSchema(t *testing.T) {
+	m := loadTestMIB(t)
+	for i, test := range testDataIndexSchema {
+		s, err := m.IndexSchema(m.Object(test.row))
+		if err != nil || !reflect.DeepEqual(s, test.schema) {
+			t.Errorf("TestIndexSchema[%d]: %v, %v", i, s, err)
+		}
+	}
+	if objs, err := m.IndexObjects(m.Object("ifXEntry")); err != nil || len(objs) != 1 || objs[0] != m.Object("ifIndex") {
+		t.Errorf("TestIndexSchema: ifXEntry %v, %v", objs, err)
+	}
+	if _, err := m.IndexSchema(m.Object("sysDescr")); !errors.Is(err, ErrIndex) {
+		t.Errorf("TestIndexSchema: sysDescr error %v", err)
+	}
+}
@@ -0,0 +1,557 @@
+package smi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseError is the panic value of parser, it is recovered by
+// parseModules.
+type parseError struct {
+	err error
+}
+
+type parser struct {
+	file string
+	toks []token
+	pos  int
+	mod  *Module
+}
+
+// parseModules parses the modules of the source file.
+func parseModules(file, src string) (mods []*Module, err error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrSyntax, file, err)
+	}
+	p := &parser{file: file, toks: toks}
+	defer func() {
+		if r := recover(); r != nil {
+			pe, ok := r.(parseError)
+			if !ok {
+				panic(r)
+			}
+			mods, err = nil, pe.err
+		}
+	}()
+	for p.peek().kind != tokEOF {
+		mods = append(mods, p.parseModule())
+	}
+	if len(mods) == 0 {
+		p.errorf("no module")
+	}
+	return
+}
+
+func (p *parser) errorf(format string, args ...any) {
+	p.errorAt(p.peek().line, format, args...)
+}
+
+func (p *parser) errorAt(line int, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	panic(parseError{fmt.Errorf("%w: %s:%d: %s", ErrSyntax, p.file, line, msg)})
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+// peekAt returns the token which follows the next one by n tokens.
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.pos+n]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// is tests whether the next token has the text.
+func (p *parser) is(text string) bool {
+	tok := p.peek()
+	return tok.kind != tokString && tok.text == text
+}
+
+// accept consumes the next token if it has the text.
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) {
+	if !p.accept(text) {
+		p.errorf("expected %q, found %s", text, p.peek())
+	}
+}
+
+func (p *parser) ident() string {
+	tok := p.peek()
+	if tok.kind != tokIdent {
+		p.errorf("expected identifier, found %s", tok)
+	}
+	p.next()
+	return tok.text
+}
+
+func (p *parser) str() string {
+	tok := p.peek()
+	if tok.kind != tokString {
+		p.errorf("expected string, found %s", tok)
+	}
+	p.next()
+	return tok.text
+}
+
+func (p *parser) number() int64 {
+	tok := p.peek()
+	var v int64
+	var err error
+	switch tok.kind {
+	case tokNumber:
+		v, err = strconv.ParseInt(tok.text, 10, 64)
+		if err != nil && tok.text[0] != '-' {
+			// the upper bound of Counter64 or Unsigned64 ranges
+			v, err = math.MaxInt64, nil
+		}
+	case tokBinary:
+		v, err = parseBinary(tok.text)
+	case tokIdent:
+		switch tok.text {
+		case "MIN":
+			v = math.MinInt64
+		case "MAX":
+			v = math.MaxInt64
+		default:
+			p.errorf("expected number, found %s", tok)
+		}
+	default:
+		p.errorf("expected number, found %s", tok)
+	}
+	if err != nil {
+		p.errorf("invalid number %s", tok)
+	}
+	p.next()
+	return v
+}
+
+func (p *parser) subid() uint32 {
+	tok := p.peek()
+	v, err := strconv.ParseUint(tok.text, 10, 32)
+	if tok.kind != tokNumber || err != nil {
+		p.errorf("expected subidentifier, found %s", tok)
+	}
+	p.next()
+	return uint32(v)
+}
+
+// parseBinary parses 'hh'H and 'bb'B literals.
+func parseBinary(s string) (int64, error) {
+	base := 16
+	if strings.HasSuffix(s, "B") || strings.HasSuffix(s, "b") {
+		base = 2
+	}
+	digits := s[1 : len(s)-2]
+	if digits == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(digits, base, 64)
+	if err != nil || v > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid literal %s", s)
+	}
+	return int64(v), nil
+}
+
+// skipTo skips the tokens up to the token with the text.
+func (p *parser) skipTo(text string) {
+	for !p.is(text) {
+		if p.peek().kind == tokEOF {
+			p.errorf("expected %q, found %s", text, p.peek())
+		}
+		p.next()
+	}
+}
+
+// skipBlock skips the block within the brackets (e.g. { }) starting
+// at the next token. It returns the text of the skipped tokens
+// within the brackets.
+func (p *parser) skipBlock(open, close string) string {
+	p.expect(open)
+	var words []string
+	for depth := 1; ; {
+		tok := p.next()
+		switch {
+		case tok.kind == tokEOF:
+			p.errorf("expected %q, found %s", close, tok)
+		case tok.kind == tokString:
+			words = append(words, strconv.Quote(tok.text))
+			continue
+		case tok.text == open:
+			depth++
+		case tok.text == close:
+			if depth--; depth == 0 {
+				return strings.Join(words, " ")
+			}
+		}
+		words = append(words, tok.text)
+	}
+}
+
+// parseModule parses the module:
+//
+//	name DEFINITIONS ::= BEGIN ... END
+func (p *parser) parseModule() *Module {
+	m := &Module{
+		Name:    p.ident(),
+		Imports: make(map[string]string),
+		objects: make(map[string]*Object),
+		types:   make(map[string]*Type),
+		rows:    make(map[string]bool),
+	}
+	p.mod = m
+	if p.is("{") {
+		p.skipBlock("{", "}")
+	}
+	p.expect("DEFINITIONS")
+	// e.g. IMPLICIT TAGS
+	p.skipTo("::=")
+	p.next()
+	p.expect("BEGIN")
+	for !p.accept("END") {
+		p.parseAssignment()
+	}
+	return m
+}
+
+func (p *parser) parseAssignment() {
+	tok := p.peek()
+	switch {
+	case tok.kind != tokIdent:
+		p.errorf("unexpected %s", tok)
+	case tok.text == "IMPORTS":
+		p.next()
+		p.parseImports()
+	case tok.text == "EXPORTS":
+		p.skipTo(";")
+		p.next()
+	case p.peekAt(1).text == "MACRO":
+		// the macros of SNMPv2-SMI, SNMPv2-TC, etc.
+		p.next()
+		p.next()
+		p.expect("::=")
+		p.expect("BEGIN")
+		p.skipTo("END")
+		p.next()
+	case isUpper(tok.text[0]):
+		p.parseTypeAssignment()
+	default:
+		p.parseValueAssignment()
+	}
+}
+
+func isUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
+
+// parseImports parses the list of imports:
+//
+//	name, ... FROM module ... ;
+func (p *parser) parseImports() {
+	line := p.peek().line
+	var names []string
+	for !p.accept(";") {
+		if p.accept(",") {
+			continue
+		}
+		name := p.ident()
+		if name != "FROM" {
+			names = append(names, name)
+			continue
+		}
+		module := p.ident()
+		for _, name := range names {
+			p.mod.Imports[name] = module
+		}
+		names = names[:0]
+	}
+	if len(names) > 0 {
+		p.errorAt(line, "imports without module")
+	}
+}
+
+func (p *parser) parseTypeAssignment() {
+	line := p.peek().line
+	t := &Type{Name: p.ident(), Module: p.mod.Name, line: line}
+	p.expect("::=")
+	switch {
+	case p.accept("TEXTUAL-CONVENTION"):
+		t.TextualConvention = true
+		p.parseTypeClauses(t)
+	case p.is("SEQUENCE") && p.peekAt(1).text == "{":
+		// the type of table rows
+		p.next()
+		p.skipBlock("{", "}")
+		p.mod.rows[t.Name] = true
+		return
+	case p.is("CHOICE"):
+		// e.g. ObjectSyntax of SMIv1
+		p.next()
+		p.skipBlock("{", "}")
+		return
+	default:
+		t.Syntax = p.parseSyntax()
+	}
+	if p.mod.types[t.Name] != nil {
+		p.errorAt(line, "%s is defined twice", t.Name)
+	}
+	p.mod.types[t.Name] = t
+	p.mod.Types = append(p.mod.Types, t)
+}
+
+func (p *parser) parseTypeClauses(t *Type) {
+	for {
+		switch p.peek().text {
+		case "DISPLAY-HINT":
+			p.next()
+			t.DisplayHint = p.str()
+		case "STATUS":
+			p.next()
+			t.Status = p.ident()
+		case "DESCRIPTION":
+			p.next()
+			t.Description = p.str()
+		case "REFERENCE":
+			p.next()
+			t.Reference = p.str()
+		case "SYNTAX":
+			p.next()
+			t.Syntax = p.parseSyntax()
+			// SYNTAX is the last clause
+			return
+		default:
+			p.errorf("unexpected %s in TEXTUAL-CONVENTION", p.peek())
+		}
+	}
+}
+
+var macroKinds = map[string]Kind{
+	"OBJECT-IDENTITY":    KindObjectIdentity,
+	"MODULE-IDENTITY":    KindModuleIdentity,
+	"OBJECT-TYPE":        KindObjectType,
+	"NOTIFICATION-TYPE":  KindNotificationType,
+	"TRAP-TYPE":          KindTrapType,
+	"OBJECT-GROUP":       KindObjectGroup,
+	"NOTIFICATION-GROUP": KindNotificationGroup,
+	"MODULE-COMPLIANCE":  KindModuleCompliance,
+	"AGENT-CAPABILITIES": KindAgentCapabilities,
+}
+
+func (p *parser) parseValueAssignment() {
+	line := p.peek().line
+	obj := &Object{Name: p.ident(), Module: p.mod.Name, line: line}
+	if p.accept("OBJECT") {
+		p.expect("IDENTIFIER")
+		obj.Kind = KindValue
+	} else {
+		tok := p.next()
+		kind, ok := macroKinds[tok.text]
+		if !ok || tok.kind != tokIdent {
+			p.pos--
+			p.errorf("unexpected %s after %s", tok, obj.Name)
+		}
+		obj.Kind = kind
+		switch kind {
+		case KindModuleCompliance, KindAgentCapabilities:
+			p.skipTo("::=")
+		default:
+			p.parseClauses(obj)
+		}
+	}
+	p.expect("::=")
+	if obj.Kind == KindTrapType {
+		// SMIv1 traps are converted to SMIv2 notifications as
+		// specified by RFC 3584, 3.
+		obj.parent, obj.subids = obj.Enterprise, []uint32{0, p.subid()}
+	} else {
+		p.parseOid(obj)
+	}
+	if p.mod.objects[obj.Name] != nil {
+		p.errorAt(line, "%s is defined twice", obj.Name)
+	}
+	p.mod.objects[obj.Name] = obj
+	p.mod.Objects = append(p.mod.Objects, obj)
+	if obj.Kind == KindModuleIdentity {
+		p.mod.Identity = obj
+	}
+}
+
+func (p *parser) parseClauses(obj *Object) {
+	for !p.is("::=") {
+		tok := p.next()
+		switch tok.text {
+		case "SYNTAX":
+			obj.Syntax = p.parseSyntax()
+		case "UNITS":
+			obj.Units = p.str()
+		case "MAX-ACCESS", "ACCESS":
+			obj.Access = p.ident()
+		case "STATUS":
+			obj.Status = p.ident()
+		case "DESCRIPTION":
+			obj.Description = p.str()
+		case "REFERENCE":
+			obj.Reference = p.str()
+		case "INDEX":
+			p.expect("{")
+			for {
+				implied := p.accept("IMPLIED")
+				obj.Index = append(obj.Index, Index{Name: p.ident(), Implied: implied})
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect("}")
+		case "AUGMENTS":
+			p.expect("{")
+			obj.Augments = p.ident()
+			p.expect("}")
+		case "DEFVAL":
+			obj.DefVal = p.skipBlock("{", "}")
+		case "OBJECTS", "VARIABLES", "NOTIFICATIONS":
+			// SMIv1 traps may have empty VARIABLES
+			p.expect("{")
+			for !p.accept("}") {
+				obj.Objects = append(obj.Objects, p.ident())
+				if !p.is("}") {
+					p.expect(",")
+				}
+			}
+		case "ENTERPRISE":
+			obj.Enterprise = p.ident()
+		case "LAST-UPDATED":
+			obj.LastUpdated = p.str()
+		case "ORGANIZATION":
+			obj.Organization = p.str()
+		case "CONTACT-INFO":
+			obj.ContactInfo = p.str()
+		case "REVISION":
+			r := Revision{Date: p.str()}
+			p.expect("DESCRIPTION")
+			r.Description = p.str()
+			obj.Revisions = append(obj.Revisions, r)
+		default:
+			p.pos--
+			p.errorf("unexpected %s in %s", tok, obj.Kind)
+		}
+	}
+}
+
+// parseOid parses the oid value, e.g.:
+//
+//	{ mib-2 2 }
+//	{ iso org(3) dod(6) 1 }
+//	{ 0 0 }
+func (p *parser) parseOid(obj *Object) {
+	p.expect("{")
+	if p.peek().kind == tokIdent && p.peekAt(1).text != "(" {
+		obj.parent = p.ident()
+	}
+	for !p.accept("}") {
+		if p.peek().kind == tokIdent {
+			// name(number)
+			p.next()
+			p.expect("(")
+			obj.subids = append(obj.subids, p.subid())
+			p.expect(")")
+			continue
+		}
+		obj.subids = append(obj.subids, p.subid())
+	}
+	if obj.parent == "" && len(obj.subids) == 0 {
+		p.errorAt(obj.line, "empty oid of %s", obj.Name)
+	}
+}
+
+// parseSyntax parses the type with the optional restrictions.
+func (p *parser) parseSyntax() *Syntax {
+	s := &Syntax{}
+	if p.is("[") {
+		// e.g. [APPLICATION 0] IMPLICIT
+		p.skipBlock("[", "]")
+		p.accept("IMPLICIT")
+	}
+	switch {
+	case p.accept("SEQUENCE"):
+		p.expect("OF")
+		s.SequenceOf = p.ident()
+		return s
+	case p.accept("OCTET"):
+		p.expect("STRING")
+		s.Type = "OCTET STRING"
+	case p.accept("OBJECT"):
+		p.expect("IDENTIFIER")
+		s.Type = "OBJECT IDENTIFIER"
+	default:
+		s.Type = p.ident()
+	}
+	if p.is("{") {
+		s.Enums = p.parseNamedNumbers()
+	}
+	if p.accept("(") {
+		if p.accept("SIZE") {
+			p.expect("(")
+			s.Sizes = p.parseRanges()
+			p.expect(")")
+		} else {
+			s.Ranges = p.parseRanges()
+		}
+		p.expect(")")
+	}
+	return s
+}
+
+// parseNamedNumbers parses the enumerations and bits:
+//
+//	{ name(number), ... }
+func (p *parser) parseNamedNumbers() (nn []NamedNumber) {
+	p.expect("{")
+	for {
+		n := NamedNumber{Name: p.ident()}
+		p.expect("(")
+		n.Value = p.number()
+		p.expect(")")
+		nn = append(nn, n)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}")
+	return
+}
+
+// parseRanges parses the alternatives of values and ranges:
+//
+//	value | min..max | ...
+func (p *parser) parseRanges() (ranges []Range) {
+	for {
+		r := Range{Min: p.number()}
+		r.Max = r.Min
+		if p.accept("..") {
+			r.Max = p.number()
+		}
+		ranges = append(ranges, r)
+		if !p.accept("|") {
+			return
+		}
+	}
+}
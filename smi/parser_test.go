@@ -0,0 +1,208 @@
+package smi
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/internal"
+)
+
+func parseTestModule(t *testing.T, file string) *Module {
+	src, err := os.ReadFile("testdata/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mods, err := parseModules(file, string(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mods[0]
+}
+
+func TestParseModule(t *testing.T) {
+	m := parseTestModule(t, "ACME-TEST-MIB")
+	if m.Name != "ACME-TEST-MIB" || m.Imports["InterfaceIndex"] != "IF-MIB" ||
+		m.Imports["RowStatus"] != "SNMPv2-TC" || len(m.Imports) != 17 {
+		t.Errorf("TestParseModule: %s, imports %v", m.Name, m.Imports)
+	}
+	if m.Identity == nil || m.Identity.Name != "acmeTestMIB" ||
+		m.Identity.LastUpdated != "202601010000Z" ||
+		!strings.Contains(m.Identity.Description, `The "quoted"`) {
+		t.Errorf("TestParseModule: identity %+v", m.Identity)
+	}
+	if len(m.Objects) != 28 || len(m.Types) != 3 || !m.rows["AcmeUserEntry"] || !m.rows["AcmePortEntry"] {
+		t.Errorf("TestParseModule: %d objects, %d types", len(m.Objects), len(m.Types))
+	}
+}
+
+var testDataParseObject = []struct {
+	name string
+	obj  Object
+}{
+	{
+		name: "acmeFeatures",
+		obj: Object{
+			Kind: KindObjectType,
+			Syntax: &Syntax{
+				Type:  "BITS",
+				Enums: []NamedNumber{{"alpha", 0}, {"beta", 1}, {"gamma", 7}},
+			},
+			Access:      "read-write",
+			Status:      "current",
+			Description: "The enabled features.",
+			DefVal:      "{ alpha , gamma }",
+		},
+	},
+	{
+		name: "acmeMask",
+		obj: Object{
+			Kind:        KindObjectType,
+			Syntax:      &Syntax{Type: "Unsigned32", Ranges: []Range{{0, 0xffff}}},
+			Access:      "read-write",
+			Status:      "current",
+			Description: "The mask.",
+			DefVal:      "'FF00'H",
+		},
+	},
+	{
+		name: "acmeTemperature",
+		obj: Object{
+			Kind:        KindObjectType,
+			Syntax:      &Syntax{Type: "INTEGER", Ranges: []Range{{-40, -1}, {1, 125}}},
+			Access:      "read-only",
+			Status:      "current",
+			Description: "The temperature, zero is not reported.",
+		},
+	},
+	{
+		name: "acmeUserTable",
+		obj: Object{
+			Kind:        KindObjectType,
+			Syntax:      &Syntax{SequenceOf: "AcmeUserEntry"},
+			Access:      "not-accessible",
+			Status:      "current",
+			Description: "The users.",
+		},
+	},
+	{
+		name: "acmeUserEntry",
+		obj: Object{
+			Kind:        KindObjectType,
+			Syntax:      &Syntax{Type: "AcmeUserEntry"},
+			Access:      "not-accessible",
+			Status:      "current",
+			Description: "The user.",
+			Index:       []Index{{Name: "acmeUserIfIndex"}, {Name: "acmeUserName", Implied: true}},
+		},
+	},
+	{
+		name: "acmeLevel",
+		obj: Object{
+			Kind:        KindObjectType,
+			Syntax:      &Syntax{Type: "AcmeLevel"},
+			Units:       "percent",
+			Access:      "read-only",
+			Status:      "current",
+			Description: "The current level.",
+		},
+	},
+	{
+		name: "acmeProducts",
+		obj: Object{
+			Kind:        KindObjectIdentity,
+			Status:      "current",
+			Description: "The root of the product identifiers.",
+			Reference:   "ACME catalog",
+		},
+	},
+	{
+		name: "acmeLevelHigh",
+		obj: Object{
+			Kind:        KindNotificationType,
+			Status:      "current",
+			Description: "The level is too high.",
+			Objects:     []string{"acmeLevel"},
+		},
+	},
+}
+
+func TestParseObject(t *testing.T) {
+	m := parseTestModule(t, "ACME-TEST-MIB")
+	for i, test := range testDataParseObject {
+		obj := m.Object(test.name)
+		if obj == nil {
+			t.Errorf("TestParseObject[%d]: %s not found", i, test.name)
+			continue
+		}
+		res := *obj
+		res.parent, res.subids, res.line = "", nil, 0
+		test.obj.Name, test.obj.Module = test.name, m.Name
+		if diff := internal.StructsDiff(res, test.obj); diff != "" {
+			t.Errorf("TestParseObject[%d]:\n%s", i, diff)
+		}
+	}
+}
+
+func TestParseSMIv1(t *testing.T) {
+	m := parseTestModule(t, "RFC1215-TEST-MIB")
+	obj := m.Object("acmeOverload")
+	if obj == nil || obj.Kind != KindTrapType || obj.Enterprise != "acme" ||
+		obj.parent != "acme" || len(obj.subids) != 2 || obj.subids[0] != 0 || obj.subids[1] != 3 ||
+		len(obj.Objects) != 2 {
+		t.Errorf("TestParseSMIv1: trap %+v", obj)
+	}
+	if obj := m.Object("acmeRestart"); obj == nil || len(obj.Objects) != 0 {
+		t.Errorf("TestParseSMIv1: trap %+v", obj)
+	}
+	if obj := m.Object("acmeName"); obj == nil || obj.Access != "read-write" || obj.Status != "mandatory" {
+		t.Errorf("TestParseSMIv1: object %+v", obj)
+	}
+	if typ := m.Type("DisplayString"); typ == nil || typ.TextualConvention || typ.Syntax.Type != "OCTET STRING" {
+		t.Errorf("TestParseSMIv1: type %+v", typ)
+	}
+	// RFC1155-SMI is the unmodified module of RFC 1155, the other
+	// standard modules of testdata are the excerpts.
+	m = parseTestModule(t, "RFC1155-SMI")
+	if obj := m.Object("internet"); obj == nil || obj.parent != "iso" || len(obj.subids) != 3 {
+		t.Errorf("TestParseSMIv1: internet %+v", obj)
+	}
+	if len(m.Objects) != 6 || len(m.Types) != 6 || m.Object("enterprises") == nil {
+		t.Errorf("TestParseSMIv1: RFC1155-SMI %d objects, %d types", len(m.Objects), len(m.Types))
+	}
+	if typ := m.Type("Opaque"); typ == nil || typ.Syntax.Type != "OCTET STRING" {
+		t.Errorf("TestParseSMIv1: type %+v", typ)
+	}
+}
+
+var testDataParseError = []struct {
+	src  string
+	line int
+}{
+	{src: "", line: 1},
+	{src: "M DEFINITIONS ::= BEGIN\n", line: 2},
+	{src: "M DEFINITIONS ::= BEGIN\nx OBJECT IDENTIFIER ::= { }\nEND", line: 2},
+	{src: "M DEFINITIONS ::= BEGIN\nx OBJECT IDENTIFIER ::= { y 4294967296 }\nEND", line: 2},
+	{src: "M DEFINITIONS ::= BEGIN\nx OBJECT IDENTIFIER ::= { y 1 }\nx OBJECT IDENTIFIER ::= { y 2 }\nEND", line: 3},
+	{src: "M DEFINITIONS ::= BEGIN\nx OBJECT-TYPE\n SYNTAX INTEGER\n BOGUS y\n ::= { y 1 }\nEND", line: 4},
+	{src: "M DEFINITIONS ::= BEGIN\nx OBJECT-TYPE\n SYNTAX INTEGER { a(1) b(2) }\n ::= { y 1 }\nEND", line: 3},
+	{src: "M DEFINITIONS ::= BEGIN\nx FOO-TYPE ::= { y 1 }\nEND", line: 2},
+	{src: "M DEFINITIONS ::= BEGIN\nIMPORTS a, b;\nEND", line: 2},
+	{src: "M DEFINITIONS ::= BEGIN\nT ::= TEXTUAL-CONVENTION\n STATUS current\nEND", line: 4},
+	{src: "M DEFINITIONS ::= BEGIN\n\"x\"\nEND", line: 2},
+}
+
+func TestParseError(t *testing.T) {
+	for i, test := range testDataParseError {
+		_, err := parseModules("test", test.src)
+		if !errors.Is(err, ErrSyntax) {
+			t.Errorf("TestParseError[%d]: error %v", i, err)
+			continue
+		}
+		if prefix := "smi: syntax error: test:" + strconv.Itoa(test.line) + ":"; !strings.HasPrefix(err.Error(), prefix) {
+			t.Errorf("TestParseError[%d]: error %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Package smi parses SMIv2 (RFC 2578, 2579, 2580) and common SMIv1
+(RFC 1155, 1212, 1215) MIB modules into a MIB model: the object
+identifiers, the object types with their syntax, access and index,
+the textual conventions, and the notifications.
+
+The modules are added to the MIB and then resolved, i.e. the oids
+and the types are computed across the modules through IMPORTS:
+
+	m := smi.New()
+	if err := m.LoadDir("/usr/share/snmp/mibs"); err != nil {
+		return err
+	}
+	if err := m.Resolve(); err != nil {
+		return err
+	}
+	obj, suffix := m.Lookup(id) // e.g. ifDescr, [3]
+	m.Register(oid.DefaultRegistry)
+	m.RegisterFormats(asn.DefaultFormats) // e.g. up(1) for ifAdminStatus
+
+The modules which define the base types and macros (SNMPv2-SMI,
+SNMPv2-TC, SNMPv2-CONF) are to be loaded as any other module. The
+MACRO definitions, MODULE-COMPLIANCE and AGENT-CAPABILITIES are
+parsed but not modeled.
+*/
+package smi
+
+import (
+	"errors"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+var (
+	// ErrSyntax is returned if the module can not be parsed.
+	ErrSyntax = errors.New("smi: syntax error")
+	// ErrUnresolved is returned if the parent of object or the type
+	// is not defined by the module or its imports.
+	ErrUnresolved = errors.New("smi: unresolved name")
+)
+
+// Kind is the kind of object definition.
+type Kind int
+
+const (
+	// KindValue is OBJECT IDENTIFIER value assignment.
+	KindValue Kind = iota
+	KindObjectIdentity
+	KindModuleIdentity
+	KindObjectType
+	KindNotificationType
+	// KindTrapType is SMIv1 TRAP-TYPE.
+	KindTrapType
+	KindObjectGroup
+	KindNotificationGroup
+	KindModuleCompliance
+	KindAgentCapabilities
+)
+
+var kindNames = [...]string{
+	KindValue:             "OBJECT IDENTIFIER",
+	KindObjectIdentity:    "OBJECT-IDENTITY",
+	KindModuleIdentity:    "MODULE-IDENTITY",
+	KindObjectType:        "OBJECT-TYPE",
+	KindNotificationType:  "NOTIFICATION-TYPE",
+	KindTrapType:          "TRAP-TYPE",
+	KindObjectGroup:       "OBJECT-GROUP",
+	KindNotificationGroup: "NOTIFICATION-GROUP",
+	KindModuleCompliance:  "MODULE-COMPLIANCE",
+	KindAgentCapabilities: "AGENT-CAPABILITIES",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Module is the MIB module.
+type Module struct {
+	Name string
+	// Imports maps the imported names to the modules.
+	Imports map[string]string
+	// Identity is MODULE-IDENTITY of SMIv2 module, or nil.
+	Identity *Object
+	// Objects are in the order of definition.
+	Objects []*Object
+	// Types are the textual conventions and type assignments
+	// (except SEQUENCE types of table entries).
+	Types []*Type
+
+	objects map[string]*Object
+	types   map[string]*Type
+	// rows are the SEQUENCE types of table rows.
+	rows map[string]bool
+}
+
+// Object returns the object defined by the module, or nil.
+func (m *Module) Object(name string) *Object {
+	return m.objects[name]
+}
+
+// Type returns the type defined by the module, or nil.
+func (m *Module) Type(name string) *Type {
+	return m.types[name]
+}
+
+// Object is the definition of oid: value assignment or macro
+// (OBJECT-TYPE, NOTIFICATION-TYPE, etc.). The clauses which are
+// not applicable to the kind are empty.
+type Object struct {
+	Name   string
+	Module string
+	Kind   Kind
+	// Oid is set by MIB.Resolve.
+	Oid oid.OID
+	// Parent and Children are set by MIB.Resolve. Parent is the
+	// object of the longest oid prefix.
+	Parent   *Object
+	Children []*Object
+
+	Syntax      *Syntax
+	Units       string
+	Access      string
+	Status      string
+	Description string
+	Reference   string
+	Index       []Index
+	Augments    string
+	DefVal      string
+	// Objects are OBJECTS (or SMIv1 VARIABLES) of notification
+	// and object group, or NOTIFICATIONS of notification group.
+	Objects []string
+	// Enterprise is ENTERPRISE of TRAP-TYPE.
+	Enterprise string
+	// LastUpdated, Organization, ContactInfo and Revisions are
+	// the clauses of MODULE-IDENTITY.
+	LastUpdated  string
+	Organization string
+	ContactInfo  string
+	Revisions    []Revision
+
+	// parent and subids are the oid value.
+	parent string
+	subids []uint32
+	line   int
+}
+
+// IsTable tests whether the object is table (SEQUENCE OF rows).
+func (o *Object) IsTable() bool {
+	return o.Kind == KindObjectType && o.Syntax != nil && o.Syntax.SequenceOf != ""
+}
+
+// IsRow tests whether the object is table row (entry).
+func (o *Object) IsRow() bool {
+	return o.Kind == KindObjectType && (len(o.Index) > 0 || o.Augments != "")
+}
+
+// IsColumn tests whether the object is table column.
+func (o *Object) IsColumn() bool {
+	return o.Kind == KindObjectType && o.Parent != nil && o.Parent.IsRow()
+}
+
+// IsScalar tests whether the object type is scalar.
+func (o *Object) IsScalar() bool {
+	return o.Kind == KindObjectType && !o.IsTable() && !o.IsRow() && !o.IsColumn()
+}
+
+// Index is the object of INDEX clause.
+type Index struct {
+	Name    string
+	Implied bool
+}
+
+// Revision is REVISION clause of MODULE-IDENTITY.
+type Revision struct {
+	Date        string
+	Description string
+}
+
+// Type is the textual convention or type assignment.
+type Type struct {
+	Name   string
+	Module string
+	// TextualConvention is set for TEXTUAL-CONVENTION.
+	TextualConvention bool
+	DisplayHint       string
+	Status            string
+	Description       string
+	Reference         string
+	Syntax            *Syntax
+
+	line int
+}
+
+// NamedNumber is INTEGER enumeration or BITS bit.
+type NamedNumber struct {
+	Name  string
+	Value int64
+}
+
+// Range is the range of values or sizes. Min == Max for a single
+// value.
+type Range struct {
+	Min int64
+	Max int64
+}
+
+// Syntax is the type of object or textual convention with the
+// restrictions.
+type Syntax struct {
+	// Type is the name of the type as written in the module: the
+	// base type (e.g. INTEGER, OCTET STRING, Counter32) or the type
+	// name (e.g. DisplayString).
+	Type   string
+	Enums  []NamedNumber
+	Ranges []Range
+	Sizes  []Range
+	// SequenceOf is the row type of SEQUENCE OF (tables).
+	SequenceOf string
+
+	// Base, DisplayHint and TC are set by MIB.Resolve. Base is the
+	// base type of the type chain. The enumerations and the ranges
+	// are inherited from the type chain if they are not restricted.
+	Base        string
+	DisplayHint string
+	// TC is the textual convention (or type) of Type, or nil.
+	TC *Type
+}
+
+// baseTypes are the types which are not resolved through modules.
+var baseTypes = map[string]asn.Tag{
+	"INTEGER":           asn.TagInteger32,
+	"Integer32":         asn.TagInteger32,
+	"OCTET STRING":      asn.TagOctetString,
+	"BITS":              asn.TagOctetString,
+	"OBJECT IDENTIFIER": asn.TagObjectId,
+	"IpAddress":         asn.TagIpAddress,
+	"NetworkAddress":    asn.TagIpAddress,
+	"Counter32":         asn.TagCounter32,
+	"Counter":           asn.TagCounter32,
+	"Gauge32":           asn.TagGauge32,
+	"Gauge":             asn.TagGauge32,
+	"Unsigned32":        asn.TagGauge32,
+	"TimeTicks":         asn.TagTimeTicks,
+	"Opaque":            asn.TagOpaque,
+	"Counter64":         asn.TagCounter64,
+}
+
+// Tag returns the asn type of the values, or 0 if the type is
+// not resolved.
+func (s *Syntax) Tag() asn.Tag {
+	return baseTypes[s.Base]
+}
@@ -0,0 +1,424 @@
+package snmp
+
+import (
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/generics"
+	"github.com/alexispb/mygosnmp/mib"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+	"github.com/alexispb/mygosnmp/snmp/usm"
+	"github.com/alexispb/mygosnmp/snmp/vacm"
+)
+
+// Agent is SNMP agent which answers requests with the values
+// provided by Handler (typically mib.Mux). SNMPv1/v2c messages
+// with unknown community are dropped. Set requests are accepted
+// with WriteCommunity only (if empty, all Set requests fail).
+// SNMPv3 messages are processed by Engine (dropped if nil).
+//
+// If Vacm is set, it defines the access of communities (instead of
+// Community and WriteCommunity) and SNMPv3 users. Otherwise SNMPv3
+// users have full access with their security level.
+type Agent struct {
+	Community      string
+	WriteCommunity string
+	Engine         *usm.Engine
+	Vacm           *vacm.Vacm
+	Handler        mib.Handler
+	// MaxMessageSize limits the size of response messages.
+	// GetBulk responses are truncated to fit the limit, other
+	// responses are replaced with tooBig error.
+	MaxMessageSize int
+}
+
+// NewAgent returns the agent which serves handler for the
+// "public" community.
+func NewAgent(handler mib.Handler) *Agent {
+	return &Agent{
+		Community:      "public",
+		Handler:        handler,
+		MaxMessageSize: maxMessageSize,
+	}
+}
+
+// ListenAndServe listens on the UDP address and serves requests.
+// If the address has no port, the default port is used.
+func (a *Agent) ListenAndServe(address string) error {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(Port))
+	}
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return a.Serve(conn)
+}
+
+// Serve reads requests from conn and sends the responses until
+// conn is closed. Requests are processed one by one, so Set
+// requests are not interleaved with other requests.
+func (a *Agent) Serve(conn net.PacketConn) error {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				continue
+			}
+			return err
+		}
+		var data []byte
+		var ok bool
+		if version, _ := ber.DecodeVersion(buf[:n]); version == ber.Version3 {
+			if a.Engine == nil {
+				continue
+			}
+			data, ok = a.respondV3(buf[:n])
+		} else {
+			req, err := ber.DecodeMessage(buf[:n])
+			if err != nil {
+				continue
+			}
+			data, ok = a.respond(req)
+		}
+		if ok {
+			conn.WriteTo(data, addr)
+		}
+	}
+}
+
+// respond processes the request and returns the encoded response.
+// It returns ok = false if the request is to be dropped.
+func (a *Agent) respond(req ber.Message) (data []byte, ok bool) {
+	var acc access
+	if a.Vacm != nil {
+		model := vacm.ModelOf(req.Version)
+		if _, ok := a.Vacm.Group(model, req.Community); !ok {
+			return nil, false
+		}
+		acc = a.access(model, req.Community, vacm.NoAuthNoPriv, "")
+	} else {
+		write := len(a.WriteCommunity) != 0 && req.Community == a.WriteCommunity
+		if req.Community != a.Community && !write {
+			return nil, false
+		}
+		acc = access{read: allView, write: allView}
+		if !write {
+			acc.write, acc.writeStatus = nil, pduerror.NoAccess
+		}
+	}
+	resp, ok := a.process(req.Pdu, req.Version, acc, a.MaxMessageSize)
+	if !ok {
+		return nil, false
+	}
+	return a.encode(req.Pdu, resp, a.MaxMessageSize, func(pdu ber.Pdu) ([]byte, error) {
+		return ber.EncodeMessage(ber.Message{Version: req.Version, Community: req.Community, Pdu: pdu})
+	})
+}
+
+// respondV3 processes SNMPv3 request and returns the encoded
+// response (or report).
+func (a *Agent) respondV3(data []byte) ([]byte, bool) {
+	req, sp, err := a.Engine.Decode(data)
+	if err != nil {
+		if serr, ok := err.(*usm.StatsError); ok {
+			report := a.Engine.Report(req, sp, serr)
+			return report, report != nil
+		}
+		return nil, false
+	}
+	var acc access
+	if a.Vacm != nil {
+		acc = a.access(vacm.ModelUSM, sp.UserName, vacm.LevelOf(req.Flags), req.ScopedPdu.ContextName)
+	} else if u, ok := a.Engine.User(sp.UserName); ok && req.Flags&(ber.FlagAuth|ber.FlagPriv) == u.Flags() {
+		acc = access{read: allView, write: allView}
+	} else {
+		acc.readStatus, acc.writeStatus = pduerror.AuthorizationError, pduerror.AuthorizationError
+	}
+	maxSize := generics.Min(a.MaxMessageSize, int(req.MaxSize))
+	resp, ok := a.process(req.ScopedPdu.Pdu, ber.Version3, acc, maxSize)
+	if !ok {
+		return nil, false
+	}
+	return a.encode(req.ScopedPdu.Pdu, resp, maxSize, func(pdu ber.Pdu) ([]byte, error) {
+		return a.Engine.Encode(ber.MessageV3{
+			MsgId:   req.MsgId,
+			MaxSize: int32(a.MaxMessageSize),
+			Flags:   req.Flags &^ ber.FlagReportable,
+			ScopedPdu: ber.ScopedPdu{
+				ContextEngineId: req.ScopedPdu.ContextEngineId,
+				ContextName:     req.ScopedPdu.ContextName,
+				Pdu:             pdu,
+			},
+		}, sp.UserName)
+	})
+}
+
+// allView contains all oids.
+var allView = func() *vacm.View {
+	v := &vacm.View{}
+	v.Add(nil, nil, true)
+	return v
+}()
+
+// access is the views of the request. If the view is nil, the
+// request fails with the status.
+type access struct {
+	read, write             *vacm.View
+	readStatus, writeStatus pduerror.Error
+}
+
+// access returns the views of the security name. The requests
+// without view fail with authorizationError (RFC 3416, 4.2).
+func (a *Agent) access(model vacm.SecurityModel, securityName string, level vacm.Level, context string) access {
+	var acc access
+	var err error
+	if acc.read, err = a.Vacm.View(model, securityName, level, context, vacm.Read); err != nil {
+		acc.readStatus = pduerror.AuthorizationError
+	}
+	if acc.write, err = a.Vacm.View(model, securityName, level, context, vacm.Write); err != nil {
+		acc.writeStatus = pduerror.AuthorizationError
+	}
+	return acc
+}
+
+// process performs the request and returns the response pdu
+// (GetBulk response is limited by maxSize). It returns ok = false
+// if the request is to be dropped.
+func (a *Agent) process(req ber.Pdu, version ber.Version, acc access, maxSize int) (pdu ber.Pdu, ok bool) {
+	pdu = req
+	pdu.Varbinds = append([]asn.Varbind(nil), req.Varbinds...)
+	isSet := pdu.Type == ber.TypeSetRequest
+	switch {
+	case isSet && acc.write == nil:
+		pdu.ErrorStatus, pdu.ErrorIndex = acc.writeStatus, 0
+	case isSet:
+		a.set(&pdu, acc.write)
+	case acc.read == nil && (pdu.Type == ber.TypeGetRequest || pdu.Type == ber.TypeGetNextRequest ||
+		pdu.Type == ber.TypeGetBulkRequest && version != ber.Version1):
+		pdu.ErrorStatus, pdu.ErrorIndex = acc.readStatus, 0
+	case pdu.Type == ber.TypeGetRequest:
+		a.get(&pdu, version, acc.read)
+	case pdu.Type == ber.TypeGetNextRequest:
+		a.getNext(&pdu, version, acc.read)
+	case pdu.Type == ber.TypeGetBulkRequest && version != ber.Version1:
+		a.getBulk(&pdu, acc.read, maxSize)
+	default:
+		return pdu, false
+	}
+
+	if version == ber.Version1 {
+		pdu.ErrorStatus = v1ErrorStatus(pdu.ErrorStatus)
+	}
+	if pdu.ErrorStatus != pduerror.NoError {
+		// the request varbinds are returned with the error
+		pdu.Varbinds = req.Varbinds
+	}
+	if req.Type == ber.TypeGetBulkRequest {
+		pdu.NonRepeaters, pdu.MaxRepetitions = 0, 0
+	}
+	pdu.Type = ber.TypeResponse
+	return pdu, true
+}
+
+// encode returns the encoded response. GetBulk response is
+// truncated to fit maxSize, other oversized responses are
+// replaced with tooBig error.
+func (a *Agent) encode(req, resp ber.Pdu, maxSize int, encode func(pdu ber.Pdu) ([]byte, error)) ([]byte, bool) {
+	data, err := encode(resp)
+	if req.Type == ber.TypeGetBulkRequest && err == nil && len(data) > maxSize {
+		// drop the trailing varbinds which exceed maxSize
+		n := len(resp.Varbinds)
+		for excess := len(data) - maxSize; n > 0 && excess > 0; n-- {
+			vb, _ := ber.AppendVarbind(nil, resp.Varbinds[n-1])
+			excess -= len(vb)
+		}
+		resp.Varbinds = resp.Varbinds[:n]
+		data, err = encode(resp)
+	}
+	if err != nil {
+		resp.ErrorStatus, resp.ErrorIndex = pduerror.GenError, 0
+		resp.Varbinds = req.Varbinds
+		if data, err = encode(resp); err != nil {
+			return nil, false
+		}
+	}
+	if len(data) > maxSize {
+		resp.ErrorStatus, resp.ErrorIndex = pduerror.TooBig, 0
+		resp.Varbinds = nil
+		data, _ = encode(resp)
+	}
+	return data, true
+}
+
+// isException tests whether the varbind has an exception value.
+func isException(vb asn.Varbind) bool {
+	switch vb.Tag {
+	case asn.TagNoSuchObject, asn.TagNoSuchInstance, asn.TagEndOfMibView:
+		return true
+	}
+	return false
+}
+
+func (a *Agent) get(pdu *ber.Pdu, version ber.Version, view *vacm.View) {
+	for i, vb := range pdu.Varbinds {
+		res := asn.Varbind{Tag: asn.TagNoSuchObject}
+		if view.Contains(vb.Oid) {
+			res = a.Handler.Get(vb.Oid)
+		}
+		if version == ber.Version1 && (isException(res) || res.Tag == asn.TagCounter64) {
+			pdu.ErrorStatus, pdu.ErrorIndex = pduerror.NoSuchName, int32(i+1)
+			return
+		}
+		res.Oid = vb.Oid
+		pdu.Varbinds[i] = res
+	}
+}
+
+// next returns the varbind which follows id in the view. SNMPv1
+// responses skip Counter64 values (RFC 3584, 4.2.2.1). The
+// subtrees excluded from the view are skipped at once. If the
+// handler returns an oid which does not follow the requested one,
+// the walk ends with endOfMibView.
+func (a *Agent) next(id []uint32, version ber.Version, view *vacm.View) asn.Varbind {
+	from, include := id, false
+	for {
+		res := a.Handler.GetNext(from, nil, include)
+		if cmp := oid.Compare(res.Oid, from); res.Tag == asn.TagEndOfMibView || cmp < 0 || cmp == 0 && !include {
+			return asn.Varbind{Oid: id, Tag: asn.TagEndOfMibView}
+		}
+		if view.Contains(res.Oid) {
+			if version != ber.Version1 || res.Tag != asn.TagCounter64 {
+				return res
+			}
+			from, include = res.Oid, false
+			continue
+		}
+		skip, ok := view.Skip(res.Oid)
+		if !ok {
+			return asn.Varbind{Oid: id, Tag: asn.TagEndOfMibView}
+		}
+		from, include = skip, oid.Ne(skip, res.Oid)
+	}
+}
+
+func (a *Agent) getNext(pdu *ber.Pdu, version ber.Version, view *vacm.View) {
+	for i, vb := range pdu.Varbinds {
+		res := a.next(vb.Oid, version, view)
+		if version == ber.Version1 && res.Tag == asn.TagEndOfMibView {
+			pdu.ErrorStatus, pdu.ErrorIndex = pduerror.NoSuchName, int32(i+1)
+			return
+		}
+		pdu.Varbinds[i] = res
+	}
+}
+
+// getBulk collects the varbinds until their encoded size exceeds
+// maxSize (the response is truncated by encode).
+func (a *Agent) getBulk(pdu *ber.Pdu, view *vacm.View, maxSize int) {
+	n := len(pdu.Varbinds)
+	nonRepeaters := int(pdu.NonRepeaters)
+	if nonRepeaters < 0 {
+		nonRepeaters = 0
+	} else if nonRepeaters > n {
+		nonRepeaters = n
+	}
+	maxRepetitions := int(pdu.MaxRepetitions)
+	repeaters := n - nonRepeaters
+
+	var vbs []asn.Varbind
+	size := 0
+	add := func(vb asn.Varbind) {
+		vbs = append(vbs, vb)
+		if data, err := ber.AppendVarbind(nil, vb); err == nil {
+			size += len(data)
+		}
+	}
+	for _, vb := range pdu.Varbinds[:nonRepeaters] {
+		add(a.next(vb.Oid, ber.Version2c, view))
+	}
+	last := make([][]uint32, repeaters)
+	for i, vb := range pdu.Varbinds[nonRepeaters:] {
+		last[i] = vb.Oid
+	}
+	for r := 0; r < maxRepetitions && repeaters > 0 && size <= maxSize; r++ {
+		endOfMib := true
+		for i := 0; i < len(last) && size <= maxSize; i++ {
+			res := a.next(last[i], ber.Version2c, view)
+			if res.Tag != asn.TagEndOfMibView {
+				endOfMib = false
+				last[i] = res.Oid
+			}
+			add(res)
+		}
+		if endOfMib {
+			break
+		}
+	}
+	pdu.Varbinds = vbs
+}
+
+// set performs the Set phases. If some TestSet fails, the
+// CleanupSet is called for the tested varbinds. If some
+// CommitSet fails, the committed varbinds are undone.
+func (a *Agent) set(pdu *ber.Pdu, view *vacm.View) {
+	vbs := pdu.Varbinds
+	tested := 0
+	defer func() {
+		for _, vb := range vbs[:tested] {
+			a.Handler.CleanupSet(vb)
+		}
+	}()
+
+	for i, vb := range vbs {
+		if !view.Contains(vb.Oid) {
+			pdu.ErrorStatus, pdu.ErrorIndex = pduerror.NoAccess, int32(i+1)
+			return
+		}
+		if !vb.Tag.IsValidValue(vb.Value) || isException(vb) || vb.Tag == asn.TagNull {
+			pdu.ErrorStatus, pdu.ErrorIndex = pduerror.WrongType, int32(i+1)
+			return
+		}
+		tested++
+		if status := a.Handler.TestSet(vb); status != pduerror.NoError {
+			pdu.ErrorStatus, pdu.ErrorIndex = status, int32(i+1)
+			return
+		}
+	}
+
+	for i, vb := range vbs {
+		status := a.Handler.CommitSet(vb)
+		if status == pduerror.NoError {
+			continue
+		}
+		pdu.ErrorStatus, pdu.ErrorIndex = pduerror.CommitFailed, int32(i+1)
+		for j := i - 1; j >= 0; j-- {
+			if a.Handler.UndoSet(vbs[j]) != pduerror.NoError {
+				pdu.ErrorStatus, pdu.ErrorIndex = pduerror.UndoFailed, int32(j+1)
+			}
+		}
+		return
+	}
+}
+
+// v1ErrorStatus maps SNMPv2 error-status to SNMPv1 (RFC 3584, 4.4).
+func v1ErrorStatus(status pduerror.Error) pduerror.Error {
+	switch status {
+	case pduerror.WrongValue, pduerror.WrongEncoding, pduerror.WrongType,
+		pduerror.WrongLength, pduerror.InconsistentValue:
+		return pduerror.BadValue
+	case pduerror.NoAccess, pduerror.NotWritable, pduerror.NoCreation,
+		pduerror.InconsistentName, pduerror.AuthorizationError:
+		return pduerror.NoSuchName
+	case pduerror.ResourceUnavailable, pduerror.CommitFailed, pduerror.UndoFailed:
+		return pduerror.GenError
+	}
+	return status
+}
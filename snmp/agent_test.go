@@ -0,0 +1,508 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/generics"
+	"github.com/alexispb/mygosnmp/mib"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+	"github.com/alexispb/mygosnmp/snmp/usm"
+	"github.com/alexispb/mygosnmp/snmp/vacm"
+)
+
+var (
+	sysDescr    = []uint32{1, 3, 6, 1, 2, 1, 1, 1}
+	sysContact  = []uint32{1, 3, 6, 1, 2, 1, 1, 4}
+	sysName     = []uint32{1, 3, 6, 1, 2, 1, 1, 5}
+	ifHCInOctet = []uint32{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 6}
+	myCounter   = []uint32{1, 3, 6, 1, 4, 1, 999, 1}
+)
+
+// testValue is the value set by the agent and checked by the test.
+type testValue struct {
+	mu    sync.Mutex
+	value string
+}
+
+func (v *testValue) get() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.value
+}
+
+func (v *testValue) set(value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.value = value
+}
+
+// testAgentMux serves the scalar objects sysDescr (read-only),
+// sysContact (read-write), sysName (commit fails), a Counter64
+// object, and a Counter32 object.
+func testAgentMux(contact *testValue) *mib.Mux {
+	m := mib.NewMux()
+	m.Register(sysDescr, &mib.Scalar{
+		Oid:      sysDescr,
+		Tag:      asn.TagOctetString,
+		GetValue: func() interface{} { return "test agent" },
+	})
+	m.Register(sysContact, &mib.Scalar{
+		Oid:      sysContact,
+		Tag:      asn.TagOctetString,
+		GetValue: func() interface{} { return contact.get() },
+		SetValue: func(v interface{}) pduerror.Error {
+			contact.set(v.(string))
+			return pduerror.NoError
+		},
+	})
+	m.Register(sysName, &mib.Scalar{
+		Oid:      sysName,
+		Tag:      asn.TagOctetString,
+		GetValue: func() interface{} { return "name" },
+		SetValue: func(v interface{}) pduerror.Error {
+			if v.(string) != "name" {
+				return pduerror.ResourceUnavailable
+			}
+			return pduerror.NoError
+		},
+	})
+	m.Register(ifHCInOctet, &mib.Scalar{
+		Oid:      ifHCInOctet,
+		Tag:      asn.TagCounter64,
+		GetValue: func() interface{} { return uint64(1) << 40 },
+	})
+	m.Register(myCounter, &mib.Scalar{
+		Oid:      myCounter,
+		Tag:      asn.TagCounter32,
+		GetValue: func() interface{} { return uint32(7) },
+	})
+	return m
+}
+
+func startAgent(t *testing.T, version ber.Version, community string, configure ...func(a *Agent)) (*Client, *testValue) {
+	contact := &testValue{value: "admin"}
+	agent := NewAgent(testAgentMux(contact))
+	agent.WriteCommunity = "private"
+	for _, f := range configure {
+		f(agent)
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go agent.Serve(conn)
+
+	c, err := Dial(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Version = version
+	c.Community = community
+	c.Timeout = 100 * time.Millisecond
+	c.Retries = 0
+	t.Cleanup(func() {
+		c.Close()
+		conn.Close()
+	})
+	return c, contact
+}
+
+// checkVarbinds compares oids and tags of varbinds.
+func checkVarbinds(t *testing.T, name string, vbs []asn.Varbind, expected []asn.Varbind) {
+	if len(vbs) != len(expected) {
+		t.Errorf("%s: expected %d varbinds, received %v", name, len(expected), vbs)
+		return
+	}
+	for i := range vbs {
+		if !oid.Eq(vbs[i].Oid, expected[i].Oid) || vbs[i].Tag != expected[i].Tag {
+			t.Errorf("%s[%d]: expected %s, received %s", name, i, expected[i].String(), vbs[i].String())
+		}
+	}
+}
+
+func TestAgentGet(t *testing.T) {
+	c, _ := startAgent(t, ber.Version2c, "public")
+	ctx := context.Background()
+
+	vbs, err := c.Get(ctx, oid.Cat(sysDescr, 0), oid.Cat(sysDescr, 1), []uint32{1, 3, 6, 1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "Get", vbs, []asn.Varbind{
+		{Oid: oid.Cat(sysDescr, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(sysDescr, 1), Tag: asn.TagNoSuchInstance},
+		{Oid: []uint32{1, 3, 6, 1, 3}, Tag: asn.TagNoSuchObject},
+	})
+	if vbs[0].Value != "test agent" {
+		t.Errorf("unexpected value %v", vbs[0].Value)
+	}
+
+	vbs, err = c.GetNext(ctx, sysDescr, oid.Cat(sysName, 0), oid.Cat(myCounter, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "GetNext", vbs, []asn.Varbind{
+		{Oid: oid.Cat(sysDescr, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(ifHCInOctet, 0), Tag: asn.TagCounter64},
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagEndOfMibView},
+	})
+
+	vbs, err = c.GetBulk(ctx, 1, 3, sysDescr, oid.Cat(sysContact, 0), oid.Cat(ifHCInOctet, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "GetBulk", vbs, []asn.Varbind{
+		{Oid: oid.Cat(sysDescr, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(sysName, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagCounter32},
+		{Oid: oid.Cat(ifHCInOctet, 0), Tag: asn.TagCounter64},
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagEndOfMibView},
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagCounter32},
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagEndOfMibView},
+	})
+}
+
+func TestAgentSet(t *testing.T) {
+	c, contact := startAgent(t, ber.Version2c, "private")
+	ctx := context.Background()
+
+	contactVb := asn.Varbind{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString, Value: "new"}
+	if _, err := c.Set(ctx, contactVb); err != nil {
+		t.Fatal(err)
+	}
+	if contact.get() != "new" {
+		t.Errorf("value is not set")
+	}
+
+	for i, test := range []struct {
+		vbs    []asn.Varbind
+		status pduerror.Error
+		index  int
+	}{
+		{
+			vbs: []asn.Varbind{
+				{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString, Value: "undone"},
+				{Oid: oid.Cat(sysDescr, 0), Tag: asn.TagOctetString, Value: "descr"},
+			},
+			status: pduerror.NotWritable, index: 2,
+		},
+		{
+			vbs:    []asn.Varbind{{Oid: oid.Cat(sysContact, 0), Tag: asn.TagInteger32, Value: int32(1)}},
+			status: pduerror.WrongType, index: 1,
+		},
+		{
+			vbs: []asn.Varbind{
+				{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString, Value: "undone"},
+				{Oid: oid.Cat(sysName, 0), Tag: asn.TagOctetString, Value: "other"},
+			},
+			status: pduerror.CommitFailed, index: 2,
+		},
+	} {
+		_, err := c.Set(ctx, test.vbs...)
+		var serr *StatusError
+		if !errors.As(err, &serr) || serr.Status != test.status || serr.Index != test.index {
+			t.Errorf("TestAgentSet[%d]: unexpected error %v", i, err)
+		}
+		if value := contact.get(); value != "new" {
+			t.Errorf("TestAgentSet[%d]: value is changed to %q", i, value)
+		}
+	}
+
+	c.Community = "public"
+	if _, err := c.Set(ctx, contactVb); !errors.Is(err, pduerror.NoAccess) {
+		t.Errorf("expected NoAccess, received %v", err)
+	}
+	c.Community = "unknown"
+	if _, err := c.Get(ctx, oid.Cat(sysDescr, 0)); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected timeout, received %v", err)
+	}
+}
+
+// TestAgentEmptyCommunity checks that the empty WriteCommunity
+// does not accept the requests with empty community.
+func TestAgentEmptyCommunity(t *testing.T) {
+	c, _ := startAgent(t, ber.Version2c, "", func(a *Agent) { a.WriteCommunity = "" })
+	ctx := context.Background()
+
+	if _, err := c.Get(ctx, oid.Cat(sysDescr, 0)); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Get: expected timeout, received %v", err)
+	}
+	if _, err := c.GetNext(ctx, sysDescr); !errors.Is(err, ErrTimeout) {
+		t.Errorf("GetNext: expected timeout, received %v", err)
+	}
+	if _, err := c.GetBulk(ctx, 0, 10, sysDescr); !errors.Is(err, ErrTimeout) {
+		t.Errorf("GetBulk: expected timeout, received %v", err)
+	}
+	c.Community = "public"
+	if _, err := c.Get(ctx, oid.Cat(sysDescr, 0)); err != nil {
+		t.Errorf("Get: %v", err)
+	}
+}
+
+func TestAgentVersion1(t *testing.T) {
+	c, _ := startAgent(t, ber.Version1, "public")
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, oid.Cat(sysDescr, 0), oid.Cat(sysDescr, 1))
+	var serr *StatusError
+	if !errors.As(err, &serr) || serr.Status != pduerror.NoSuchName || serr.Index != 2 {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Counter64 is skipped
+	vbs, err := c.GetNext(ctx, oid.Cat(sysName, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "GetNext", vbs, []asn.Varbind{
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagCounter32},
+	})
+
+	c.Community = "private"
+	_, err = c.Set(ctx, asn.Varbind{Oid: oid.Cat(sysDescr, 0), Tag: asn.TagOctetString, Value: "x"})
+	if !errors.As(err, &serr) || serr.Status != pduerror.NoSuchName || serr.Index != 1 {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestAgentVersion3(t *testing.T) {
+	users := []usm.User{
+		{Name: "noauth"},
+		{Name: "auth", Auth: usm.SHA256, AuthPassword: "authpassword"},
+		{Name: "priv", Auth: usm.SHA, AuthPassword: "authpassword", Priv: usm.AES, PrivPassword: "privpassword"},
+	}
+	engine := usm.NewEngine([]byte("test engine"), 1)
+	for _, u := range users {
+		engine.AddUser(u)
+	}
+	c, contact := startAgent(t, ber.Version3, "", func(a *Agent) { a.Engine = engine })
+	addr := c.conn.RemoteAddr().String()
+	ctx := context.Background()
+
+	dial := func(u usm.User) *Client {
+		c, err := Dial(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Version = ber.Version3
+		c.User = u
+		c.Timeout = 100 * time.Millisecond
+		c.Retries = 0
+		t.Cleanup(func() { c.Close() })
+		return c
+	}
+
+	for i, u := range users {
+		c := dial(u)
+		vbs, err := c.Get(ctx, oid.Cat(sysDescr, 0))
+		if err != nil {
+			t.Fatalf("TestAgentVersion3[%d]: %v", i, err)
+		}
+		if vbs[0].Value != "test agent" {
+			t.Errorf("TestAgentVersion3[%d]: unexpected value %v", i, vbs[0].Value)
+		}
+		value := asn.Varbind{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString, Value: u.Name}
+		if _, err := c.Set(ctx, value); err != nil {
+			t.Fatalf("TestAgentVersion3[%d]: Set: %v", i, err)
+		}
+		if contact.get() != u.Name {
+			t.Errorf("TestAgentVersion3[%d]: value is not set", i)
+		}
+	}
+
+	for i, test := range []struct {
+		user usm.User
+		err  error
+	}{
+		{user: usm.User{Name: "unknown"}, err: usm.ErrUnknownUserName},
+		{user: usm.User{Name: "auth", Auth: usm.SHA256, AuthPassword: "wrongpassword"}, err: usm.ErrWrongDigest},
+		{user: usm.User{Name: "noauth", Auth: usm.MD5, AuthPassword: "authpassword"}, err: usm.ErrUnsupportedSecLevel},
+		{user: usm.User{Name: "priv"}, err: usm.ErrUnsupportedSecLevel},
+		{user: usm.User{Name: "priv", Auth: usm.SHA, AuthPassword: "authpassword"}, err: usm.ErrUnsupportedSecLevel},
+	} {
+		if _, err := dial(test.user).Get(ctx, oid.Cat(sysDescr, 0)); err != test.err {
+			t.Errorf("TestAgentVersion3[%d]: error %v, expected %v", i, err, test.err)
+		}
+	}
+
+	// the user without keys can not bypass the security level
+	contact.set("admin")
+	value := asn.Varbind{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString, Value: "pwned"}
+	if _, err := dial(usm.User{Name: "priv"}).Set(ctx, value); err != usm.ErrUnsupportedSecLevel {
+		t.Errorf("TestAgentVersion3: Set error %v, expected %v", err, usm.ErrUnsupportedSecLevel)
+	}
+	if contact.get() != "admin" {
+		t.Errorf("TestAgentVersion3: value is set without authentication")
+	}
+	if n := engine.Stats(usm.ErrUnsupportedSecLevel); n < 3 {
+		t.Errorf("TestAgentVersion3: usmStatsUnsupportedSecLevels %d", n)
+	}
+}
+
+func TestAgentVacm(t *testing.T) {
+	v := vacm.New()
+	v.AddGroup(vacm.ModelV2c, "public", "readers")
+	v.AddGroup(vacm.ModelV2c, "private", "writers")
+	v.AddView("system", []uint32{1, 3, 6, 1, 2, 1, 1}, nil, true)
+	v.AddView("system", sysDescr, nil, false)
+	v.AddView("contact", sysContact, nil, true)
+	v.AddAccess(vacm.Access{Group: "readers", Level: vacm.NoAuthNoPriv, ReadView: "system"})
+	v.AddAccess(vacm.Access{Group: "writers", Level: vacm.NoAuthNoPriv, ReadView: "system", WriteView: "contact"})
+	ctx := context.Background()
+
+	c, contact := startAgent(t, ber.Version2c, "public", func(a *Agent) { a.Vacm = v })
+	vbs, err := c.Get(ctx, oid.Cat(sysDescr, 0), oid.Cat(sysContact, 0), oid.Cat(myCounter, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "Get", vbs, []asn.Varbind{
+		{Oid: oid.Cat(sysDescr, 0), Tag: asn.TagNoSuchObject},
+		{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagNoSuchObject},
+	})
+
+	// excluded sysDescr and out of view objects are skipped
+	vbs, err = c.GetNext(ctx, []uint32{1, 3, 6, 1, 2, 1}, oid.Cat(sysName, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "GetNext", vbs, []asn.Varbind{
+		{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(sysName, 0), Tag: asn.TagEndOfMibView},
+	})
+
+	value := asn.Varbind{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString, Value: "new"}
+	var serr *StatusError
+	if _, err = c.Set(ctx, value); !errors.As(err, &serr) || serr.Status != pduerror.AuthorizationError {
+		t.Errorf("Set: error %v, expected %s", err, pduerror.AuthorizationError)
+	}
+
+	c.Community = "private"
+	if _, err = c.Set(ctx, value); err != nil {
+		t.Errorf("Set: %v", err)
+	}
+	if contact.get() != "new" {
+		t.Errorf("value is not set")
+	}
+	name := asn.Varbind{Oid: oid.Cat(sysName, 0), Tag: asn.TagOctetString, Value: "name"}
+	if _, err = c.Set(ctx, value, name); !errors.As(err, &serr) || serr.Status != pduerror.NoAccess || serr.Index != 2 {
+		t.Errorf("Set: error %v, expected %s at index 2", err, pduerror.NoAccess)
+	}
+
+	// unknown community is dropped
+	c.Community = "other"
+	if _, err = c.Get(ctx, oid.Cat(sysContact, 0)); err != ErrTimeout {
+		t.Errorf("Get: error %v, expected %v", err, ErrTimeout)
+	}
+}
+
+// testColumn serves the instances 1..n of the column. If broken is
+// set, GetNext returns start. It counts GetNext calls, which are
+// made by the agent goroutine.
+type testColumn struct {
+	mib.ReadOnly
+	oid    []uint32
+	n      uint32
+	broken bool
+	calls  int64
+}
+
+func (c *testColumn) Get(id []uint32) asn.Varbind {
+	return asn.Varbind{Oid: id, Tag: asn.TagNoSuchObject}
+}
+
+func (c *testColumn) GetNext(start, end []uint32, include bool) asn.Varbind {
+	atomic.AddInt64(&c.calls, 1)
+	if c.broken {
+		return asn.Varbind{Oid: start, Tag: asn.TagInteger32, Value: int32(0)}
+	}
+	if oid.Compare(start, oid.Cat(c.oid, c.n)) > 0 {
+		return asn.Varbind{Oid: start, Tag: asn.TagEndOfMibView}
+	}
+	i := uint32(1)
+	if oid.HasPrefix(start, c.oid...) && len(start) > len(c.oid) {
+		i = generics.Max(start[len(c.oid)], 1)
+	}
+	for ; i <= c.n && oid.Compare(oid.Cat(c.oid, i), start) <= 0; i++ {
+		if include && oid.Eq(oid.Cat(c.oid, i), start) {
+			break
+		}
+	}
+	if i <= c.n && oid.Compare(oid.Cat(c.oid, i), start) >= 0 {
+		return asn.Varbind{Oid: oid.Cat(c.oid, i), Tag: asn.TagInteger32, Value: int32(i)}
+	}
+	return asn.Varbind{Oid: start, Tag: asn.TagEndOfMibView}
+}
+
+func TestAgentGetBulkSize(t *testing.T) {
+	ctx := context.Background()
+	for _, size := range []int{484, 60000} {
+		column := &testColumn{oid: []uint32{1, 3, 6, 1, 4, 1, 999, 2, 1}, n: 100000}
+		c, _ := startAgent(t, ber.Version2c, "public", func(a *Agent) {
+			a.Handler, a.MaxMessageSize = column, size
+		})
+		vbs, err := c.GetBulk(ctx, 0, 100000, column.oid)
+		if err != nil {
+			t.Fatalf("TestAgentGetBulkSize(%d): %v", size, err)
+		}
+		data, _ := ber.EncodeMessage(ber.Message{Version: c.Version, Community: c.Community,
+			Pdu: ber.Pdu{Type: ber.TypeResponse, Varbinds: vbs}})
+		if len(vbs) == 0 || len(data) > size {
+			t.Errorf("TestAgentGetBulkSize(%d): %d varbinds of %d bytes", size, len(vbs), len(data))
+		}
+		// the varbinds exceeding the size are not collected
+		if calls := atomic.LoadInt64(&column.calls); calls > int64(len(vbs)+size/16) {
+			t.Errorf("TestAgentGetBulkSize(%d): %d calls for %d varbinds", size, calls, len(vbs))
+		}
+	}
+}
+
+func TestAgentNextSkip(t *testing.T) {
+	column := &testColumn{oid: []uint32{1, 3, 6, 1, 4, 1, 999, 2, 1}, n: 100000}
+	m := mib.NewMux()
+	m.Register(column.oid, column)
+	m.Register(myCounter, &mib.Scalar{
+		Oid:      myCounter,
+		Tag:      asn.TagCounter32,
+		GetValue: func() interface{} { return uint32(7) },
+	})
+	v := vacm.New()
+	v.AddGroup(vacm.ModelV2c, "public", "readers")
+	v.AddView("enterprise", []uint32{1, 3, 6, 1, 4, 1, 999}, nil, true)
+	v.AddView("enterprise", []uint32{1, 3, 6, 1, 4, 1, 999, 2}, nil, false)
+	v.AddView("enterprise", oid.Cat(column.oid, 99999), nil, true)
+	v.AddAccess(vacm.Access{Group: "readers", Level: vacm.NoAuthNoPriv, ReadView: "enterprise"})
+	ctx := context.Background()
+
+	c, _ := startAgent(t, ber.Version2c, "public", func(a *Agent) { a.Handler, a.Vacm = m, v })
+	vbs, err := c.GetNext(ctx, myCounter, oid.Cat(column.oid, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "GetNext", vbs, []asn.Varbind{
+		{Oid: oid.Cat(myCounter, 0), Tag: asn.TagCounter32},
+		{Oid: oid.Cat(column.oid, 99999), Tag: asn.TagInteger32},
+	})
+	if calls := atomic.LoadInt64(&column.calls); calls > 4 {
+		t.Errorf("TestAgentNextSkip: excluded subtree is walked with %d calls", calls)
+	}
+
+	// the handler returning non-increasing oid ends the walk
+	broken := &testColumn{oid: column.oid, n: column.n, broken: true}
+	c, _ = startAgent(t, ber.Version2c, "public", func(a *Agent) { a.Handler = broken })
+	vbs, err = c.GetNext(ctx, column.oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVarbinds(t, "GetNext", vbs, []asn.Varbind{{Oid: column.oid, Tag: asn.TagEndOfMibView}})
+}
@@ -0,0 +1,369 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/pduerror"
+	"github.com/alexispb/mygosnmp/snmp/usm"
+)
+
+const (
+	DefaultTimeout = 2 * time.Second
+	DefaultRetries = 2
+)
+
+// maxMessageSize is the maximum size of UDP datagram payload.
+const maxMessageSize = 65535
+
+// Client is SNMP manager which sends requests to a single agent.
+// The exported fields are to be set before the first request.
+// Methods of the Client can be called concurrently.
+type Client struct {
+	Version   ber.Version
+	Community string
+	// Timeout is the time to wait for a response to each
+	// (re)transmission of the request.
+	Timeout time.Duration
+	// Retries is the number of retransmissions after timeout.
+	Retries int
+	// Backoff doubles Timeout for each retransmission.
+	Backoff bool
+	// AdaptiveBulk halves maxRepetitions of BulkWalk and GetTable
+	// on tooBig error.
+	AdaptiveBulk bool
+	// User and ContextName are used by SNMPv3 instead of Community.
+	User        usm.User
+	ContextName string
+
+	conn    net.Conn
+	mu      sync.Mutex
+	nextId  int32
+	pending map[int32]chan response
+	remote  *usm.Remote
+	// done is closed when the receiving goroutine exits.
+	done chan struct{}
+	err  error
+}
+
+// Dial connects to the agent at address. If the address has no
+// port, the default port is used.
+func Dial(address string) (*Client, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(Port))
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns the client which sends requests over conn.
+// The client reads responses from conn until Close is called.
+func NewClient(conn net.Conn) *Client {
+	c := &Client{
+		Version:   ber.Version2c,
+		Community: "public",
+		Timeout:   DefaultTimeout,
+		Retries:   DefaultRetries,
+		conn:      conn,
+		nextId:    rand.Int31(),
+		pending:   make(map[int32]chan response),
+		done:      make(chan struct{}),
+	}
+	go c.receive()
+	return c
+}
+
+// Close closes the connection. Pending requests fail with ErrClosed.
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// response is the response pdu or the error reported by the agent.
+type response struct {
+	pdu ber.Pdu
+	err error
+}
+
+// receive delivers responses to the pending requests. Messages
+// which can not be decoded or do not match pending requests
+// are dropped.
+func (c *Client) receive() {
+	defer close(c.done)
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				continue
+			}
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+			return
+		}
+		id, resp, ok := c.decode(buf[:n])
+		if !ok {
+			continue
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+}
+
+// decode returns the response and the id of the request it
+// correlates with (msgID for SNMPv3, and request-id otherwise).
+// SNMPv3 responses with other security level than the request
+// are dropped, except usm reports.
+func (c *Client) decode(data []byte) (id int32, resp response, ok bool) {
+	if c.Version != ber.Version3 {
+		msg, err := ber.DecodeMessage(data)
+		if err != nil || msg.Community != c.Community || msg.Pdu.Type != ber.TypeResponse {
+			return 0, resp, false
+		}
+		return msg.Pdu.RequestId, response{pdu: msg.Pdu}, true
+	}
+
+	c.mu.Lock()
+	remote := c.remote
+	c.mu.Unlock()
+	if remote == nil {
+		return 0, resp, false
+	}
+	msg, err := remote.Decode(data)
+	resp = response{pdu: msg.ScopedPdu.Pdu, err: err}
+	// the requests have the security level of the user (except
+	// discovery), the responses must have the same level
+	level := msg.Flags & (ber.FlagAuth | ber.FlagPriv)
+	switch {
+	case level != remote.User.Flags() && usm.ReportError(resp.pdu) == nil:
+		return 0, resp, false
+	case err == nil && resp.pdu.Type == ber.TypeReport:
+		resp.err = &ReportError{Varbinds: resp.pdu.Varbinds}
+	case err == nil && resp.pdu.Type != ber.TypeResponse:
+		return 0, resp, false
+	case err != nil && usm.ReportError(resp.pdu) == nil:
+		// security processing failed
+		return 0, resp, false
+	}
+	return msg.MsgId, resp, true
+}
+
+// Request sends the pdu with a new request-id and returns the
+// response pdu. The request is retransmitted Retries times if
+// no response is received within Timeout. Request returns
+// ErrTimeout if all the attempts time out, or ctx.Err() if ctx
+// is done before the response is received. The error-status of
+// the response is not checked.
+//
+// SNMPv3 request is preceded by the engine discovery (once per
+// Client), and is resent once if the agent reports that the
+// request is not in time window. The usm reports are returned
+// as *usm.StatsError, and other reports as *ReportError.
+func (c *Client) Request(ctx context.Context, pdu ber.Pdu) (ber.Pdu, error) {
+	if c.Version == ber.Version3 {
+		return c.requestV3(ctx, pdu)
+	}
+	return c.exchange(ctx, func(id int32) ([]byte, error) {
+		pdu.RequestId = id
+		return ber.EncodeMessage(ber.Message{
+			Version:   c.Version,
+			Community: c.Community,
+			Pdu:       pdu,
+		})
+	})
+}
+
+func (c *Client) requestV3(ctx context.Context, pdu ber.Pdu) (ber.Pdu, error) {
+	c.mu.Lock()
+	if c.remote == nil {
+		remote, err := usm.NewRemote(c.User)
+		if err != nil {
+			c.mu.Unlock()
+			return ber.Pdu{}, err
+		}
+		c.remote = remote
+	}
+	remote := c.remote
+	c.mu.Unlock()
+
+	if !remote.Discovered() {
+		_, err := c.exchange(ctx, func(id int32) ([]byte, error) {
+			return remote.EncodeDiscovery(id, maxMessageSize)
+		})
+		if err != nil && err != usm.ErrUnknownEngineId {
+			return ber.Pdu{}, err
+		}
+		if !remote.Discovered() {
+			return ber.Pdu{}, usm.ErrUnknownEngineId
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.exchange(ctx, func(id int32) ([]byte, error) {
+			pdu.RequestId = id
+			return remote.Encode(ber.MessageV3{
+				MsgId:   id,
+				MaxSize: maxMessageSize,
+				Flags:   ber.FlagReportable,
+				ScopedPdu: ber.ScopedPdu{
+					ContextEngineId: remote.EngineId(),
+					ContextName:     c.ContextName,
+					Pdu:             pdu,
+				},
+			})
+		})
+		if err == usm.ErrNotInTimeWindow && attempt == 0 {
+			continue
+		}
+		return resp, err
+	}
+}
+
+// send sends the pdu with a new request-id without waiting for
+// a response (e.g. Trap).
+func (c *Client) send(pdu ber.Pdu) error {
+	c.mu.Lock()
+	c.nextId = (c.nextId + 1) & 0x7FFFFFFF
+	pdu.RequestId = c.nextId
+	c.mu.Unlock()
+	data, err := ber.EncodeMessage(ber.Message{
+		Version:   c.Version,
+		Community: c.Community,
+		Pdu:       pdu,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(data)
+	return err
+}
+
+// exchange sends the message returned by encode for a new id and
+// waits for the response.
+func (c *Client) exchange(ctx context.Context, encode func(id int32) ([]byte, error)) (ber.Pdu, error) {
+	ch := make(chan response, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return ber.Pdu{}, ErrClosed
+	}
+	c.nextId = (c.nextId + 1) & 0x7FFFFFFF
+	id := c.nextId
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	data, err := encode(id)
+	if err != nil {
+		return ber.Pdu{}, err
+	}
+
+	timeout := c.Timeout
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if _, err := c.conn.Write(data); err != nil {
+			return ber.Pdu{}, err
+		}
+		if attempt > 0 {
+			if c.Backoff {
+				timeout *= 2
+			}
+			timer.Reset(timeout)
+		}
+		select {
+		case resp := <-ch:
+			return resp.pdu, resp.err
+		case <-timer.C:
+		case <-ctx.Done():
+			return ber.Pdu{}, ctx.Err()
+		case <-c.done:
+			return ber.Pdu{}, ErrClosed
+		}
+	}
+	return ber.Pdu{}, ErrTimeout
+}
+
+// request sends the request pdu and returns the response
+// varbinds. If the response includes non-zero error-status,
+// the varbinds are returned with *StatusError.
+func (c *Client) request(ctx context.Context, pdu ber.Pdu) ([]asn.Varbind, error) {
+	resp, err := c.Request(ctx, pdu)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ErrorStatus != pduerror.NoError {
+		return resp.Varbinds, &StatusError{Status: resp.ErrorStatus, Index: int(resp.ErrorIndex)}
+	}
+	return resp.Varbinds, nil
+}
+
+func nullVarbinds(oids [][]uint32) []asn.Varbind {
+	vbs := make([]asn.Varbind, len(oids))
+	for i, id := range oids {
+		vbs[i] = asn.Varbind{Oid: id, Tag: asn.TagNull}
+	}
+	return vbs
+}
+
+// Get returns the values of the object instances.
+func (c *Client) Get(ctx context.Context, oids ...[]uint32) ([]asn.Varbind, error) {
+	return c.request(ctx, ber.Pdu{
+		Type:     ber.TypeGetRequest,
+		Varbinds: nullVarbinds(oids),
+	})
+}
+
+// GetNext returns the values of the object instances which
+// follow the oids in lexicographical order.
+func (c *Client) GetNext(ctx context.Context, oids ...[]uint32) ([]asn.Varbind, error) {
+	return c.request(ctx, ber.Pdu{
+		Type:     ber.TypeGetNextRequest,
+		Varbinds: nullVarbinds(oids),
+	})
+}
+
+// GetBulk performs GetNext for the first nonRepeaters oids and
+// up to maxRepetitions successive GetNext for the rest of oids.
+// GetBulk is not supported by SNMPv1.
+func (c *Client) GetBulk(ctx context.Context, nonRepeaters, maxRepetitions int, oids ...[]uint32) ([]asn.Varbind, error) {
+	if c.Version == ber.Version1 {
+		return nil, errors.New("snmp: GetBulk is not supported by SNMPv1")
+	}
+	return c.request(ctx, ber.Pdu{
+		Type:           ber.TypeGetBulkRequest,
+		NonRepeaters:   int32(nonRepeaters),
+		MaxRepetitions: int32(maxRepetitions),
+		Varbinds:       nullVarbinds(oids),
+	})
+}
+
+// Set assigns the values to the object instances.
+func (c *Client) Set(ctx context.Context, vbs ...asn.Varbind) ([]asn.Varbind, error) {
+	return c.request(ctx, ber.Pdu{
+		Type:     ber.TypeSetRequest,
+		Varbinds: vbs,
+	})
+}
@@ -0,0 +1,242 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/internal"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+	"github.com/alexispb/mygosnmp/snmp/usm"
+)
+
+// responder is a local UDP agent which answers requests with
+// the result of handle (no answer if handle returns false).
+type responder struct {
+	conn     net.PacketConn
+	requests int32
+	handle   func(n int, req ber.Pdu) (ber.Pdu, bool)
+}
+
+func startResponder(t *testing.T, handle func(n int, req ber.Pdu) (ber.Pdu, bool)) (*responder, *Client) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &responder{conn: conn, handle: handle}
+	go r.serve()
+
+	c, err := Dial(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Timeout = 50 * time.Millisecond
+	t.Cleanup(func() {
+		c.Close()
+		conn.Close()
+	})
+	return r, c
+}
+
+func (r *responder) serve() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		msg, err := ber.DecodeMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		resp, ok := r.handle(int(atomic.AddInt32(&r.requests, 1)), msg.Pdu)
+		if !ok {
+			continue
+		}
+		msg.Pdu = resp
+		data, _ := ber.EncodeMessage(msg)
+		r.conn.WriteTo(data, addr)
+	}
+}
+
+// echo answers with the request varbinds.
+func echo(req ber.Pdu) ber.Pdu {
+	return ber.Pdu{Type: ber.TypeResponse, RequestId: req.RequestId, Varbinds: req.Varbinds}
+}
+
+var (
+	testOid1 = []uint32{1, 3, 6, 1, 2, 1, 1, 1, 0}
+	testOid2 = []uint32{1, 3, 6, 1, 2, 1, 1, 3, 0}
+)
+
+func TestClientOperations(t *testing.T) {
+	var mu sync.Mutex
+	var reqs []ber.Pdu
+	_, c := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		reqs = append(reqs, req)
+		return echo(req), true
+	})
+	ctx := context.Background()
+
+	vbset := asn.Varbind{Oid: testOid1, Tag: asn.TagOctetString, Value: "descr"}
+	for i, op := range []func() ([]asn.Varbind, error){
+		func() ([]asn.Varbind, error) { return c.Get(ctx, testOid1, testOid2) },
+		func() ([]asn.Varbind, error) { return c.GetNext(ctx, testOid1, testOid2) },
+		func() ([]asn.Varbind, error) { return c.GetBulk(ctx, 1, 10, testOid1, testOid2) },
+		func() ([]asn.Varbind, error) { return c.Set(ctx, vbset) },
+	} {
+		if _, err := op(); err != nil {
+			t.Errorf("TestClientOperations[%d]: %v", i, err)
+		}
+	}
+
+	nulls := []asn.Varbind{{Oid: testOid1, Tag: asn.TagNull}, {Oid: testOid2, Tag: asn.TagNull}}
+	expected := []ber.Pdu{
+		{Type: ber.TypeGetRequest, Varbinds: nulls},
+		{Type: ber.TypeGetNextRequest, Varbinds: nulls},
+		{Type: ber.TypeGetBulkRequest, NonRepeaters: 1, MaxRepetitions: 10, Varbinds: nulls},
+		{Type: ber.TypeSetRequest, Varbinds: []asn.Varbind{vbset}},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reqs) != len(expected) {
+		t.Fatalf("expected %d requests, received %d", len(expected), len(reqs))
+	}
+	for i := range reqs {
+		reqs[i].RequestId = 0
+		if diff := internal.StructsDiff(expected[i], reqs[i]); len(diff) > 0 {
+			t.Errorf("TestClientOperations[%d]:\n%s", i, diff)
+		}
+	}
+}
+
+func TestClientStatusError(t *testing.T) {
+	_, c := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		resp := echo(req)
+		resp.ErrorStatus = pduerror.NotWritable
+		resp.ErrorIndex = 2
+		return resp, true
+	})
+
+	vbs, err := c.Set(context.Background(),
+		asn.Varbind{Oid: testOid1, Tag: asn.TagInteger32, Value: int32(1)},
+		asn.Varbind{Oid: testOid2, Tag: asn.TagInteger32, Value: int32(2)})
+	var serr *StatusError
+	if !errors.As(err, &serr) || serr.Status != pduerror.NotWritable || serr.Index != 2 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errors.Is(err, pduerror.NotWritable) {
+		t.Errorf("error does not wrap pduerror.NotWritable")
+	}
+	if len(vbs) != 2 {
+		t.Errorf("expected 2 varbinds, received %d", len(vbs))
+	}
+}
+
+func TestClientCorrelation(t *testing.T) {
+	// the first response has a stale request-id and the second
+	// one is a retransmission
+	_, c := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		resp := echo(req)
+		if n == 1 {
+			resp.RequestId--
+			resp.Varbinds = nil
+		}
+		return resp, true
+	})
+
+	vbs, err := c.Get(context.Background(), testOid1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vbs) != 1 || !oid.Eq(vbs[0].Oid, testOid1) {
+		t.Errorf("unexpected varbinds %v", vbs)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	r, c := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		return echo(req), n == 3
+	})
+	if _, err := c.Get(context.Background(), testOid1); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&r.requests); n != 3 {
+		t.Errorf("expected 3 transmissions, received %d", n)
+	}
+
+	c.Retries = 1
+	if _, err := c.Get(context.Background(), testOid1); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected timeout, received %v", err)
+	}
+	if n := atomic.LoadInt32(&r.requests); n != 5 {
+		t.Errorf("expected 5 transmissions, received %d", n)
+	}
+}
+
+func TestClientCancel(t *testing.T) {
+	_, c := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		return ber.Pdu{}, false
+	})
+	c.Timeout = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := c.Get(ctx, testOid1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, received %v", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		c.Close()
+	}()
+	if _, err := c.Get(context.Background(), testOid1); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, received %v", err)
+	}
+}
+
+func TestClientSecurityLevel(t *testing.T) {
+	user := usm.User{Name: "auth", Auth: usm.SHA256, AuthPassword: "authpassword"}
+	engine := usm.NewEngine([]byte("test engine"), 1)
+	engine.AddUser(user)
+	remote, _ := usm.NewRemote(user)
+	c := &Client{Version: ber.Version3, User: user, remote: remote}
+
+	encode := func(flags ber.MsgFlags, vb asn.Varbind) []byte {
+		data, err := engine.Encode(ber.MessageV3{
+			MsgId: 1,
+			Flags: flags,
+			ScopedPdu: ber.ScopedPdu{
+				Pdu: ber.Pdu{Type: ber.TypeReport, RequestId: 1, Varbinds: []asn.Varbind{vb}},
+			},
+		}, user.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	report := asn.Varbind{Oid: usm.ErrUnknownEngineId.Oid(), Tag: asn.TagCounter32, Value: uint32(1)}
+	other := asn.Varbind{Oid: testOid1, Tag: asn.TagCounter32, Value: uint32(1)}
+	for i, test := range []struct {
+		data []byte
+		ok   bool
+	}{
+		// the unauthenticated report discovers the engine
+		{data: encode(0, report), ok: true},
+		{data: encode(0, other), ok: false},
+		{data: encode(ber.FlagAuth, other), ok: true},
+	} {
+		if _, _, ok := c.decode(test.data); ok != test.ok {
+			t.Errorf("TestClientSecurityLevel[%d]: decoded %t", i, ok)
+		}
+	}
+}
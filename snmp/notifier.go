@@ -0,0 +1,207 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// Target is the notification receiver. If Inform is set, the
+// SNMPv2c Inform is retransmitted Retries times (the Timeout is
+// doubled for each retransmission) until it is acknowledged.
+type Target struct {
+	// Address is host[:port], TrapPort is used by default.
+	Address   string
+	Version   ber.Version
+	Community string
+	Inform    bool
+	// Timeout is the initial Inform timeout (DefaultTimeout if zero).
+	Timeout time.Duration
+	Retries int
+}
+
+// Notifier sends notifications to the targets. The exported
+// fields are to be set before the first notification.
+type Notifier struct {
+	Targets []Target
+	// AgentAddr is the agent-addr of SNMPv1 traps.
+	AgentAddr [4]byte
+	// Uptime returns sysUpTime.0 (hundredths of a second), by
+	// default the time since the Notifier was created.
+	Uptime func() uint32
+
+	mu      sync.Mutex
+	clients map[Target]*Client
+}
+
+// NewNotifier returns the notifier sending notifications to the
+// targets.
+func NewNotifier(targets ...Target) *Notifier {
+	start := time.Now()
+	return &Notifier{
+		Targets: targets,
+		Uptime: func() uint32 {
+			return uint32(time.Since(start) / (10 * time.Millisecond))
+		},
+		clients: make(map[Target]*Client),
+	}
+}
+
+// Close closes the connections to the targets.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var err error
+	for t, c := range n.clients {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+		delete(n.clients, t)
+	}
+	return err
+}
+
+func (n *Notifier) client(t Target) (*Client, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if c := n.clients[t]; c != nil {
+		return c, nil
+	}
+	if t.Version != ber.Version1 && t.Version != ber.Version2c || t.Inform && t.Version == ber.Version1 {
+		return nil, errors.New("snmp: unsupported target version " + t.Version.String())
+	}
+	address := t.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(TrapPort))
+	}
+	c, err := Dial(address)
+	if err != nil {
+		return nil, err
+	}
+	c.Version, c.Community = t.Version, t.Community
+	if t.Timeout != 0 {
+		c.Timeout = t.Timeout
+	}
+	c.Retries, c.Backoff = t.Retries, true
+	n.clients[t] = c
+	return c, nil
+}
+
+// Notify sends the notification with sysUpTime.0, snmpTrapOID.0
+// and the varbinds to the targets. SNMPv1 targets receive the trap
+// converted by ConvertTrapV2. Notify waits until all the Informs
+// are acknowledged (or time out), and returns the first error.
+func (n *Notifier) Notify(ctx context.Context, trapOid []uint32, vbs ...asn.Varbind) error {
+	v2 := ber.Pdu{Type: ber.TypeTrapV2}
+	v2.Varbinds = append(v2.Varbinds,
+		asn.Varbind{Oid: oid.Clone(sysUpTime0), Tag: asn.TagTimeTicks, Value: n.Uptime()},
+		asn.Varbind{Oid: oid.Clone(snmpTrapOid0), Tag: asn.TagObjectId, Value: trapOid})
+	v2.Varbinds = append(v2.Varbinds, vbs...)
+	v1 := ConvertTrapV2(v2)
+	v1.AgentAddr = n.AgentAddr
+	return n.send(ctx, v1, v2)
+}
+
+// NotifyV1 sends SNMPv1 trap with the enterprise, generic-trap and
+// specific-trap fields. Other targets receive the trap converted
+// by ConvertTrapV1. The trap originates here (it is not proxied),
+// so the converted trap has no snmpTrapCommunity.0 and
+// snmpTrapAddress.0 varbinds.
+func (n *Notifier) NotifyV1(ctx context.Context, enterprise []uint32, generic, specific int, vbs ...asn.Varbind) error {
+	v1 := ber.Pdu{
+		Type:         ber.TypeTrapV1,
+		Enterprise:   enterprise,
+		AgentAddr:    n.AgentAddr,
+		GenericTrap:  int32(generic),
+		SpecificTrap: int32(specific),
+		Timestamp:    n.Uptime(),
+		Varbinds:     vbs,
+	}
+	const notProxied = ""
+	return n.send(ctx, v1, ConvertTrapV1(v1, notProxied))
+}
+
+func (n *Notifier) send(ctx context.Context, v1, v2 ber.Pdu) error {
+	errs := make([]error, len(n.Targets))
+	var wg sync.WaitGroup
+	for i, t := range n.Targets {
+		c, err := n.client(t)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		switch {
+		case t.Version == ber.Version1:
+			errs[i] = c.send(v1)
+		case !t.Inform:
+			errs[i] = c.send(v2)
+		default:
+			wg.Add(1)
+			go func(i int, c *Client) {
+				defer wg.Done()
+				pdu := v2
+				pdu.Type = ber.TypeInformRequest
+				_, errs[i] = c.request(ctx, pdu)
+			}(i, c)
+		}
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("snmp: notify %s: %w", n.Targets[i].Address, err)
+		}
+	}
+	return nil
+}
+
+// ConvertTrapV2 returns SNMPv1 Trap pdu converted from SNMPv2 Trap
+// pdu (RFC 3584, 3.2). The agent-addr is taken from snmpTrapAddress.0
+// (if present), and Counter64 varbinds are removed.
+func ConvertTrapV2(pdu ber.Pdu) ber.Pdu {
+	v1 := ber.Pdu{Type: ber.TypeTrapV1}
+	var trapOid, enterprise []uint32
+	for _, vb := range pdu.Varbinds {
+		switch {
+		case oid.Eq(vb.Oid, sysUpTime0) && vb.Tag == asn.TagTimeTicks:
+			v1.Timestamp = vb.Value.(uint32)
+			continue
+		case oid.Eq(vb.Oid, snmpTrapOid0) && vb.Tag == asn.TagObjectId:
+			trapOid, _ = asn.ObjectIdValue(vb.Value)
+			continue
+		case oid.Eq(vb.Oid, snmpTrapEnterprise0) && vb.Tag == asn.TagObjectId:
+			enterprise, _ = asn.ObjectIdValue(vb.Value)
+		case oid.Eq(vb.Oid, snmpTrapAddress0) && vb.Tag == asn.TagIpAddress:
+			v1.AgentAddr = vb.Value.([4]byte)
+		case vb.Tag == asn.TagCounter64:
+			continue
+		}
+		v1.Varbinds = append(v1.Varbinds, vb)
+	}
+
+	n := len(trapOid)
+	switch {
+	case n == len(snmpTraps)+1 && oid.HasPrefix(trapOid, snmpTraps...) &&
+		1 <= trapOid[n-1] && trapOid[n-1] <= enterpriseSpecific:
+		// generic trap
+		v1.GenericTrap = int32(trapOid[n-1] - 1)
+		v1.Enterprise = enterprise
+		if v1.Enterprise == nil {
+			v1.Enterprise = oid.Clone(snmpTraps)
+		}
+	case n >= 2 && trapOid[n-2] == 0:
+		v1.GenericTrap, v1.SpecificTrap = enterpriseSpecific, int32(trapOid[n-1])
+		v1.Enterprise = oid.Clone(trapOid[:n-2])
+	case n >= 1:
+		v1.GenericTrap, v1.SpecificTrap = enterpriseSpecific, int32(trapOid[n-1])
+		v1.Enterprise = oid.Clone(trapOid[:n-1])
+	}
+	return v1
+}
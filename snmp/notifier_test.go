@@ -0,0 +1,150 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/internal"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+func TestConvertTrapV2(t *testing.T) {
+	for i, test := range testDataConvertTrapV1 {
+		pdu := ConvertTrapV2(ConvertTrapV1(test.pdu, "public"))
+		expected := test.pdu
+		expected.Varbinds = append(append([]asn.Varbind(nil), test.pdu.Varbinds...),
+			asn.Varbind{Oid: snmpTrapAddress0, Tag: asn.TagIpAddress, Value: test.pdu.AgentAddr},
+			asn.Varbind{Oid: snmpTrapCommunity0, Tag: asn.TagOctetString, Value: "public"})
+		if test.pdu.GenericTrap != enterpriseSpecific {
+			expected.Varbinds = append(expected.Varbinds,
+				asn.Varbind{Oid: snmpTrapEnterprise0, Tag: asn.TagObjectId, Value: test.pdu.Enterprise})
+		}
+		if diff := internal.StructsDiff(pdu, expected); diff != "" {
+			t.Errorf("TestConvertTrapV2[%d]:\n%s", i, diff)
+		}
+	}
+
+	pdu := ConvertTrapV2(ber.Pdu{Type: ber.TypeTrapV2, Varbinds: []asn.Varbind{
+		{Oid: sysUpTime0, Tag: asn.TagTimeTicks, Value: uint32(5)},
+		{Oid: snmpTrapOid0, Tag: asn.TagObjectId, Value: []uint32{1, 3, 6, 1, 4, 1, 999, 2, 3}},
+		{Oid: ifHCInOctet, Tag: asn.TagCounter64, Value: uint64(1)},
+	}})
+	expected := ber.Pdu{
+		Type:         ber.TypeTrapV1,
+		Enterprise:   []uint32{1, 3, 6, 1, 4, 1, 999, 2},
+		GenericTrap:  6,
+		SpecificTrap: 3,
+		Timestamp:    5,
+	}
+	if diff := internal.StructsDiff(pdu, expected); diff != "" {
+		t.Errorf("TestConvertTrapV2:\n%s", diff)
+	}
+}
+
+func startTrapListener(t *testing.T, community string) (chan *Notification, string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	ch := make(chan *Notification, 10)
+	l := NewTrapListener(ChanHandler(ch))
+	l.Community = community
+	go l.Serve(conn)
+	return ch, conn.LocalAddr().String()
+}
+
+func TestNotifier(t *testing.T) {
+	ch, address := startTrapListener(t, "public")
+	n := NewNotifier(
+		Target{Address: address, Version: ber.Version1, Community: "public"},
+		Target{Address: address, Version: ber.Version2c, Community: "public"},
+		Target{Address: address, Version: ber.Version2c, Community: "public", Inform: true},
+	)
+	defer n.Close()
+	n.AgentAddr = [4]byte{192, 0, 2, 1}
+	n.Uptime = func() uint32 { return 1000 }
+
+	linkUp := oid.Cat(snmpTraps, 4)
+	vb := asn.Varbind{Oid: oid.Cat(sysName, 0), Tag: asn.TagOctetString, Value: "name"}
+	if err := n.Notify(context.Background(), linkUp, vb); err != nil {
+		t.Fatal(err)
+	}
+	types := map[ber.PduType]bool{}
+	for i := 0; i < len(n.Targets); i++ {
+		r := <-ch
+		types[r.Pdu.Type] = true
+		if r.Uptime != 1000 || !oid.Eq(r.TrapOid, linkUp) || !oid.Eq(r.Varbinds[0].Oid, vb.Oid) {
+			t.Errorf("TestNotifier: %s %+v", r.Pdu.Type, r)
+		}
+		if r.Pdu.Type == ber.TypeTrapV1 && (r.Pdu.GenericTrap != 3 || r.Pdu.AgentAddr != n.AgentAddr) {
+			t.Errorf("TestNotifier: v1 trap %+v", r.Pdu)
+		}
+	}
+	if len(types) != 3 {
+		t.Errorf("TestNotifier: received %v", types)
+	}
+
+	if err := n.NotifyV1(context.Background(), []uint32{1, 3, 6, 1, 4, 1, 999}, 6, 7, vb); err != nil {
+		t.Fatal(err)
+	}
+	expectedOid := []uint32{1, 3, 6, 1, 4, 1, 999, 0, 7}
+	for i := 0; i < len(n.Targets); i++ {
+		r := <-ch
+		if !oid.Eq(r.TrapOid, expectedOid) {
+			t.Errorf("TestNotifier: %s trap oid %s", r.Pdu.Type, oid.String(r.TrapOid))
+		}
+		// the converted trap is not proxied, so it has no
+		// snmpTrapAddress.0 and snmpTrapCommunity.0
+		if r.Pdu.Type != ber.TypeTrapV1 && len(r.Pdu.Varbinds) != 3 {
+			t.Errorf("TestNotifier: %s varbinds %v", r.Pdu.Type, r.Pdu.Varbinds)
+		}
+	}
+}
+
+func TestNotifierInformRetries(t *testing.T) {
+	// the receiver acknowledges the third Inform only
+	var mu sync.Mutex
+	var sent []time.Time
+	r, _ := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, time.Now())
+		req.Type = ber.TypeResponse
+		return req, n == 3
+	})
+
+	target := Target{
+		Address:   r.conn.LocalAddr().String(),
+		Version:   ber.Version2c,
+		Community: "public",
+		Inform:    true,
+		Timeout:   20 * time.Millisecond,
+		Retries:   2,
+	}
+	n := NewNotifier(target)
+	defer n.Close()
+	if err := n.Notify(context.Background(), oid.Cat(snmpTraps, 1)); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	informs := append([]time.Time(nil), sent...)
+	mu.Unlock()
+	if len(informs) != 3 {
+		t.Fatalf("TestNotifierInformRetries: %d informs", len(informs))
+	}
+	if d1, d2 := informs[1].Sub(informs[0]), informs[2].Sub(informs[1]); d2 < d1*3/2 {
+		t.Errorf("TestNotifierInformRetries: no backoff: %v, %v", d1, d2)
+	}
+
+	n.Targets[0].Retries = 0
+	if err := n.Notify(context.Background(), oid.Cat(snmpTraps, 1)); !errors.Is(err, ErrTimeout) {
+		t.Errorf("TestNotifierInformRetries: error %v", err)
+	}
+}
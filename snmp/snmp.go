@@ -0,0 +1,112 @@
+/*
+Package snmp implements SNMP (RFC 3416) over UDP on top of the
+asn/ber codec.
+
+The Client sends requests to an agent and correlates responses
+by request-id:
+
+	c, err := snmp.Dial("192.0.2.1:161")
+	if err != nil {
+		...
+	}
+	defer c.Close()
+	c.Community = "public"
+
+	vbs, err := c.Get(ctx, oid.MustParse("1.3.6.1.2.1.1.1.0"))
+	var serr *snmp.StatusError
+	if errors.As(err, &serr) {
+		// the agent returned error-status (e.g. pduerror.NoSuchName)
+		// for the varbind vbs[serr.Index-1]
+	}
+
+SNMPv3 requests are secured by the User-based Security Model (see
+the snmp/usm package). The Client discovers the agent engine with
+the first request:
+
+	c.Version = ber.Version3
+	c.User = usm.User{
+		Name:         "admin",
+		Auth:         usm.SHA256,
+		AuthPassword: "authpassword",
+		Priv:         usm.AES,
+		PrivPassword: "privpassword",
+	}
+
+The Agent answers requests with the values provided by mib.Handler
+(see the mib package):
+
+	agent := snmp.NewAgent(mux)
+	agent.WriteCommunity = "private"
+	agent.Engine = engine // SNMPv3 users, see usm.Engine
+	err := agent.ListenAndServe(":161")
+
+The TrapListener receives notifications (SNMPv1 traps are converted
+to SNMPv2 form as described in RFC 3584) and acknowledges Informs:
+
+	ch := make(chan *snmp.Notification)
+	go snmp.NewTrapListener(snmp.ChanHandler(ch)).ListenAndServe(":162")
+	for n := range ch {
+		fmt.Println(n.Addr, oid.String(n.TrapOid), n.Varbinds)
+	}
+
+The Notifier sends Traps and Informs to the targets:
+
+	n := snmp.NewNotifier(snmp.Target{
+		Address:   "192.0.2.2",
+		Version:   ber.Version2c,
+		Community: "public",
+		Inform:    true,
+		Retries:   3,
+	})
+	err := n.Notify(ctx, linkDown, ifIndexVarbind)
+
+Varbind values are represented by the Go types described in
+the asn package.
+*/
+package snmp
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+// Port is the default SNMP agent port.
+const Port = 161
+
+var (
+	ErrTimeout = errors.New("snmp: request timed out")
+	ErrClosed  = errors.New("snmp: client closed")
+)
+
+// StatusError is returned if the response pdu includes non-zero
+// error-status. Index is the error-index, i.e. the 1-based index
+// of the varbind which caused the error (0 if not applicable).
+type StatusError struct {
+	Status pduerror.Error
+	Index  int
+}
+
+func (e *StatusError) Error() string {
+	return "snmp: " + e.Status.String() + " at index " + strconv.Itoa(e.Index)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Status
+}
+
+// ReportError is returned if the SNMPv3 request is answered with
+// the Report pdu other than usm report (e.g. snmpUnknownPDUHandlers).
+type ReportError struct {
+	Varbinds []asn.Varbind
+}
+
+func (e *ReportError) Error() string {
+	if len(e.Varbinds) == 0 {
+		return "snmp: report"
+	}
+	return "snmp: report " + oid.String(e.Varbinds[0].Oid)
+}
@@ -0,0 +1,185 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/mib"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+// tableRepetitions is maxRepetitions of GetTable requests.
+const tableRepetitions = 10
+
+// Row is the conceptual row of the table. Columns maps the column
+// subid to the varbind, the columns missing in the row are absent.
+type Row struct {
+	Index   []uint32
+	Columns map[uint32]asn.Varbind
+}
+
+// Table maps the index (in dotted notation) to the row.
+type Table map[string]*Row
+
+// Rows returns the rows sorted by index.
+func (t Table) Rows() []*Row {
+	rows := make([]*Row, 0, len(t))
+	for _, row := range t {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return oid.Lt(rows[i].Index, rows[j].Index)
+	})
+	return rows
+}
+
+func (t Table) add(column uint32, index []uint32, vb asn.Varbind) {
+	key := oid.String(index)
+	row := t[key]
+	if row == nil {
+		row = &Row{Index: index, Columns: make(map[uint32]asn.Varbind)}
+		t[key] = row
+	}
+	row.Columns[column] = vb
+}
+
+// GetTable returns the rows of the table entry (e.g. ifEntry) with
+// the columns. The columns are walked in parallel, i.e. each GetBulk
+// request (GetNext for SNMPv1) includes the next instance of each
+// column which is not completed yet. The rows with missing columns
+// are kept.
+func (c *Client) GetTable(ctx context.Context, entry []uint32, columns ...uint32) (Table, error) {
+	table := make(Table)
+	last := make([][]uint32, len(columns))
+	for i, column := range columns {
+		last[i] = oid.Cat(entry, column)
+	}
+	active := make([]int, len(columns))
+	for i := range active {
+		active[i] = i
+	}
+	maxRepetitions := tableRepetitions
+
+	for len(active) > 0 {
+		oids := make([][]uint32, len(active))
+		for i, col := range active {
+			oids[i] = last[col]
+		}
+		var vbs []asn.Varbind
+		var err error
+		if c.Version == ber.Version1 {
+			vbs, err = c.GetNext(ctx, oids...)
+		} else {
+			vbs, err = c.GetBulk(ctx, 0, maxRepetitions, oids...)
+		}
+		var serr *StatusError
+		if errors.As(err, &serr) {
+			switch {
+			case c.Version == ber.Version1 && serr.Status == pduerror.NoSuchName &&
+				0 < serr.Index && serr.Index <= len(active):
+				// the column is completed
+				active = append(active[:serr.Index-1], active[serr.Index:]...)
+				continue
+			case c.AdaptiveBulk && serr.Status == pduerror.TooBig && maxRepetitions > 1:
+				maxRepetitions /= 2
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(vbs) == 0 {
+			return nil, ErrNotIncreasing
+		}
+
+		done := make([]bool, len(active))
+		for j, vb := range vbs {
+			i := j % len(active)
+			if done[i] {
+				continue
+			}
+			col := active[i]
+			prefix := oid.Cat(entry, columns[col])
+			if isException(vb) || !oid.HasPrefix(vb.Oid, prefix...) {
+				done[i] = true
+				continue
+			}
+			if oid.Compare(vb.Oid, last[col]) <= 0 {
+				return nil, ErrNotIncreasing
+			}
+			last[col] = vb.Oid
+			table.add(columns[col], vb.Oid[len(prefix):], vb)
+		}
+		next := active[:0]
+		for i, col := range active {
+			if !done[i] {
+				next = append(next, col)
+			}
+		}
+		active = next
+	}
+	return table, nil
+}
+
+// UnmarshalTable appends the rows of the table (sorted by index)
+// to the slice pointed by v. The slice elements are structs (or
+// pointers to structs) with the struct tags described in
+// mib.Binding.
+func UnmarshalTable(t Table, v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%w: %T is not pointer to slice", mib.ErrBinding, v)
+	}
+	slice = slice.Elem()
+	b, err := mib.NewBinding(slice.Type().Elem())
+	if err != nil {
+		return err
+	}
+	for _, row := range t.Rows() {
+		vbs := make([]asn.Varbind, 0, len(row.Columns))
+		for _, vb := range row.Columns {
+			vbs = append(vbs, vb)
+		}
+		elem := reflect.New(b.Type)
+		if err := b.Unmarshal(elem, row.Index, vbs); err != nil {
+			return err
+		}
+		if slice.Type().Elem().Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return nil
+}
+
+// GetTableInto retrieves the table whose columns are bound to the
+// fields of the slice elements (see UnmarshalTable), and appends
+// the rows to the slice pointed by v. The noaccess columns are not
+// retrieved, the index fields are decoded from the row index.
+func (c *Client) GetTableInto(ctx context.Context, v interface{}) error {
+	typ := reflect.TypeOf(v)
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%w: %T is not pointer to slice", mib.ErrBinding, v)
+	}
+	b, err := mib.NewBinding(typ.Elem().Elem())
+	if err != nil {
+		return err
+	}
+	columns := make([]uint32, 0, len(b.Columns))
+	for _, col := range b.Columns {
+		if !col.NoAccess {
+			columns = append(columns, col.Oid[len(b.Entry)])
+		}
+	}
+	t, err := c.GetTable(ctx, b.Entry, columns...)
+	if err != nil {
+		return err
+	}
+	return UnmarshalTable(t, v)
+}
@@ -0,0 +1,163 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/internal"
+	"github.com/alexispb/mygosnmp/mib"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// testTable is the read-only handler of the table cells.
+type testTable struct {
+	mib.ReadOnly
+	cells []asn.Varbind
+}
+
+func (t *testTable) add(vb asn.Varbind) {
+	t.cells = append(t.cells, vb)
+	sort.Slice(t.cells, func(i, j int) bool { return oid.Lt(t.cells[i].Oid, t.cells[j].Oid) })
+}
+
+func (t *testTable) Get(id []uint32) asn.Varbind {
+	for _, vb := range t.cells {
+		if oid.Eq(vb.Oid, id) {
+			return vb
+		}
+	}
+	return asn.Varbind{Oid: id, Tag: asn.TagNoSuchInstance}
+}
+
+func (t *testTable) GetNext(start, end []uint32, include bool) asn.Varbind {
+	for _, vb := range t.cells {
+		if mib.InRange(vb.Oid, start, end, include) {
+			return vb
+		}
+	}
+	return asn.Varbind{Oid: start, Tag: asn.TagEndOfMibView}
+}
+
+func TestGetTable(t *testing.T) {
+	ifEntry := oid.Name["ifEntry"]
+	table := &testTable{}
+	const rows = 25
+	for i := uint32(1); i <= rows; i++ {
+		table.add(asn.Varbind{Oid: oid.Cat(ifEntry, 1, i), Tag: asn.TagInteger32, Value: int32(i)})
+		table.add(asn.Varbind{Oid: oid.Cat(ifEntry, 2, i), Tag: asn.TagOctetString, Value: "if" + strconv.Itoa(int(i))})
+		if i != 3 {
+			// sparse column
+			table.add(asn.Varbind{Oid: oid.Cat(ifEntry, 10, i), Tag: asn.TagCounter32, Value: i * 100})
+		}
+	}
+	mux := mib.NewMux()
+	mux.Register(ifEntry, table)
+	mux.Register(sysDescr, &mib.Scalar{
+		Oid:      sysDescr,
+		Tag:      asn.TagOctetString,
+		GetValue: func() interface{} { return "descr" },
+	})
+
+	for _, version := range []ber.Version{ber.Version1, ber.Version2c} {
+		c, _ := startAgent(t, version, "public", func(a *Agent) { a.Handler = mux })
+		res, err := c.GetTable(context.Background(), ifEntry, 1, 2, 10, 20)
+		if err != nil {
+			t.Fatalf("TestGetTable[%s]: %v", version, err)
+		}
+		if len(res) != rows {
+			t.Fatalf("TestGetTable[%s]: %d rows", version, len(res))
+		}
+		for i, row := range res.Rows() {
+			index := uint32(i + 1)
+			if !oid.Eq(row.Index, []uint32{index}) || res[strconv.Itoa(i+1)] != row {
+				t.Errorf("TestGetTable[%s]: row %d index %s", version, i, oid.String(row.Index))
+			}
+			columns := 3
+			if index == 3 {
+				columns = 2
+			}
+			if len(row.Columns) != columns || row.Columns[2].Value != "if"+strconv.Itoa(i+1) {
+				t.Errorf("TestGetTable[%s]: row %d columns %v", version, i, row.Columns)
+			}
+		}
+	}
+}
+
+type testIfRow struct {
+	Index    int32  `snmp:"ifIndex,index"`
+	Descr    string `snmp:"ifDescr"`
+	InOctets uint64 `snmp:"1.3.6.1.2.1.2.2.1.10,counter32"`
+}
+
+func TestGetTableInto(t *testing.T) {
+	rows := []testIfRow{
+		{Index: 2, Descr: "eth1", InOctets: 200},
+		{Index: 1, Descr: "eth0", InOctets: 100},
+		{Index: 5, Descr: "lo"},
+	}
+	table, err := mib.NewStructTable(func() []testIfRow { return rows })
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := mib.NewMux()
+	mux.Register(table.Entry(), table)
+
+	for _, version := range []ber.Version{ber.Version1, ber.Version2c} {
+		c, _ := startAgent(t, version, "public", func(a *Agent) { a.Handler = mux })
+		var res []testIfRow
+		if err := c.GetTableInto(context.Background(), &res); err != nil {
+			t.Fatalf("TestGetTableInto[%s]: %v", version, err)
+		}
+		expected := []testIfRow{rows[1], rows[0], rows[2]}
+		if diff := internal.StructsDiff(res, expected); diff != "" {
+			t.Errorf("TestGetTableInto[%s]:\n%s", version, diff)
+		}
+	}
+
+	var res []*testIfRow
+	if err := UnmarshalTable(Table{}, res); !errors.Is(err, mib.ErrBinding) {
+		t.Errorf("TestGetTableInto: UnmarshalTable error %v", err)
+	}
+}
+
+// testPeerRow is indexed by the not-accessible address and the
+// IMPLIED name.
+type testPeerRow struct {
+	Addr  net.IP `snmp:"1.3.6.1.4.1.999.8.1.1,index,noaccess"`
+	Name  string `snmp:"1.3.6.1.4.1.999.8.1.2,index,implied,noaccess"`
+	State int32  `snmp:"1.3.6.1.4.1.999.8.1.3"`
+}
+
+func TestGetTableIntoIndex(t *testing.T) {
+	rows := []testPeerRow{
+		{Addr: net.IPv4(10, 0, 0, 2).To4(), Name: "a", State: 2},
+		{Addr: net.IPv4(10, 0, 0, 1).To4(), Name: "b", State: 1},
+	}
+	table, err := mib.NewStructTable(func() []testPeerRow { return rows })
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := mib.NewMux()
+	mux.Register(table.Entry(), table)
+
+	c, _ := startAgent(t, ber.Version2c, "public", func(a *Agent) { a.Handler = mux })
+	var res []testPeerRow
+	if err := c.GetTableInto(context.Background(), &res); err != nil {
+		t.Fatal(err)
+	}
+	expected := []testPeerRow{rows[1], rows[0]}
+	if len(res) != len(expected) {
+		t.Fatalf("TestGetTableIntoIndex: %d rows", len(res))
+	}
+	for i := range expected {
+		if diff := internal.StructsDiff(res[i], expected[i]); diff != "" {
+			t.Errorf("TestGetTableIntoIndex[%d]:\n%s", i, diff)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package snmp
+
+import (
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// TrapPort is the default notification receiver port.
+const TrapPort = 162
+
+var (
+	sysUpTime0          = []uint32{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	snmpTrapOid0        = []uint32{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}
+	snmpTrapEnterprise0 = []uint32{1, 3, 6, 1, 6, 3, 1, 1, 4, 3, 0}
+	snmpTraps           = []uint32{1, 3, 6, 1, 6, 3, 1, 1, 5}
+	snmpTrapAddress0    = []uint32{1, 3, 6, 1, 6, 3, 18, 1, 3, 0}
+	snmpTrapCommunity0  = []uint32{1, 3, 6, 1, 6, 3, 18, 1, 4, 0}
+)
+
+// enterpriseSpecific is the generic-trap of SNMPv1 enterprise
+// specific trap.
+const enterpriseSpecific = 6
+
+// Notification is the received Trap or Inform. The Uptime, TrapOid
+// and Varbinds are taken from the SNMPv2 form of the notification
+// (SNMPv1 traps are converted by ConvertTrapV1), and Varbinds do not
+// include sysUpTime.0 and snmpTrapOID.0.
+type Notification struct {
+	Addr      net.Addr
+	Version   ber.Version
+	Community string
+	Uptime    uint32
+	TrapOid   []uint32
+	Varbinds  []asn.Varbind
+	// Pdu is the received pdu (TypeTrapV1, TypeTrapV2 or
+	// TypeInformRequest).
+	Pdu ber.Pdu
+}
+
+// ConvertTrapV1 returns SNMPv2 Trap pdu converted from SNMPv1 Trap
+// pdu (RFC 3584, 3.1). The snmpTrapEnterprise.0 varbind is appended
+// to the varbinds of the generic traps. If community is not empty,
+// the trap is converted by proxy (e.g. TrapListener), and the
+// snmpTrapAddress.0 and snmpTrapCommunity.0 varbinds are appended
+// too.
+func ConvertTrapV1(pdu ber.Pdu, community string) ber.Pdu {
+	trapOid := oid.Cat(pdu.Enterprise, 0, uint32(pdu.SpecificTrap))
+	if pdu.GenericTrap != enterpriseSpecific {
+		trapOid = oid.Cat(snmpTraps, uint32(pdu.GenericTrap+1))
+	}
+	vbs := make([]asn.Varbind, 0, len(pdu.Varbinds)+5)
+	vbs = append(vbs,
+		asn.Varbind{Oid: oid.Clone(sysUpTime0), Tag: asn.TagTimeTicks, Value: pdu.Timestamp},
+		asn.Varbind{Oid: oid.Clone(snmpTrapOid0), Tag: asn.TagObjectId, Value: trapOid})
+	vbs = append(vbs, pdu.Varbinds...)
+	if community != "" {
+		vbs = append(vbs,
+			asn.Varbind{Oid: oid.Clone(snmpTrapAddress0), Tag: asn.TagIpAddress, Value: pdu.AgentAddr},
+			asn.Varbind{Oid: oid.Clone(snmpTrapCommunity0), Tag: asn.TagOctetString, Value: community})
+	}
+	if pdu.GenericTrap != enterpriseSpecific {
+		vbs = append(vbs,
+			asn.Varbind{Oid: oid.Clone(snmpTrapEnterprise0), Tag: asn.TagObjectId, Value: oid.Clone(pdu.Enterprise)})
+	}
+	return ber.Pdu{Type: ber.TypeTrapV2, Varbinds: vbs}
+}
+
+// TrapListener receives SNMPv1/v2c Traps and Informs, and passes
+// them to Handler. Informs are acknowledged before Handler is
+// called. Notifications with community other than Community are
+// dropped (if Community is empty, all are accepted).
+type TrapListener struct {
+	Community string
+	Handler   func(n *Notification)
+}
+
+// NewTrapListener returns the listener which passes notifications
+// to handler.
+func NewTrapListener(handler func(n *Notification)) *TrapListener {
+	return &TrapListener{Handler: handler}
+}
+
+// ChanHandler returns the handler which sends notifications to ch.
+// The listener is blocked until the notification is received
+// from ch.
+func ChanHandler(ch chan<- *Notification) func(n *Notification) {
+	return func(n *Notification) {
+		ch <- n
+	}
+}
+
+// ListenAndServe listens on the UDP address and receives
+// notifications. If the address has no port, TrapPort is used.
+func (l *TrapListener) ListenAndServe(address string) error {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(TrapPort))
+	}
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return l.Serve(conn)
+}
+
+// Serve reads notifications from conn until conn is closed.
+func (l *TrapListener) Serve(conn net.PacketConn) error {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			var nerr net.Error
+			if errors.As(err, &nerr) && nerr.Timeout() {
+				continue
+			}
+			return err
+		}
+		msg, err := ber.DecodeMessage(buf[:n])
+		if err != nil || len(l.Community) != 0 && msg.Community != l.Community {
+			continue
+		}
+		notification, ok := newNotification(msg)
+		if !ok {
+			continue
+		}
+		notification.Addr = addr
+		if msg.Pdu.Type == ber.TypeInformRequest {
+			resp := msg
+			resp.Pdu.Type = ber.TypeResponse
+			if data, err := ber.EncodeMessage(resp); err == nil {
+				conn.WriteTo(data, addr)
+			}
+		}
+		l.Handler(notification)
+	}
+}
+
+// newNotification returns the notification of the message. It
+// returns ok = false if the message is not a notification, or
+// the notification has no sysUpTime.0 and snmpTrapOID.0 varbinds
+// (RFC 3416, 4.2.6).
+func newNotification(msg ber.Message) (n *Notification, ok bool) {
+	pdu := msg.Pdu
+	switch {
+	case pdu.Type == ber.TypeTrapV1 && msg.Version == ber.Version1:
+		pdu = ConvertTrapV1(pdu, msg.Community)
+	case pdu.Type == ber.TypeTrapV2 && msg.Version != ber.Version1:
+	case pdu.Type == ber.TypeInformRequest && msg.Version != ber.Version1:
+	default:
+		return nil, false
+	}
+
+	vbs := pdu.Varbinds
+	if len(vbs) < 2 || !oid.Eq(vbs[0].Oid, sysUpTime0) || vbs[0].Tag != asn.TagTimeTicks ||
+		!oid.Eq(vbs[1].Oid, snmpTrapOid0) || vbs[1].Tag != asn.TagObjectId {
+		return nil, false
+	}
+	return &Notification{
+		Version:   msg.Version,
+		Community: msg.Community,
+		Uptime:    vbs[0].Value.(uint32),
+		TrapOid:   vbs[1].Value.([]uint32),
+		Varbinds:  vbs[2:],
+		Pdu:       msg.Pdu,
+	}, true
+}
@@ -0,0 +1,170 @@
+package snmp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/internal"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+var testDataConvertTrapV1 = []struct {
+	pdu     ber.Pdu
+	trapOid []uint32
+}{
+	{
+		// linkDown
+		pdu: ber.Pdu{
+			Type:        ber.TypeTrapV1,
+			Enterprise:  []uint32{1, 3, 6, 1, 4, 1, 999},
+			AgentAddr:   [4]byte{192, 0, 2, 1},
+			GenericTrap: 2,
+			Timestamp:   100,
+			Varbinds: []asn.Varbind{
+				{Oid: []uint32{1, 3, 6, 1, 2, 1, 2, 2, 1, 1, 3}, Tag: asn.TagInteger32, Value: int32(3)},
+			},
+		},
+		trapOid: []uint32{1, 3, 6, 1, 6, 3, 1, 1, 5, 3},
+	},
+	{
+		// enterpriseSpecific
+		pdu: ber.Pdu{
+			Type:         ber.TypeTrapV1,
+			Enterprise:   []uint32{1, 3, 6, 1, 4, 1, 999},
+			AgentAddr:    [4]byte{192, 0, 2, 1},
+			GenericTrap:  6,
+			SpecificTrap: 17,
+			Timestamp:    200,
+		},
+		trapOid: []uint32{1, 3, 6, 1, 4, 1, 999, 0, 17},
+	},
+}
+
+func TestConvertTrapV1(t *testing.T) {
+	for i, test := range testDataConvertTrapV1 {
+		for _, community := range []string{"public", ""} {
+			expected := []asn.Varbind{
+				{Oid: sysUpTime0, Tag: asn.TagTimeTicks, Value: test.pdu.Timestamp},
+				{Oid: snmpTrapOid0, Tag: asn.TagObjectId, Value: test.trapOid},
+			}
+			expected = append(expected, test.pdu.Varbinds...)
+			if community != "" {
+				expected = append(expected,
+					asn.Varbind{Oid: snmpTrapAddress0, Tag: asn.TagIpAddress, Value: test.pdu.AgentAddr},
+					asn.Varbind{Oid: snmpTrapCommunity0, Tag: asn.TagOctetString, Value: community})
+			}
+			if test.pdu.GenericTrap != enterpriseSpecific {
+				expected = append(expected,
+					asn.Varbind{Oid: snmpTrapEnterprise0, Tag: asn.TagObjectId, Value: test.pdu.Enterprise})
+			}
+
+			pdu := ConvertTrapV1(test.pdu, community)
+			if pdu.Type != ber.TypeTrapV2 {
+				t.Errorf("TestConvertTrapV1[%d]: type %s", i, pdu.Type)
+			}
+			if len(pdu.Varbinds) != len(expected) {
+				t.Errorf("TestConvertTrapV1[%d]: %q: varbinds %v", i, community, pdu.Varbinds)
+				continue
+			}
+			for j := range expected {
+				if diff := internal.StructsDiff(pdu.Varbinds[j], expected[j]); diff != "" {
+					t.Errorf("TestConvertTrapV1[%d]: %q: varbind %d:\n%s", i, community, j, diff)
+				}
+			}
+		}
+	}
+}
+
+func TestTrapListener(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ch := make(chan *Notification, 1)
+	l := NewTrapListener(ChanHandler(ch))
+	l.Community = "public"
+	go l.Serve(conn)
+
+	sender, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Close()
+	send := func(msg ber.Message) {
+		data, err := ber.EncodeMessage(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = sender.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive := func() *Notification {
+		select {
+		case n := <-ch:
+			return n
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	coldStart := oid.Cat(snmpTraps, 1)
+	v2Varbinds := []asn.Varbind{
+		{Oid: sysUpTime0, Tag: asn.TagTimeTicks, Value: uint32(300)},
+		{Oid: snmpTrapOid0, Tag: asn.TagObjectId, Value: coldStart},
+		{Oid: oid.Cat(sysName, 0), Tag: asn.TagOctetString, Value: "name"},
+	}
+
+	// SNMPv1 trap, and the trap with other community (dropped)
+	send(ber.Message{Version: ber.Version1, Community: "other", Pdu: testDataConvertTrapV1[1].pdu})
+	send(ber.Message{Version: ber.Version1, Community: "public", Pdu: testDataConvertTrapV1[1].pdu})
+	n := receive()
+	if n == nil {
+		t.Fatal("TestTrapListener: v1 trap is not received")
+	}
+	if n.Version != ber.Version1 || n.Uptime != 200 || !oid.Eq(n.TrapOid, testDataConvertTrapV1[1].trapOid) ||
+		n.Pdu.Type != ber.TypeTrapV1 || len(n.Varbinds) != 2 {
+		t.Errorf("TestTrapListener: v1 trap %+v", n)
+	}
+
+	// SNMPv2c trap
+	send(ber.Message{Version: ber.Version2c, Community: "public",
+		Pdu: ber.Pdu{Type: ber.TypeTrapV2, RequestId: 1, Varbinds: v2Varbinds}})
+	if n = receive(); n == nil {
+		t.Fatal("TestTrapListener: v2c trap is not received")
+	}
+	if n.Uptime != 300 || !oid.Eq(n.TrapOid, coldStart) {
+		t.Errorf("TestTrapListener: v2c trap %+v", n)
+	}
+	if diff := internal.StructsDiff(n.Varbinds, v2Varbinds[2:]); diff != "" {
+		t.Errorf("TestTrapListener: v2c trap varbinds:\n%s", diff)
+	}
+
+	// Inform is acknowledged
+	send(ber.Message{Version: ber.Version2c, Community: "public",
+		Pdu: ber.Pdu{Type: ber.TypeInformRequest, RequestId: 2, Varbinds: v2Varbinds}})
+	if n = receive(); n == nil || n.Pdu.Type != ber.TypeInformRequest {
+		t.Fatalf("TestTrapListener: inform %+v", n)
+	}
+	sender.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, maxMessageSize)
+	size, err := sender.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ber.DecodeMessage(buf[:size])
+	if err != nil || resp.Pdu.Type != ber.TypeResponse || resp.Pdu.RequestId != 2 || len(resp.Pdu.Varbinds) != 3 {
+		t.Errorf("TestTrapListener: inform response %+v, %v", resp, err)
+	}
+
+	// trap without snmpTrapOID.0 is dropped
+	send(ber.Message{Version: ber.Version2c, Community: "public",
+		Pdu: ber.Pdu{Type: ber.TypeTrapV2, RequestId: 3, Varbinds: v2Varbinds[2:]}})
+	if n = receive(); n != nil {
+		t.Errorf("TestTrapListener: malformed trap %+v", n)
+	}
+}
@@ -0,0 +1,105 @@
+package usm
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"strconv"
+)
+
+// AuthProtocol is the authentication protocol of USM.
+type AuthProtocol byte
+
+const (
+	NoAuth AuthProtocol = iota
+	// MD5 is usmHMACMD5AuthProtocol (RFC 3414).
+	MD5
+	// SHA is usmHMACSHAAuthProtocol (RFC 3414).
+	SHA
+	// SHA224 is usmHMAC128SHA224AuthProtocol (RFC 7860).
+	SHA224
+	// SHA256 is usmHMAC192SHA256AuthProtocol (RFC 7860).
+	SHA256
+	// SHA384 is usmHMAC256SHA384AuthProtocol (RFC 7860).
+	SHA384
+	// SHA512 is usmHMAC384SHA512AuthProtocol (RFC 7860).
+	SHA512
+)
+
+type authEntry struct {
+	name string
+	hash func() hash.Hash
+	// macSize is the size of msgAuthenticationParameters,
+	// i.e. the truncated HMAC.
+	macSize int
+}
+
+var authTable = [...]authEntry{
+	NoAuth: {name: "NoAuth"},
+	MD5:    {name: "MD5", hash: md5.New, macSize: 12},
+	SHA:    {name: "SHA", hash: sha1.New, macSize: 12},
+	SHA224: {name: "SHA224", hash: sha256.New224, macSize: 16},
+	SHA256: {name: "SHA256", hash: sha256.New, macSize: 24},
+	SHA384: {name: "SHA384", hash: sha512.New384, macSize: 32},
+	SHA512: {name: "SHA512", hash: sha512.New, macSize: 48},
+}
+
+func (p AuthProtocol) IsKnown() bool {
+	return int(p) < len(authTable)
+}
+
+func (p AuthProtocol) String() string {
+	if !p.IsKnown() {
+		return "?" + strconv.Itoa(int(p))
+	}
+	return authTable[p].name
+}
+
+// MacSize returns the size of msgAuthenticationParameters.
+func (p AuthProtocol) MacSize() int {
+	return authTable[p].macSize
+}
+
+// PasswordToKey returns the user key Ku of the password
+// (RFC 3414, A.2). It returns nil for NoAuth.
+func PasswordToKey(p AuthProtocol, password string) []byte {
+	if p == NoAuth || len(password) == 0 {
+		return nil
+	}
+	const size = 1048576
+	h := authTable[p].hash()
+	var buf [64]byte
+	for i, n := 0, 0; n < size; n += len(buf) {
+		for j := range buf {
+			buf[j] = password[i]
+			if i++; i == len(password) {
+				i = 0
+			}
+		}
+		h.Write(buf[:])
+	}
+	return h.Sum(nil)
+}
+
+// LocalizeKey returns the key localized for the authoritative
+// engine, i.e. H(Ku || engineId || Ku) (RFC 3414, 2.6).
+func LocalizeKey(p AuthProtocol, ku, engineId []byte) []byte {
+	if p == NoAuth || ku == nil {
+		return nil
+	}
+	h := authTable[p].hash()
+	h.Write(ku)
+	h.Write(engineId)
+	h.Write(ku)
+	return h.Sum(nil)
+}
+
+// mac returns the truncated HMAC of data.
+func (p AuthProtocol) mac(key, data []byte) []byte {
+	m := hmac.New(authTable[p].hash, key)
+	m.Write(data)
+	return m.Sum(nil)[:authTable[p].macSize]
+}
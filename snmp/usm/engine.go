@@ -0,0 +1,163 @@
+package usm
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+)
+
+// TimeWindow is the time window of authenticated messages
+// (RFC 3414, 2.2.3).
+const TimeWindow = 150
+
+// Engine is the authoritative SNMP engine.
+type Engine struct {
+	Id    []byte
+	Boots int32
+	start time.Time
+
+	mu    sync.RWMutex
+	users map[string]*Keys
+	stats [len(statsErrors)]uint32
+}
+
+// NewEngine returns the engine with the engine id and the number
+// of times the engine has (re-)initialized itself.
+func NewEngine(id []byte, boots int32) *Engine {
+	return &Engine{
+		Id:    id,
+		Boots: boots,
+		start: time.Now(),
+		users: make(map[string]*Keys),
+	}
+}
+
+// Time returns the number of seconds since the engine start.
+func (e *Engine) Time() int32 {
+	return int32(time.Since(e.start) / time.Second)
+}
+
+// AddUser adds (or replaces) the user. The user keys are
+// localized for the engine.
+func (e *Engine) AddUser(u User) error {
+	if err := u.validate(); err != nil {
+		return err
+	}
+	keys := u.Localize(e.Id)
+	e.mu.Lock()
+	e.users[u.Name] = keys
+	e.mu.Unlock()
+	return nil
+}
+
+// RemoveUser removes the user.
+func (e *Engine) RemoveUser(name string) {
+	e.mu.Lock()
+	delete(e.users, name)
+	e.mu.Unlock()
+}
+
+// User returns the user with the name.
+func (e *Engine) User(name string) (User, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if keys := e.users[name]; keys != nil {
+		return keys.User, true
+	}
+	return User{}, false
+}
+
+// Stats returns the value of usmStats counter of the error.
+func (e *Engine) Stats(err *StatsError) uint32 {
+	return atomic.LoadUint32(&e.stats[err.subid])
+}
+
+func (e *Engine) lookup(sp SecurityParameters) (*Keys, error) {
+	if !bytes.Equal(sp.EngineId, e.Id) {
+		return nil, ErrUnknownEngineId
+	}
+	e.mu.RLock()
+	keys := e.users[sp.UserName]
+	e.mu.RUnlock()
+	if keys == nil {
+		return nil, ErrUnknownUserName
+	}
+	return keys, nil
+}
+
+func (e *Engine) check(sp SecurityParameters) error {
+	if sp.EngineBoots == maxEngineBoots || sp.EngineBoots != e.Boots {
+		return ErrNotInTimeWindow
+	}
+	if d := sp.EngineTime - e.Time(); d < -TimeWindow || d > TimeWindow {
+		return ErrNotInTimeWindow
+	}
+	return nil
+}
+
+// Decode decodes the incoming message and performs the security
+// checks (RFC 3414, 3.2). If the message fails the checks, Decode
+// returns *StatsError, and the returned message and security
+// parameters can be passed to Report.
+func (e *Engine) Decode(data []byte) (m ber.MessageV3, sp SecurityParameters, err error) {
+	m, sp, err = decodeMessage(data, e.lookup, e.check)
+	if serr, ok := err.(*StatsError); ok {
+		atomic.AddUint32(&e.stats[serr.subid], 1)
+	}
+	return
+}
+
+// Encode encodes the message (e.g. response) for the user. The
+// security level of the message is defined by m.Flags.
+func (e *Engine) Encode(m ber.MessageV3, user string) ([]byte, error) {
+	e.mu.RLock()
+	keys := e.users[user]
+	e.mu.RUnlock()
+	return encodeMessage(m, e.params(user), keys)
+}
+
+func (e *Engine) params(user string) SecurityParameters {
+	return SecurityParameters{
+		EngineId:    e.Id,
+		EngineBoots: e.Boots,
+		EngineTime:  e.Time(),
+		UserName:    user,
+	}
+}
+
+// Report returns the encoded Report message for the error returned
+// by Decode (RFC 3414, 3.1.1), or nil if the message is not
+// reportable. The report is authenticated for ErrNotInTimeWindow
+// only.
+func (e *Engine) Report(m ber.MessageV3, sp SecurityParameters, err *StatsError) []byte {
+	if m.Flags&ber.FlagReportable == 0 {
+		return nil
+	}
+	report := ber.MessageV3{
+		MsgId:   m.MsgId,
+		MaxSize: m.MaxSize,
+		ScopedPdu: ber.ScopedPdu{
+			ContextEngineId: e.Id,
+			Pdu: ber.Pdu{
+				Type:      ber.TypeReport,
+				RequestId: m.ScopedPdu.Pdu.RequestId,
+				Varbinds: []asn.Varbind{{
+					Oid:   err.Oid(),
+					Tag:   asn.TagCounter32,
+					Value: e.Stats(err),
+				}},
+			},
+		},
+	}
+	var keys *Keys
+	if err == ErrNotInTimeWindow {
+		report.Flags = ber.FlagAuth
+		keys, _ = e.lookup(sp)
+	}
+	data, _ := encodeMessage(report, e.params(sp.UserName), keys)
+	return data
+}
@@ -0,0 +1,135 @@
+package usm
+
+import (
+	"crypto/hmac"
+
+	"github.com/alexispb/mygosnmp/asn/ber"
+)
+
+// maxEngineBoots is the maximum value of snmpEngineBoots and
+// snmpEngineTime.
+const maxEngineBoots = 2147483647
+
+// encodeMessage secures the message with the keys and returns
+// the encoded message (RFC 3414, 3.1). The security level is
+// defined by m.Flags, and the plaintext scoped pdu is m.ScopedPdu.
+// The sp defines the engine and the user name.
+func encodeMessage(m ber.MessageV3, sp SecurityParameters, keys *Keys) ([]byte, error) {
+	m.SecurityModel = ber.SecurityModelUSM
+	auth, priv := m.Flags&ber.FlagAuth != 0, m.Flags&ber.FlagPriv != 0
+	if auth && (keys == nil || keys.AuthKey == nil) || priv && (!auth || keys.PrivKey == nil) {
+		return nil, ErrUnsupportedSecLevel
+	}
+
+	if priv {
+		data, err := ber.AppendScopedPdu(nil, m.ScopedPdu)
+		if err != nil {
+			return nil, err
+		}
+		m.EncryptedPdu, sp.PrivParameters, err = keys.User.Priv.encrypt(
+			keys.PrivKey, sp.EngineBoots, sp.EngineTime, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if auth {
+		// placeholder for HMAC
+		sp.AuthParameters = make([]byte, keys.User.Auth.MacSize())
+	}
+	m.SecurityParameters = appendSecurityParameters(nil, sp)
+
+	data, err := ber.EncodeMessageV3(m)
+	if err != nil || !auth {
+		return data, err
+	}
+	offset, err := authParametersOffset(data)
+	if err != nil {
+		return nil, err
+	}
+	copy(data[offset:], keys.User.Auth.mac(keys.AuthKey, data))
+	return data, nil
+}
+
+// authParametersOffset returns the offset of msgAuthenticationParameters
+// content in the encoded message.
+func authParametersOffset(data []byte) (int, error) {
+	offset, err := ber.SecurityParametersOffset(data)
+	if err != nil {
+		return 0, err
+	}
+	m, err := ber.DecodeMessageV3(data)
+	if err != nil {
+		return 0, err
+	}
+	_, authOffset, err := decodeSecurityParameters(m.SecurityParameters)
+	return offset + authOffset, err
+}
+
+// decodeMessage decodes the message and performs the security
+// checks (RFC 3414, 3.2). The lookup function returns the keys
+// of the user defined by the security parameters (or an error
+// if the engine or the user is unknown). The check function
+// checks timeliness of authenticated message. The message (except
+// report) must have the security level of the user. On error, the
+// returned message and the security parameters include the
+// decoded fields (so they can be used for report).
+func decodeMessage(data []byte, lookup func(sp SecurityParameters) (*Keys, error),
+	check func(sp SecurityParameters) error) (m ber.MessageV3, sp SecurityParameters, err error) {
+
+	if m, err = ber.DecodeMessageV3(data); err != nil {
+		return
+	}
+	if m.SecurityModel != ber.SecurityModelUSM {
+		return m, sp, ber.ErrValue
+	}
+	sp, authOffset, err := decodeSecurityParameters(m.SecurityParameters)
+	if err != nil {
+		return
+	}
+
+	keys, err := lookup(sp)
+	if err != nil {
+		return
+	}
+	auth, priv := m.Flags&ber.FlagAuth != 0, m.Flags&ber.FlagPriv != 0
+	if auth && (keys == nil || keys.AuthKey == nil) || priv && (!auth || keys.PrivKey == nil) {
+		return m, sp, ErrUnsupportedSecLevel
+	}
+	// the message below the security level of the user would
+	// bypass the authentication, only the reports (e.g. of the
+	// discovery) may be unauthenticated
+	if keys != nil && m.Flags&(ber.FlagAuth|ber.FlagPriv) != keys.User.Flags() &&
+		(priv || m.ScopedPdu.Pdu.Type != ber.TypeReport) {
+		return m, sp, ErrUnsupportedSecLevel
+	}
+	if !auth {
+		return
+	}
+
+	if len(sp.AuthParameters) != keys.User.Auth.MacSize() {
+		return m, sp, ErrWrongDigest
+	}
+	offset, err := ber.SecurityParametersOffset(data)
+	if err != nil {
+		return
+	}
+	zeroed := append([]byte(nil), data...)
+	offset += authOffset
+	copy(zeroed[offset:offset+len(sp.AuthParameters)], make([]byte, len(sp.AuthParameters)))
+	if !hmac.Equal(sp.AuthParameters, keys.User.Auth.mac(keys.AuthKey, zeroed)) {
+		return m, sp, ErrWrongDigest
+	}
+
+	if err = check(sp); err != nil || !priv {
+		return
+	}
+	decrypted, err := keys.User.Priv.decrypt(keys.PrivKey,
+		sp.EngineBoots, sp.EngineTime, sp.PrivParameters, m.EncryptedPdu)
+	if err != nil {
+		return
+	}
+	if m.ScopedPdu, err = ber.DecodeScopedPdu(decrypted); err != nil {
+		return m, sp, ErrDecryption
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package usm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+	"sync/atomic"
+)
+
+// PrivProtocol is the privacy protocol of USM.
+type PrivProtocol byte
+
+const (
+	NoPriv PrivProtocol = iota
+	// DES is usmDESPrivProtocol (CBC-DES, RFC 3414).
+	DES
+	// AES is usmAesCfb128Protocol (RFC 3826).
+	AES
+)
+
+var privNames = [...]string{"NoPriv", "DES", "AES"}
+
+func (p PrivProtocol) IsKnown() bool {
+	return int(p) < len(privNames)
+}
+
+func (p PrivProtocol) String() string {
+	if !p.IsKnown() {
+		return "?" + strconv.Itoa(int(p))
+	}
+	return privNames[p]
+}
+
+// salt is the local integer used for msgPrivacyParameters. It is
+// initialized with a random value (RFC 3414, 8.1.1.1).
+var salt = func() uint64 {
+	var b [8]byte
+	rand.Read(b[:])
+	return binary.BigEndian.Uint64(b[:])
+}()
+
+func nextSalt() uint64 {
+	return atomic.AddUint64(&salt, 1)
+}
+
+// encrypt returns the encrypted scoped pdu data and msgPrivacyParameters.
+// The first 16 bytes of the localized key are used (DES uses the
+// second 8 bytes as pre-IV).
+func (p PrivProtocol) encrypt(key []byte, boots, time int32, data []byte) (encrypted, params []byte, err error) {
+	params = make([]byte, 8)
+	switch p {
+	case DES:
+		binary.BigEndian.PutUint32(params, uint32(boots))
+		binary.BigEndian.PutUint32(params[4:], uint32(nextSalt()))
+		block, err := des.NewCipher(key[:8])
+		if err != nil {
+			return nil, nil, err
+		}
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = key[8+i] ^ params[i]
+		}
+		// the padding is appended to the copy, data may be shared
+		// with the caller
+		n := (len(data) + des.BlockSize - 1) / des.BlockSize * des.BlockSize
+		encrypted = make([]byte, n)
+		copy(encrypted, data)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+	case AES:
+		binary.BigEndian.PutUint64(params, nextSalt())
+		block, err := aes.NewCipher(key[:16])
+		if err != nil {
+			return nil, nil, err
+		}
+		encrypted = make([]byte, len(data))
+		cipher.NewCFBEncrypter(block, aesIV(boots, time, params)).XORKeyStream(encrypted, data)
+	}
+	return
+}
+
+// decrypt returns the decrypted scoped pdu data. The data may
+// include padding.
+func (p PrivProtocol) decrypt(key []byte, boots, time int32, params, encrypted []byte) ([]byte, error) {
+	if len(params) != 8 {
+		return nil, ErrDecryption
+	}
+	data := make([]byte, len(encrypted))
+	switch p {
+	case DES:
+		if len(encrypted)%des.BlockSize != 0 {
+			return nil, ErrDecryption
+		}
+		block, err := des.NewCipher(key[:8])
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = key[8+i] ^ params[i]
+		}
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, encrypted)
+	case AES:
+		block, err := aes.NewCipher(key[:16])
+		if err != nil {
+			return nil, err
+		}
+		cipher.NewCFBDecrypter(block, aesIV(boots, time, params)).XORKeyStream(data, encrypted)
+	}
+	return data, nil
+}
+
+// aesIV returns the concatenation of engine boots, engine time,
+// and salt (RFC 3826, 3.1.2.1).
+func aesIV(boots, time int32, params []byte) []byte {
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint32(iv, uint32(boots))
+	binary.BigEndian.PutUint32(iv[4:], uint32(time))
+	copy(iv[8:], params)
+	return iv
+}
@@ -0,0 +1,141 @@
+package usm
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/alexispb/mygosnmp/asn/ber"
+)
+
+// Remote is the state of the authoritative engine kept by the
+// non-authoritative engine for the user (RFC 3414, 2.3).
+type Remote struct {
+	User User
+
+	mu     sync.Mutex
+	id     []byte
+	keys   *Keys
+	boots  int32
+	time   int32
+	latest int32
+	synced time.Time
+}
+
+// NewRemote returns the remote engine state for the user. The
+// engine is not discovered yet.
+func NewRemote(u User) (*Remote, error) {
+	if err := u.validate(); err != nil {
+		return nil, err
+	}
+	return &Remote{User: u}, nil
+}
+
+// EngineId returns the id of the discovered engine, or nil.
+func (r *Remote) EngineId() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.id
+}
+
+// Discovered reports whether the engine id is known.
+func (r *Remote) Discovered() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.id != nil
+}
+
+// estimated returns the estimated engine time.
+func (r *Remote) estimated() int32 {
+	return r.time + int32(time.Since(r.synced)/time.Second)
+}
+
+// EncodeDiscovery returns the encoded discovery message, i.e. the
+// unauthenticated request with empty engine id and user name
+// (RFC 3414, 4).
+func (r *Remote) EncodeDiscovery(msgId, maxSize int32) ([]byte, error) {
+	m := ber.MessageV3{
+		MsgId:     msgId,
+		MaxSize:   maxSize,
+		Flags:     ber.FlagReportable,
+		ScopedPdu: ber.ScopedPdu{Pdu: ber.Pdu{Type: ber.TypeGetRequest, RequestId: msgId}},
+	}
+	return encodeMessage(m, SecurityParameters{}, nil)
+}
+
+// Encode encodes the request message with the security level of
+// the user. The engine should be discovered.
+func (r *Remote) Encode(m ber.MessageV3) ([]byte, error) {
+	r.mu.Lock()
+	sp := SecurityParameters{
+		EngineId: r.id,
+		UserName: r.User.Name,
+	}
+	if r.User.Auth != NoAuth {
+		sp.EngineBoots, sp.EngineTime = r.boots, r.estimated()
+	}
+	keys := r.keys
+	r.mu.Unlock()
+	m.Flags |= r.User.Flags()
+	return encodeMessage(m, sp, keys)
+}
+
+// Decode decodes the response (or report) message and performs
+// the security checks (RFC 3414, 3.2). The engine state is updated
+// by the authenticated messages, and by the unknownEngineIDs report
+// during discovery. Report with usmStats counter is returned as
+// *StatsError.
+func (r *Remote) Decode(data []byte) (m ber.MessageV3, err error) {
+	m, sp, err := decodeMessage(data, r.lookup, r.check)
+	if err != nil {
+		return
+	}
+	if m.ScopedPdu.Pdu.Type != ber.TypeReport {
+		return
+	}
+	serr := ReportError(m.ScopedPdu.Pdu)
+	if serr == nil {
+		return
+	}
+	if serr == ErrUnknownEngineId && len(sp.EngineId) != 0 {
+		r.mu.Lock()
+		if r.id == nil {
+			r.id = append([]byte(nil), sp.EngineId...)
+			r.keys = r.User.Localize(r.id)
+			// the unauthenticated time is used until the first
+			// authenticated message (RFC 3414, 4)
+			r.boots, r.time, r.latest = sp.EngineBoots, sp.EngineTime, 0
+			r.synced = time.Now()
+		}
+		r.mu.Unlock()
+	}
+	return m, serr
+}
+
+func (r *Remote) lookup(sp SecurityParameters) (*Keys, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.id != nil && !bytes.Equal(sp.EngineId, r.id) {
+		return nil, ErrUnknownEngineId
+	}
+	if sp.UserName != r.User.Name && sp.UserName != "" {
+		return nil, ErrUnknownUserName
+	}
+	return r.keys, nil
+}
+
+// check updates the engine time by the authenticated message and
+// checks its timeliness (RFC 3414, 3.2.7.b).
+func (r *Remote) check(sp SecurityParameters) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sp.EngineBoots > r.boots || sp.EngineBoots == r.boots && sp.EngineTime > r.latest {
+		r.boots, r.time, r.latest = sp.EngineBoots, sp.EngineTime, sp.EngineTime
+		r.synced = time.Now()
+	}
+	if r.boots == maxEngineBoots || sp.EngineBoots < r.boots ||
+		sp.EngineBoots == r.boots && sp.EngineTime < r.latest-TimeWindow {
+		return ErrNotInTimeWindow
+	}
+	return nil
+}
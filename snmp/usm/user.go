@@ -0,0 +1,52 @@
+package usm
+
+import "github.com/alexispb/mygosnmp/asn/ber"
+
+// User defines the security name and the protocols of the user.
+// The privacy key is derived from PrivPassword with the hash
+// function of the authentication protocol (RFC 3414, 2.6).
+type User struct {
+	Name         string
+	Auth         AuthProtocol
+	AuthPassword string
+	Priv         PrivProtocol
+	PrivPassword string
+}
+
+// Flags returns the security level of the user.
+func (u User) Flags() ber.MsgFlags {
+	var flags ber.MsgFlags
+	if u.Auth != NoAuth {
+		flags |= ber.FlagAuth
+		if u.Priv != NoPriv {
+			flags |= ber.FlagPriv
+		}
+	}
+	return flags
+}
+
+func (u User) validate() error {
+	if u.Priv != NoPriv && u.Auth == NoAuth || !u.Auth.IsKnown() || !u.Priv.IsKnown() {
+		return ErrUser
+	}
+	return nil
+}
+
+// Keys are the keys of the user localized for the engine.
+type Keys struct {
+	User    User
+	AuthKey []byte
+	PrivKey []byte
+}
+
+// Localize returns the keys of the user localized for the engine.
+func (u User) Localize(engineId []byte) *Keys {
+	keys := &Keys{User: u}
+	if u.Auth != NoAuth {
+		keys.AuthKey = LocalizeKey(u.Auth, PasswordToKey(u.Auth, u.AuthPassword), engineId)
+		if u.Priv != NoPriv {
+			keys.PrivKey = LocalizeKey(u.Auth, PasswordToKey(u.Auth, u.PrivPassword), engineId)
+		}
+	}
+	return keys
+}
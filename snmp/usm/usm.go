@@ -0,0 +1,154 @@
+/*
+Package usm implements the User-based Security Model of SNMPv3
+(RFC 3414) with the SHA-2 authentication protocols (RFC 7860)
+and the AES privacy protocol (RFC 3826).
+
+The Engine is the authoritative SNMP engine (e.g. agent receiving
+requests). It decodes incoming messages for the registered users
+and encodes responses and reports:
+
+	engine := usm.NewEngine(engineId, boots)
+	engine.AddUser(usm.User{
+		Name:         "admin",
+		Auth:         usm.SHA256,
+		AuthPassword: "authpassword",
+		Priv:         usm.AES,
+		PrivPassword: "privpassword",
+	})
+
+The Remote is the state of an authoritative engine kept by the
+non-authoritative engine (e.g. manager sending requests). It
+performs engine discovery and time synchronization (RFC 3414,
+section 4). The snmp.Client and snmp.Agent use Remote and Engine
+respectively.
+*/
+package usm
+
+import (
+	"errors"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+var ErrUser = errors.New("usm: privacy requires authentication")
+
+// StatsError is the error of the incoming message processing
+// (RFC 3414, 3.2). Each error corresponds to a usmStats counter
+// which is reported to the sender of the message.
+type StatsError struct {
+	name  string
+	subid uint32
+}
+
+var (
+	ErrUnsupportedSecLevel = &StatsError{name: "unsupported security level", subid: 1}
+	ErrNotInTimeWindow     = &StatsError{name: "not in time window", subid: 2}
+	ErrUnknownUserName     = &StatsError{name: "unknown user name", subid: 3}
+	ErrUnknownEngineId     = &StatsError{name: "unknown engine id", subid: 4}
+	ErrWrongDigest         = &StatsError{name: "wrong digest", subid: 5}
+	ErrDecryption          = &StatsError{name: "decryption error", subid: 6}
+)
+
+var statsErrors = [...]*StatsError{
+	nil,
+	ErrUnsupportedSecLevel,
+	ErrNotInTimeWindow,
+	ErrUnknownUserName,
+	ErrUnknownEngineId,
+	ErrWrongDigest,
+	ErrDecryption,
+}
+
+// usmStats is the prefix of usmStats counters.
+var usmStats = []uint32{1, 3, 6, 1, 6, 3, 15, 1, 1}
+
+func (e *StatsError) Error() string {
+	return "usm: " + e.name
+}
+
+// Oid returns the instance identifier of the usmStats counter.
+func (e *StatsError) Oid() []uint32 {
+	return oid.Cat(usmStats, e.subid, 0)
+}
+
+// ReportError returns the StatsError reported by the Report pdu,
+// or nil if the report is not a usmStats report.
+func ReportError(pdu ber.Pdu) *StatsError {
+	if len(pdu.Varbinds) == 0 {
+		return nil
+	}
+	id := pdu.Varbinds[0].Oid
+	if len(id) != len(usmStats)+2 || !oid.HasPrefix(id, usmStats...) || id[len(id)-1] != 0 {
+		return nil
+	}
+	if subid := id[len(usmStats)]; 0 < subid && int(subid) < len(statsErrors) {
+		return statsErrors[subid]
+	}
+	return nil
+}
+
+// SecurityParameters is UsmSecurityParameters (RFC 3414, 2.4).
+type SecurityParameters struct {
+	EngineId       []byte
+	EngineBoots    int32
+	EngineTime     int32
+	UserName       string
+	AuthParameters []byte
+	PrivParameters []byte
+}
+
+func appendSecurityParameters(dst []byte, sp SecurityParameters) []byte {
+	tagOctetString := byte(asn.TagOctetString)
+	content := ber.AppendOctetString(nil, tagOctetString, sp.EngineId)
+	content = ber.AppendInteger(content, byte(asn.TagInteger32), int64(sp.EngineBoots))
+	content = ber.AppendInteger(content, byte(asn.TagInteger32), int64(sp.EngineTime))
+	content = ber.AppendOctetString(content, tagOctetString, []byte(sp.UserName))
+	content = ber.AppendOctetString(content, tagOctetString, sp.AuthParameters)
+	content = ber.AppendOctetString(content, tagOctetString, sp.PrivParameters)
+	return ber.AppendTLV(dst, byte(asn.TagSequence), content)
+}
+
+// decodeSecurityParameters returns the decoded security parameters
+// and the offset of AuthParameters in data.
+func decodeSecurityParameters(data []byte) (sp SecurityParameters, authOffset int, err error) {
+	r := ber.NewReader(data)
+	s, err := r.ReadSequence(byte(asn.TagSequence), "SecurityParameters")
+	if err != nil {
+		return
+	}
+	tagOctetString := byte(asn.TagOctetString)
+	if sp.EngineId, err = s.ReadOctetString(tagOctetString, "EngineId"); err != nil {
+		return
+	}
+	var v int64
+	if v, err = s.ReadInteger(byte(asn.TagInteger32), "EngineBoots"); err != nil {
+		return
+	}
+	if v < 0 || v > maxEngineBoots {
+		return sp, 0, ber.ErrValue
+	}
+	sp.EngineBoots = int32(v)
+	if v, err = s.ReadInteger(byte(asn.TagInteger32), "EngineTime"); err != nil {
+		return
+	}
+	if v < 0 || v > maxEngineBoots {
+		return sp, 0, ber.ErrValue
+	}
+	sp.EngineTime = int32(v)
+	var name []byte
+	if name, err = s.ReadOctetString(tagOctetString, "UserName"); err != nil {
+		return
+	}
+	sp.UserName = string(name)
+	if sp.AuthParameters, err = s.ReadOctetString(tagOctetString, "AuthParameters"); err != nil {
+		return
+	}
+	authOffset = s.Offset() - len(sp.AuthParameters)
+	if sp.PrivParameters, err = s.ReadOctetString(tagOctetString, "PrivParameters"); err != nil {
+		return
+	}
+	err = s.End("SecurityParameters")
+	return
+}
@@ -0,0 +1,241 @@
+package usm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/hex"
+	"github.com/alexispb/mygosnmp/internal"
+)
+
+// RFC 3414, A.3
+var testDataKeys = []struct {
+	auth AuthProtocol
+	ku   []byte
+	kul  []byte
+}{
+	{
+		auth: MD5,
+		ku:   hex.MustParse("9f af 32 83 88 4e 92 83 4e bc 98 47 d8 ed d9 63"),
+		kul:  hex.MustParse("52 6f 5e ed 9f cc e2 6f 89 64 c2 93 07 87 d8 2b"),
+	},
+	{
+		auth: SHA,
+		ku:   hex.MustParse("9f b5 cc 03 81 49 7b 37 93 52 89 39 ff 78 8d 5d 79 14 52 11"),
+		kul:  hex.MustParse("66 95 fe bc 92 88 e3 62 82 23 5f c7 15 1f 12 84 97 b3 8f 3f"),
+	},
+}
+
+func TestKeys(t *testing.T) {
+	engineId := hex.MustParse("00 00 00 00 00 00 00 00 00 00 00 02")
+	for i, test := range testDataKeys {
+		ku := PasswordToKey(test.auth, "maplesyrup")
+		if !bytes.Equal(ku, test.ku) {
+			t.Errorf("TestKeys[%d]: Ku:\n%s", i, hex.DumpDiff(ku, test.ku))
+		}
+		kul := LocalizeKey(test.auth, ku, engineId)
+		if !bytes.Equal(kul, test.kul) {
+			t.Errorf("TestKeys[%d]: Kul:\n%s", i, hex.DumpDiff(kul, test.kul))
+		}
+	}
+}
+
+var testDataUsers = []User{
+	{Name: "noauth"},
+	{Name: "md5", Auth: MD5, AuthPassword: "md5password"},
+	{Name: "sha-des", Auth: SHA, AuthPassword: "shapassword", Priv: DES, PrivPassword: "despassword"},
+	{Name: "sha224-aes", Auth: SHA224, AuthPassword: "sha224password", Priv: AES, PrivPassword: "aespassword"},
+	{Name: "sha512-aes", Auth: SHA512, AuthPassword: "sha512password", Priv: AES, PrivPassword: "aespassword"},
+}
+
+func testRequest(msgId int32) ber.MessageV3 {
+	return ber.MessageV3{
+		MsgId:   msgId,
+		MaxSize: 65507,
+		Flags:   ber.FlagReportable,
+		ScopedPdu: ber.ScopedPdu{
+			ContextName: "context",
+			Pdu: ber.Pdu{
+				Type:      ber.TypeGetRequest,
+				RequestId: msgId,
+				Varbinds: []asn.Varbind{
+					{Oid: []uint32{1, 3, 6, 1, 2, 1, 1, 5, 0}, Tag: asn.TagNull},
+				},
+			},
+		},
+	}
+}
+
+func TestEngine(t *testing.T) {
+	engine := NewEngine([]byte("engine"), 1)
+	for i, user := range testDataUsers {
+		if err := engine.AddUser(user); err != nil {
+			t.Fatalf("TestEngine[%d]: AddUser: %v", i, err)
+		}
+		remote, err := NewRemote(user)
+		if err != nil {
+			t.Fatalf("TestEngine[%d]: NewRemote: %v", i, err)
+		}
+
+		// discovery
+		data, err := remote.EncodeDiscovery(1, 65507)
+		if err != nil {
+			t.Fatalf("TestEngine[%d]: EncodeDiscovery: %v", i, err)
+		}
+		m, sp, err := engine.Decode(data)
+		if err != ErrUnknownEngineId {
+			t.Fatalf("TestEngine[%d]: discovery error: %v", i, err)
+		}
+		if _, err = remote.Decode(engine.Report(m, sp, ErrUnknownEngineId)); err != ErrUnknownEngineId {
+			t.Fatalf("TestEngine[%d]: discovery report error: %v", i, err)
+		}
+		if !remote.Discovered() || !bytes.Equal(remote.EngineId(), engine.Id) {
+			t.Fatalf("TestEngine[%d]: engine id %q is not discovered", i, remote.EngineId())
+		}
+
+		// request and response
+		req := testRequest(2)
+		req.ScopedPdu.ContextEngineId = engine.Id
+		if data, err = remote.Encode(req); err != nil {
+			t.Fatalf("TestEngine[%d]: Encode: %v", i, err)
+		}
+		m, sp, err = engine.Decode(data)
+		if err != nil {
+			t.Fatalf("TestEngine[%d]: Decode: %v", i, err)
+		}
+		if sp.UserName != user.Name || m.Flags != user.Flags()|ber.FlagReportable {
+			t.Errorf("TestEngine[%d]: user %q, flags %d", i, sp.UserName, m.Flags)
+		}
+		if diff := internal.StructsDiff(m.ScopedPdu, req.ScopedPdu); diff != "" {
+			t.Errorf("TestEngine[%d]: request:\n%s", i, diff)
+		}
+
+		resp := m
+		resp.Flags &^= ber.FlagReportable
+		resp.ScopedPdu.Pdu.Type = ber.TypeResponse
+		resp.ScopedPdu.Pdu.Varbinds[0] = asn.Varbind{
+			Oid: []uint32{1, 3, 6, 1, 2, 1, 1, 5, 0}, Tag: asn.TagOctetString, Value: "name"}
+		if data, err = engine.Encode(resp, sp.UserName); err != nil {
+			t.Fatalf("TestEngine[%d]: Encode response: %v", i, err)
+		}
+		if m, err = remote.Decode(data); err != nil {
+			t.Fatalf("TestEngine[%d]: Decode response: %v", i, err)
+		}
+		if diff := internal.StructsDiff(m.ScopedPdu, resp.ScopedPdu); diff != "" {
+			t.Errorf("TestEngine[%d]: response:\n%s", i, diff)
+		}
+	}
+}
+
+func TestEngineErrors(t *testing.T) {
+	engine := NewEngine([]byte("engine"), 1)
+	user := testDataUsers[3]
+	engine.AddUser(user)
+	keys := user.Localize(engine.Id)
+	encode := func(flags ber.MsgFlags, sp SecurityParameters, keys *Keys) []byte {
+		m := testRequest(1)
+		m.Flags |= flags
+		data, err := encodeMessage(m, sp, keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	sp := SecurityParameters{EngineId: engine.Id, EngineBoots: 1, UserName: user.Name}
+	authPriv := ber.FlagAuth | ber.FlagPriv
+	tampered := encode(authPriv, sp, keys)
+	tampered[len(tampered)-1] ^= 1
+	wrongKeys := User{Auth: user.Auth, AuthPassword: "wrong", Priv: AES, PrivPassword: "wrong"}.Localize(engine.Id)
+	unknownUser := sp
+	unknownUser.UserName = "unknown"
+	oldBoots := sp
+	oldBoots.EngineBoots = 0
+	oldTime := sp
+	oldTime.EngineTime = engine.Time() + TimeWindow + 1
+
+	for i, test := range []struct {
+		data []byte
+		err  error
+	}{
+		{data: encode(0, SecurityParameters{UserName: user.Name}, nil), err: ErrUnknownEngineId},
+		{data: encode(0, unknownUser, nil), err: ErrUnknownUserName},
+		{data: encode(authPriv, sp, keys), err: nil},
+		{data: tampered, err: ErrWrongDigest},
+		{data: encode(authPriv, sp, wrongKeys), err: ErrWrongDigest},
+		{data: encode(authPriv, oldBoots, keys), err: ErrNotInTimeWindow},
+		{data: encode(authPriv, oldTime, keys), err: ErrNotInTimeWindow},
+		// below the security level of the user
+		{data: encode(ber.FlagAuth, sp, keys), err: ErrUnsupportedSecLevel},
+		{data: encode(0, sp, nil), err: ErrUnsupportedSecLevel},
+		{data: []byte{0x30, 0x00}, err: ber.ErrTruncated},
+	} {
+		_, _, err := engine.Decode(test.data)
+		if !errors.Is(err, test.err) {
+			t.Errorf("TestEngineErrors[%d]: error %v, expected %v", i, err, test.err)
+		}
+	}
+	if n := engine.Stats(ErrWrongDigest); n != 2 {
+		t.Errorf("TestEngineErrors: wrong digest stats %d, expected 2", n)
+	}
+
+	// unsupported security level
+	noauth := testDataUsers[0]
+	engine.AddUser(noauth)
+	sp.UserName = noauth.Name
+	if _, _, err := engine.Decode(encode(ber.FlagAuth, sp, keys)); err != ErrUnsupportedSecLevel {
+		t.Errorf("TestEngineErrors: error %v, expected %v", err, ErrUnsupportedSecLevel)
+	}
+	if err := engine.AddUser(User{Name: "bad", Priv: AES}); err != ErrUser {
+		t.Errorf("TestEngineErrors: AddUser error %v, expected %v", err, ErrUser)
+	}
+}
+
+func TestNotInTimeWindow(t *testing.T) {
+	engine := NewEngine([]byte("engine"), 5)
+	user := testDataUsers[1]
+	engine.AddUser(user)
+	remote, _ := NewRemote(user)
+
+	// stale engine time learned from discovery
+	remote.id = engine.Id
+	remote.keys = user.Localize(engine.Id)
+	remote.boots = 4
+
+	data, _ := remote.Encode(testRequest(1))
+	m, sp, err := engine.Decode(data)
+	if err != ErrNotInTimeWindow {
+		t.Fatalf("TestNotInTimeWindow: error %v", err)
+	}
+	report := engine.Report(m, sp, ErrNotInTimeWindow)
+	if m, err = remote.Decode(report); err != ErrNotInTimeWindow {
+		t.Fatalf("TestNotInTimeWindow: report error %v", err)
+	}
+	if m.Flags != ber.FlagAuth || m.MsgId != 1 {
+		t.Errorf("TestNotInTimeWindow: report flags %d, msgId %d", m.Flags, m.MsgId)
+	}
+
+	data, _ = remote.Encode(testRequest(2))
+	if _, _, err = engine.Decode(data); err != nil {
+		t.Errorf("TestNotInTimeWindow: resent request error %v", err)
+	}
+}
+
+func TestEncryptData(t *testing.T) {
+	key := make([]byte, 16)
+	buf := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 0xEE, 0xEE, 0xEE, 0xEE, 0xEE, 0xEE}
+	data := buf[:10]
+	encrypted, params, err := DES.encrypt(key, 1, 0, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf[10:], []byte{0xEE, 0xEE, 0xEE, 0xEE, 0xEE, 0xEE}) {
+		t.Errorf("TestEncryptData: padding is written to the caller's buffer % x", buf)
+	}
+	decrypted, err := DES.decrypt(key, 1, 0, params, encrypted)
+	if err != nil || !bytes.Equal(decrypted[:len(data)], data) {
+		t.Errorf("TestEncryptData: decrypted % x, %v", decrypted, err)
+	}
+}
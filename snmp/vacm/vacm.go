@@ -0,0 +1,229 @@
+/*
+Package vacm implements the View-based Access Control Model
+(RFC 3415) used by snmp.Agent.
+
+The security name (community or USM user name) is mapped to
+a group, and the access entry of the group (selected by the
+context, the security model and the security level) defines
+the read, write and notify views. A view is a family of
+included and excluded subtrees:
+
+	v := vacm.New()
+	v.AddGroup(vacm.ModelV2c, "public", "readers")
+	v.AddAccess(vacm.Access{Group: "readers", Level: vacm.NoAuthNoPriv, ReadView: "system"})
+	v.AddView("system", oid.MustParse("1.3.6.1.2.1.1"), nil, true)
+*/
+package vacm
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/alexispb/mygosnmp/asn/ber"
+)
+
+// Errors of IsAccessAllowed (RFC 3415, 3.2).
+var (
+	ErrNoGroupName   = errors.New("vacm: no group name")
+	ErrNoAccessEntry = errors.New("vacm: no access entry")
+	ErrNoSuchView    = errors.New("vacm: no such view")
+	ErrNotInView     = errors.New("vacm: not in view")
+)
+
+// SecurityModel is the SNMP security model.
+type SecurityModel int32
+
+const (
+	// ModelAny matches any security model in Access.
+	ModelAny SecurityModel = 0
+	ModelV1  SecurityModel = 1
+	ModelV2c SecurityModel = 2
+	ModelUSM SecurityModel = ber.SecurityModelUSM
+)
+
+// ModelOf returns the security model of SNMPv1/v2c version.
+func ModelOf(version ber.Version) SecurityModel {
+	if version == ber.Version1 {
+		return ModelV1
+	}
+	return ModelV2c
+}
+
+// Level is the SNMP security level.
+type Level int
+
+const (
+	NoAuthNoPriv Level = 1
+	AuthNoPriv   Level = 2
+	AuthPriv     Level = 3
+)
+
+// LevelOf returns the security level of SNMPv3 message flags.
+func LevelOf(flags ber.MsgFlags) Level {
+	switch {
+	case flags&ber.FlagPriv != 0:
+		return AuthPriv
+	case flags&ber.FlagAuth != 0:
+		return AuthNoPriv
+	}
+	return NoAuthNoPriv
+}
+
+// ViewType selects the view of Access.
+type ViewType int
+
+const (
+	Read ViewType = iota
+	Write
+	Notify
+)
+
+// Access is vacmAccessEntry. The entry matches the requests of
+// the Group with the context name equal to Context (or starting
+// with Context if Prefix is set), the security model Model, and
+// the security level at least Level. An empty view name means
+// no access.
+type Access struct {
+	Group      string
+	Context    string
+	Prefix     bool
+	Model      SecurityModel
+	Level      Level
+	ReadView   string
+	WriteView  string
+	NotifyView string
+}
+
+func (a *Access) matches(group, context string, model SecurityModel, level Level) bool {
+	return a.Group == group && (a.Model == ModelAny || a.Model == model) && a.Level <= level &&
+		(a.Context == context || a.Prefix && strings.HasPrefix(context, a.Context))
+}
+
+// better tests whether the access entry is preferred to other
+// matching entry (RFC 3415, 4, vacmAccessTable).
+func (a *Access) better(other *Access) bool {
+	if (a.Model != ModelAny) != (other.Model != ModelAny) {
+		return a.Model != ModelAny
+	}
+	if a.Prefix != other.Prefix {
+		return !a.Prefix
+	}
+	if len(a.Context) != len(other.Context) {
+		return len(a.Context) > len(other.Context)
+	}
+	return a.Level > other.Level
+}
+
+func (a *Access) view(viewType ViewType) string {
+	switch viewType {
+	case Read:
+		return a.ReadView
+	case Write:
+		return a.WriteView
+	}
+	return a.NotifyView
+}
+
+type groupKey struct {
+	model SecurityModel
+	name  string
+}
+
+// Vacm is the access control configuration. Methods of the Vacm
+// can be called concurrently.
+type Vacm struct {
+	mu     sync.RWMutex
+	groups map[groupKey]string
+	access []Access
+	views  map[string]*View
+}
+
+// New returns the empty configuration (no access).
+func New() *Vacm {
+	return &Vacm{
+		groups: make(map[groupKey]string),
+		views:  make(map[string]*View),
+	}
+}
+
+// AddGroup maps the security name of the security model to the
+// group (vacmSecurityToGroupEntry).
+func (v *Vacm) AddGroup(model SecurityModel, securityName, group string) {
+	v.mu.Lock()
+	v.groups[groupKey{model, securityName}] = group
+	v.mu.Unlock()
+}
+
+// Group returns the group of the security name.
+func (v *Vacm) Group(model SecurityModel, securityName string) (string, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	group, ok := v.groups[groupKey{model, securityName}]
+	return group, ok
+}
+
+// AddAccess adds the access entry.
+func (v *Vacm) AddAccess(a Access) {
+	v.mu.Lock()
+	v.access = append(v.access, a)
+	v.mu.Unlock()
+}
+
+// AddView adds the subtree to the view family (vacmViewTreeFamilyEntry).
+// The bit i of the mask (the most significant bit of the first
+// byte is bit 0) defines whether subtree[i] must match (1) or is
+// a wildcard (0). The missing bits are 1.
+func (v *Vacm) AddView(name string, subtree []uint32, mask []byte, included bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// the views are immutable, so the returned views are not
+	// affected by the changes
+	view := &View{}
+	if old := v.views[name]; old != nil {
+		view.families = append(view.families, old.families...)
+	}
+	view.Add(subtree, mask, included)
+	v.views[name] = view
+}
+
+// View returns the view of the request (RFC 3415, 3.2 steps 1-5).
+func (v *Vacm) View(model SecurityModel, securityName string, level Level,
+	context string, viewType ViewType) (*View, error) {
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	group, ok := v.groups[groupKey{model, securityName}]
+	if !ok {
+		return nil, ErrNoGroupName
+	}
+	var best *Access
+	for i := range v.access {
+		a := &v.access[i]
+		if a.matches(group, context, model, level) && (best == nil || a.better(best)) {
+			best = a
+		}
+	}
+	if best == nil {
+		return nil, ErrNoAccessEntry
+	}
+	view := v.views[best.view(viewType)]
+	if view == nil {
+		return nil, ErrNoSuchView
+	}
+	return view, nil
+}
+
+// IsAccessAllowed checks access to the object instance (RFC 3415, 3.2).
+func (v *Vacm) IsAccessAllowed(model SecurityModel, securityName string, level Level,
+	context string, viewType ViewType, id []uint32) error {
+
+	view, err := v.View(model, securityName, level, context, viewType)
+	if err != nil {
+		return err
+	}
+	if !view.Contains(id) {
+		return ErrNotInView
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package vacm
+
+import (
+	"testing"
+
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+var testDataView = []struct {
+	id       string
+	included bool
+}{
+	{id: "1.3.6.1.2.1.1.1.0", included: true},
+	{id: "1.3.6.1.2.1.1.9.1.2.1", included: false},
+	{id: "1.3.6.1.2.1.1.9.1.2.1.5", included: true},
+	{id: "1.3.6.1.2.1.2.2.1.1.1", included: true},
+	{id: "1.3.6.1.2.1.2.2.1.2.1", included: true},
+	{id: "1.3.6.1.2.1.2.2.1.3.1", included: false},
+	{id: "1.3.6.1.2.1.2.2.1.2.7", included: false},
+	{id: "1.3.6.1.2.1.2.2.1", included: false},
+	{id: "1.3.6.1.4.1", included: false},
+}
+
+func TestView(t *testing.T) {
+	v := &View{}
+	v.Add(oid.MustParse("1.3.6.1.2.1.1"), nil, true)
+	v.Add(oid.MustParse("1.3.6.1.2.1.1.9"), nil, false)
+	v.Add(oid.MustParse("1.3.6.1.2.1.1.9.1.2.1.5"), nil, true)
+	// ifEntry columns 1 and 2 for any index but 7 (the wildcard
+	// subids 10 and 11 are masked with 0xFF 0xC0)
+	v.Add(oid.MustParse("1.3.6.1.2.1.2.2.1.1.0"), []byte{0xFF, 0xC0}, true)
+	v.Add(oid.MustParse("1.3.6.1.2.1.2.2.1.2.0"), []byte{0xFF, 0xC0}, true)
+	v.Add(oid.MustParse("1.3.6.1.2.1.2.2.1.2.7"), nil, false)
+	for i, test := range testDataView {
+		if included := v.Contains(oid.MustParse(test.id)); included != test.included {
+			t.Errorf("TestView[%d]: %s included %t", i, test.id, included)
+		}
+	}
+
+	var nilView *View
+	if nilView.Contains(oid.MustParse("1.3")) {
+		t.Errorf("TestView: nil view contains oid")
+	}
+}
+
+var testDataViewSkip = []struct {
+	id    string
+	start string
+	ok    bool
+}{
+	{id: "1.3.6.1.2.1.1.9.1.1.0", start: "1.3.6.1.2.1.1.9.1.2.1.5", ok: true},
+	{id: "1.3.6.1.2.1.1.9.1.2.1.6", start: "1.3.6.1.2.1.1.10", ok: true},
+	{id: "1.3.6.1.2.1.1.9.1.3", start: "1.3.6.1.2.1.1.10", ok: true},
+	{id: "1.3.6.1.2.1.2.2.1.1.1", start: "1.3.6.1.4.1.9", ok: true},
+	{id: "1.3.6.1.4.1.8.1", start: "1.3.6.1.4.1.9", ok: true},
+	{id: "1.3.6.1.4.1.9.4294967295.4294967295.1", start: "1.3.6.1.4.1.10", ok: true},
+	{id: "1.3.6.1.4.1.10.1", ok: false},
+}
+
+func TestViewSkip(t *testing.T) {
+	v := &View{}
+	v.Add(oid.MustParse("1.3.6.1.2.1.1"), nil, true)
+	v.Add(oid.MustParse("1.3.6.1.2.1.1.9"), nil, false)
+	v.Add(oid.MustParse("1.3.6.1.2.1.1.9.1.2.1.5"), nil, true)
+	v.Add(oid.MustParse("1.3.6.1.4.1.9"), nil, true)
+	v.Add(oid.MustParse("1.3.6.1.4.1.9.4294967295.4294967295"), nil, false)
+	for i, test := range testDataViewSkip {
+		start, ok := v.Skip(oid.MustParse(test.id))
+		if ok != test.ok || ok && oid.String(start) != test.start {
+			t.Errorf("TestViewSkip[%d]: %s skipped to %s %t", i, test.id, oid.String(start), ok)
+		}
+	}
+
+	// nothing is skipped in the view with wildcards
+	v.Add(oid.MustParse("1.3.6.1.2.1.2.2.1.1.0"), []byte{0xFF, 0xC0}, true)
+	id := oid.MustParse("1.3.6.1.2.1.1.9.1.1.0")
+	if start, ok := v.Skip(id); !ok || oid.Ne(start, id) {
+		t.Errorf("TestViewSkip: wildcard view skipped to %s %t", oid.String(start), ok)
+	}
+}
+
+func TestVacm(t *testing.T) {
+	v := New()
+	v.AddGroup(ModelV2c, "public", "readers")
+	v.AddGroup(ModelUSM, "admin", "admins")
+	v.AddView("system", oid.MustParse("1.3.6.1.2.1.1"), nil, true)
+	v.AddView("all", oid.MustParse("1.3"), nil, true)
+	v.AddAccess(Access{Group: "readers", Level: NoAuthNoPriv, ReadView: "system"})
+	v.AddAccess(Access{Group: "admins", Level: NoAuthNoPriv, ReadView: "system"})
+	v.AddAccess(Access{Group: "admins", Model: ModelUSM, Level: AuthNoPriv, ReadView: "all", WriteView: "system"})
+	v.AddAccess(Access{Group: "admins", Model: ModelUSM, Level: AuthPriv, ReadView: "all", WriteView: "all"})
+	v.AddAccess(Access{Group: "admins", Context: "ctx", Prefix: true, Level: AuthNoPriv, ReadView: "none"})
+
+	sysDescr := oid.MustParse("1.3.6.1.2.1.1.1.0")
+	ifDescr := oid.MustParse("1.3.6.1.2.1.2.2.1.2.1")
+	for i, test := range []struct {
+		model    SecurityModel
+		name     string
+		level    Level
+		context  string
+		viewType ViewType
+		id       []uint32
+		err      error
+	}{
+		{model: ModelV2c, name: "public", level: NoAuthNoPriv, viewType: Read, id: sysDescr},
+		{model: ModelV2c, name: "public", level: NoAuthNoPriv, viewType: Read, id: ifDescr, err: ErrNotInView},
+		{model: ModelV2c, name: "public", level: NoAuthNoPriv, viewType: Write, id: sysDescr, err: ErrNoSuchView},
+		{model: ModelV1, name: "public", level: NoAuthNoPriv, viewType: Read, id: sysDescr, err: ErrNoGroupName},
+		{model: ModelV2c, name: "public", level: NoAuthNoPriv, context: "ctx", viewType: Read, id: sysDescr, err: ErrNoAccessEntry},
+		{model: ModelUSM, name: "admin", level: NoAuthNoPriv, viewType: Read, id: ifDescr, err: ErrNotInView},
+		{model: ModelUSM, name: "admin", level: AuthNoPriv, viewType: Read, id: ifDescr},
+		{model: ModelUSM, name: "admin", level: AuthNoPriv, viewType: Write, id: ifDescr, err: ErrNotInView},
+		{model: ModelUSM, name: "admin", level: AuthPriv, viewType: Write, id: ifDescr},
+		{model: ModelUSM, name: "admin", level: AuthPriv, context: "ctx1", viewType: Read, id: sysDescr, err: ErrNoSuchView},
+	} {
+		err := v.IsAccessAllowed(test.model, test.name, test.level, test.context, test.viewType, test.id)
+		if err != test.err {
+			t.Errorf("TestVacm[%d]: error %v, expected %v", i, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package vacm
+
+import (
+	"github.com/alexispb/mygosnmp/oid"
+)
+
+// family is a subtree of the view family.
+type family struct {
+	subtree  []uint32
+	mask     []byte
+	included bool
+}
+
+// matches tests whether id is in the subtree. The subids
+// with the mask bit 0 are wildcards.
+func (f *family) matches(id []uint32) bool {
+	if len(f.mask) == 0 {
+		return oid.HasPrefix(id, f.subtree...)
+	}
+	if len(id) < len(f.subtree) {
+		return false
+	}
+	for i, subid := range f.subtree {
+		if i/8 < len(f.mask) && f.mask[i/8]&(0x80>>(i%8)) == 0 {
+			continue
+		}
+		if id[i] != subid {
+			return false
+		}
+	}
+	return true
+}
+
+// View is the family of view subtrees. The nil view contains
+// nothing.
+type View struct {
+	families []family
+}
+
+// Add adds the included or excluded subtree (see Vacm.AddView).
+func (v *View) Add(subtree []uint32, mask []byte, included bool) {
+	v.families = append(v.families, family{
+		subtree:  oid.Clone(subtree),
+		mask:     append([]byte(nil), mask...),
+		included: included,
+	})
+}
+
+// Contains tests whether id is in the view. If several subtrees
+// contain id, the longest one (and then the lexicographically
+// greater one) is used (RFC 3415, 5, vacmViewTreeFamilyTable).
+func (v *View) Contains(id []uint32) bool {
+	if v == nil {
+		return false
+	}
+	var best *family
+	for i := range v.families {
+		f := &v.families[i]
+		if !f.matches(id) {
+			continue
+		}
+		if best == nil || len(f.subtree) > len(best.subtree) ||
+			len(f.subtree) == len(best.subtree) && oid.Compare(f.subtree, best.subtree) > 0 {
+			best = f
+		}
+	}
+	return best != nil && best.included
+}
+
+// wildcard tests whether the subtree has wildcard subids.
+func (f *family) wildcard() bool {
+	for i := range f.subtree {
+		if i/8 < len(f.mask) && f.mask[i/8]&(0x80>>(i%8)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Skip returns the oid from which the oids of the view following
+// id (which is not in the view) are to be searched, i.e. the least
+// oid which follows the excluded subtree containing id or starts
+// an included subtree. It returns ok = false if no oids of the
+// view follow id. If the view has wildcard subtrees, nothing is
+// skipped and id is returned.
+func (v *View) Skip(id []uint32) (start []uint32, ok bool) {
+	if v == nil {
+		return nil, false
+	}
+	var best *family
+	for i := range v.families {
+		f := &v.families[i]
+		if f.wildcard() {
+			return id, true
+		}
+		if f.matches(id) && (best == nil || len(f.subtree) > len(best.subtree)) {
+			best = f
+		}
+	}
+	if best != nil {
+		// the subtrees without wildcards are nested, so the
+		// following oids of best are excluded unless they are
+		// in nested included subtrees
+		start, ok = subtreeEnd(best.subtree)
+	}
+	for i := range v.families {
+		f := &v.families[i]
+		if f.included && oid.Gt(f.subtree, id) && (!ok || oid.Lt(f.subtree, start)) {
+			start, ok = f.subtree, true
+		}
+	}
+	return oid.Clone(start), ok
+}
+
+// subtreeEnd returns the least oid which follows the subtree
+// oids. It returns ok = false if there is no such oid.
+func subtreeEnd(subtree []uint32) (end []uint32, ok bool) {
+	for n := len(subtree); n > 0; n-- {
+		if subtree[n-1] != ^uint32(0) {
+			end = oid.Clone(subtree[:n])
+			end[n-1]++
+			return end, true
+		}
+	}
+	return nil, false
+}
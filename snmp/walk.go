@@ -0,0 +1,107 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+var (
+	// ErrNotIncreasing is returned by walks if the agent returns
+	// an oid which does not follow the previous one.
+	ErrNotIncreasing = errors.New("snmp: agent returned non-increasing oid")
+	// StopWalk is returned by WalkFunc to stop the walk without
+	// error.
+	StopWalk = errors.New("snmp: stop walk")
+)
+
+// WalkFunc is called by walks for each varbind of the subtree.
+// If it returns an error, the walk stops and returns the error
+// (unless it is StopWalk).
+type WalkFunc func(vb asn.Varbind) error
+
+// walker checks the varbinds of the walk.
+type walker struct {
+	root []uint32
+	last []uint32
+	fn   WalkFunc
+}
+
+// visit passes vb to the walk function. It returns done = true
+// if vb is out of the subtree or the walk is stopped.
+func (w *walker) visit(vb asn.Varbind) (done bool, err error) {
+	if isException(vb) || !oid.HasPrefix(vb.Oid, w.root...) {
+		return true, nil
+	}
+	if oid.Compare(vb.Oid, w.last) <= 0 {
+		return true, ErrNotIncreasing
+	}
+	w.last = vb.Oid
+	if err = w.fn(vb); err == StopWalk {
+		return true, nil
+	}
+	return err != nil, err
+}
+
+// Walk calls fn for each object instance of the subtree with
+// successive GetNext requests.
+func (c *Client) Walk(ctx context.Context, root []uint32, fn WalkFunc) error {
+	w := &walker{root: root, last: root, fn: fn}
+	for {
+		vbs, err := c.GetNext(ctx, w.last)
+		if c.Version == ber.Version1 && isEndOfMib(err) {
+			// SNMPv1 agent
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(vbs) != 1 {
+			return ErrNotIncreasing
+		}
+		if done, err := w.visit(vbs[0]); done {
+			return err
+		}
+	}
+}
+
+// BulkWalk calls fn for each object instance of the subtree with
+// successive GetBulk requests for maxRepetitions instances. If
+// AdaptiveBulk is set, maxRepetitions is halved when the agent
+// responds with tooBig. SNMPv1 client uses Walk.
+func (c *Client) BulkWalk(ctx context.Context, root []uint32, maxRepetitions int, fn WalkFunc) error {
+	if c.Version == ber.Version1 {
+		return c.Walk(ctx, root, fn)
+	}
+	w := &walker{root: root, last: root, fn: fn}
+	for {
+		vbs, err := c.GetBulk(ctx, 0, maxRepetitions, w.last)
+		var serr *StatusError
+		if c.AdaptiveBulk && maxRepetitions > 1 &&
+			errors.As(err, &serr) && serr.Status == pduerror.TooBig {
+			maxRepetitions /= 2
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(vbs) == 0 {
+			return ErrNotIncreasing
+		}
+		for _, vb := range vbs {
+			if done, err := w.visit(vb); done {
+				return err
+			}
+		}
+	}
+}
+
+// isEndOfMib tests whether the error is SNMPv1 end of mib.
+func isEndOfMib(err error) bool {
+	var serr *StatusError
+	return errors.As(err, &serr) && serr.Status == pduerror.NoSuchName
+}
@@ -0,0 +1,118 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alexispb/mygosnmp/asn"
+	"github.com/alexispb/mygosnmp/asn/ber"
+	"github.com/alexispb/mygosnmp/oid"
+	"github.com/alexispb/mygosnmp/pduerror"
+)
+
+func TestWalk(t *testing.T) {
+	system := []uint32{1, 3, 6, 1, 2, 1, 1}
+	expected := []asn.Varbind{
+		{Oid: oid.Cat(sysDescr, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(sysContact, 0), Tag: asn.TagOctetString},
+		{Oid: oid.Cat(sysName, 0), Tag: asn.TagOctetString},
+	}
+	ctx := context.Background()
+
+	for _, version := range []ber.Version{ber.Version1, ber.Version2c} {
+		c, _ := startAgent(t, version, "public")
+		for i, walk := range []func(fn WalkFunc) error{
+			func(fn WalkFunc) error { return c.Walk(ctx, system, fn) },
+			func(fn WalkFunc) error { return c.BulkWalk(ctx, system, 2, fn) },
+		} {
+			var vbs []asn.Varbind
+			err := walk(func(vb asn.Varbind) error {
+				vbs = append(vbs, vb)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("TestWalk[%s,%d]: %v", version, i, err)
+			}
+			checkVarbinds(t, "TestWalk", vbs, expected)
+
+			// stop
+			n := 0
+			err = walk(func(vb asn.Varbind) error {
+				n++
+				return StopWalk
+			})
+			if err != nil || n != 1 {
+				t.Errorf("TestWalk[%s,%d]: stop: %v, %d calls", version, i, err, n)
+			}
+		}
+	}
+
+	// the walk of the last object ends with endOfMibView
+	c, _ := startAgent(t, ber.Version2c, "public")
+	n := 0
+	if err := c.BulkWalk(ctx, myCounter, 10, func(vb asn.Varbind) error { n++; return nil }); err != nil || n != 1 {
+		t.Errorf("TestWalk: end of mib: %v, %d calls", err, n)
+	}
+}
+
+func TestWalkNotIncreasing(t *testing.T) {
+	// the agent returns the same oid
+	_, c := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		resp := ber.Pdu{Type: ber.TypeResponse, RequestId: req.RequestId, Varbinds: []asn.Varbind{
+			{Oid: testOid1, Tag: asn.TagInteger32, Value: int32(1)},
+			{Oid: testOid1, Tag: asn.TagInteger32, Value: int32(1)},
+		}}
+		if req.Type == ber.TypeGetNextRequest {
+			resp.Varbinds = resp.Varbinds[:1]
+		}
+		return resp, true
+	})
+	ctx := context.Background()
+	root := []uint32{1, 3, 6, 1}
+	fn := func(vb asn.Varbind) error { return nil }
+	if err := c.Walk(ctx, root, fn); err != ErrNotIncreasing {
+		t.Errorf("TestWalkNotIncreasing: Walk error %v", err)
+	}
+	if err := c.BulkWalk(ctx, root, 10, fn); err != ErrNotIncreasing {
+		t.Errorf("TestWalkNotIncreasing: BulkWalk error %v", err)
+	}
+}
+
+func TestBulkWalkAdaptive(t *testing.T) {
+	// the agent responds with tooBig if maxRepetitions > 2
+	var mu sync.Mutex
+	var repetitions []int32
+	_, c := startResponder(t, func(n int, req ber.Pdu) (ber.Pdu, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		repetitions = append(repetitions, req.MaxRepetitions)
+		resp := ber.Pdu{Type: ber.TypeResponse, RequestId: req.RequestId}
+		if req.MaxRepetitions > 2 {
+			resp.ErrorStatus = pduerror.TooBig
+			return resp, true
+		}
+		resp.Varbinds = []asn.Varbind{{Oid: oid.Cat(testOid1, 1), Tag: asn.TagEndOfMibView}}
+		return resp, true
+	})
+	ctx := context.Background()
+	fn := func(vb asn.Varbind) error { return nil }
+
+	var serr *StatusError
+	if err := c.BulkWalk(ctx, testOid1, 10, fn); !errors.As(err, &serr) || serr.Status != pduerror.TooBig {
+		t.Errorf("TestBulkWalkAdaptive: error %v", err)
+	}
+	c.AdaptiveBulk = true
+	mu.Lock()
+	repetitions = nil
+	mu.Unlock()
+	if err := c.BulkWalk(ctx, testOid1, 10, fn); err != nil {
+		t.Errorf("TestBulkWalkAdaptive: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(repetitions) != 3 || repetitions[2] != 2 {
+		t.Errorf("TestBulkWalkAdaptive: maxRepetitions %v", repetitions)
+	}
+}